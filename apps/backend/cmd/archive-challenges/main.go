@@ -0,0 +1,49 @@
+// Command archive-challenges stores the archive time of every user's
+// challenges that ended before their today. Writes archive them as they
+// happen; run this hourly so challenges of users who stopped logging are
+// archived too, in every time zone.
+package main
+
+import (
+	"context"
+
+	"github.com/reche13/habitum/internal/config"
+	"github.com/reche13/habitum/internal/database"
+	"github.com/reche13/habitum/internal/logger"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/service"
+)
+
+func main() {
+	log := logger.New()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to load config")
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to database")
+	}
+	defer db.Pool.Close()
+
+	repositories := repository.NewRepositories(db.Pool)
+	services := service.NewServices(repositories, cfg, log)
+
+	ctx := context.Background()
+	users, err := repositories.User.List(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to list users")
+	}
+
+	for _, u := range users {
+		if err := services.Habit.ArchiveEndedChallenges(ctx, u.ID); err != nil {
+			log.Fatal().Err(err).Str("user_id", u.ID.String()).Msg("failed to archive ended challenges")
+		}
+	}
+
+	log.Info().Int("users", len(users)).Msg("ended challenges archived")
+}
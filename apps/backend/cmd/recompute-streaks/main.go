@@ -0,0 +1,51 @@
+// Command recompute-streaks rebuilds the stored streaks of every habit from
+// its logs. Streaks are normally maintained when logs are written; run this to
+// repair them after manual data changes or a bug.
+package main
+
+import (
+	"context"
+
+	"github.com/reche13/habitum/internal/config"
+	"github.com/reche13/habitum/internal/database"
+	"github.com/reche13/habitum/internal/logger"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/service"
+)
+
+func main() {
+	log := logger.New()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Msg("failed to load config")
+	}
+
+	db, err := database.New(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to connect to database")
+	}
+	defer db.Pool.Close()
+
+	repositories := repository.NewRepositories(db.Pool)
+	services := service.NewServices(repositories, cfg, log)
+
+	ctx := context.Background()
+	users, err := repositories.User.List(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to list users")
+	}
+
+	total := 0
+	for _, u := range users {
+		count, err := services.Habit.RecomputeStreaks(ctx, u.ID)
+		if err != nil {
+			log.Fatal().Err(err).Str("user_id", u.ID.String()).Msg("failed to recompute streaks")
+		}
+		total += count
+	}
+
+	log.Info().Int("users", len(users)).Int("habits", total).Msg("streaks recomputed")
+}
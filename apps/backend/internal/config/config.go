@@ -14,6 +14,7 @@ type Config struct {
 	Server   ServerConfig   `koanf:"server" validate:"required"`
 	Database DatabaseConfig `koanf:"database" validate:"required"`
 	Auth     AuthConfig     `koanf:"auth" validate:"required"`
+	XP       XPConfig       `koanf:"xp"`
 }
 
 type ServerConfig struct {
@@ -36,6 +37,18 @@ type AuthConfig struct {
 	TestAccountPassword string `koanf:"test_account_password"`
 }
 
+// XPConfig holds the rules for earning XP and points. Unset values fall back
+// to the defaults in the service package.
+type XPConfig struct {
+	CompletionXP         int     `koanf:"completion_xp" validate:"omitempty,min=0"`          // XP for completing a medium habit
+	EasyMultiplier       float64 `koanf:"easy_multiplier" validate:"omitempty,gt=0"`
+	MediumMultiplier     float64 `koanf:"medium_multiplier" validate:"omitempty,gt=0"`
+	HardMultiplier       float64 `koanf:"hard_multiplier" validate:"omitempty,gt=0"`
+	StreakBonusPerDay    float64 `koanf:"streak_bonus_per_day" validate:"omitempty,min=0"`   // Added to the multiplier for each day of the streak
+	MaxStreakMultiplier  float64 `koanf:"max_streak_multiplier" validate:"omitempty,gte=1"`
+	AchievementXP        int     `koanf:"achievement_xp" validate:"omitempty,min=0"`
+	LevelXP              int     `koanf:"level_xp" validate:"omitempty,min=1"`               // XP from level 1 to 2; each level needs that much more than the one before
+}
 
 func Load() (*Config, error) {
 	k := koanf.New(".")
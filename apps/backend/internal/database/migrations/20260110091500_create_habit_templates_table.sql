@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE habit_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    description TEXT,
+    icon TEXT,
+    color TEXT,
+    category TEXT NOT NULL,
+
+    frequency habit_frequency NOT NULL,
+    times_per_week INT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT habit_template_frequency_check CHECK (
+        (frequency = 'daily' AND times_per_week IS NULL)
+        OR
+        (frequency = 'weekly' AND times_per_week BETWEEN 1 AND 7)
+    )
+);
+
+CREATE INDEX idx_habit_templates_user_id ON habit_templates(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS habit_templates;
+-- +goose StatementEnd
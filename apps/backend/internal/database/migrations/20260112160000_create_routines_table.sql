@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE routine_time_of_day AS ENUM ('morning', 'afternoon', 'evening', 'anytime');
+
+CREATE TABLE routines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    time_of_day routine_time_of_day NOT NULL DEFAULT 'anytime',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_routines_user_id ON routines(user_id);
+
+CREATE TABLE routine_habits (
+    routine_id UUID NOT NULL REFERENCES routines(id) ON DELETE CASCADE,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+
+    PRIMARY KEY (routine_id, habit_id)
+);
+
+CREATE INDEX idx_routine_habits_habit_id ON routine_habits(habit_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS routine_habits;
+DROP TABLE IF EXISTS routines;
+DROP TYPE IF EXISTS routine_time_of_day;
+-- +goose StatementEnd
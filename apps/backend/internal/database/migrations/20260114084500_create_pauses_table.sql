@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE pauses (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- NULL habit_id pauses every habit of the user (vacation mode)
+    habit_id UUID REFERENCES habits(id) ON DELETE CASCADE,
+
+    start_date DATE NOT NULL,
+    -- NULL end_date keeps the pause open until it is ended
+    end_date DATE,
+    reason TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT pause_date_range_check CHECK (end_date IS NULL OR end_date >= start_date)
+);
+
+CREATE INDEX idx_pauses_user_id ON pauses(user_id);
+
+CREATE INDEX idx_pauses_habit_id ON pauses(habit_id) WHERE habit_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pauses;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE habit_log_status AS ENUM ('completed', 'skipped', 'failed', 'partial');
+
+ALTER TABLE habit_logs
+ADD COLUMN status habit_log_status NOT NULL DEFAULT 'completed';
+
+UPDATE habit_logs
+SET status = CASE WHEN completed THEN 'completed'::habit_log_status ELSE 'failed'::habit_log_status END;
+
+-- completed is kept for existing queries and derived from status
+ALTER TABLE habit_logs
+DROP COLUMN completed;
+
+ALTER TABLE habit_logs
+ADD COLUMN completed BOOLEAN GENERATED ALWAYS AS (status = 'completed') STORED;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_logs
+DROP COLUMN completed;
+
+ALTER TABLE habit_logs
+ADD COLUMN completed BOOLEAN NOT NULL DEFAULT true;
+
+UPDATE habit_logs
+SET completed = (status = 'completed');
+
+ALTER TABLE habit_logs
+DROP COLUMN status;
+
+DROP TYPE IF EXISTS habit_log_status;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A frozen log marks a missed day that was covered by a streak freeze
+ALTER TYPE habit_log_status ADD VALUE IF NOT EXISTS 'frozen';
+
+CREATE TABLE streak_freezes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- Habit whose streak milestone earned the freeze; kept in the inventory
+    -- when the habit is deleted
+    habit_id UUID REFERENCES habits(id) ON DELETE SET NULL,
+
+    earned_on DATE NOT NULL,
+    streak INT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT streak_freezes_habit_earned_on_key UNIQUE (habit_id, earned_on)
+);
+
+CREATE INDEX idx_streak_freezes_user_id ON streak_freezes(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS streak_freezes;
+
+-- Enum values cannot be dropped, so frozen days fall back to failed ones
+UPDATE habit_logs
+SET status = 'failed'
+WHERE status = 'frozen';
+-- +goose StatementEnd
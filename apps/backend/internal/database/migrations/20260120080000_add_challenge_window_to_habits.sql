@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A habit with a start and end date is a time-boxed challenge
+ALTER TABLE habits
+ADD COLUMN start_date DATE,
+ADD COLUMN end_date DATE,
+ADD CONSTRAINT habit_challenge_window_check CHECK (
+    (start_date IS NULL AND end_date IS NULL)
+    OR (start_date IS NOT NULL AND end_date IS NOT NULL AND end_date >= start_date)
+);
+
+CREATE INDEX idx_habits_end_date ON habits(end_date) WHERE end_date IS NOT NULL AND archived_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_habits_end_date;
+
+ALTER TABLE habits
+DROP CONSTRAINT IF EXISTS habit_challenge_window_check,
+DROP COLUMN IF EXISTS end_date,
+DROP COLUMN IF EXISTS start_date;
+-- +goose StatementEnd
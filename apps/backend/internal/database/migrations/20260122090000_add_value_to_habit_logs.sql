@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Amount logged for quantitative habits, for example kilometres run
+ALTER TABLE habit_logs
+ADD COLUMN value DOUBLE PRECISION CHECK (value IS NULL OR value >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_logs
+DROP COLUMN IF EXISTS value;
+-- +goose StatementEnd
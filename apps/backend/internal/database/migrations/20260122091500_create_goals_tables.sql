@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE goals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    description TEXT,
+    -- Logs before start_date do not count towards the goal
+    start_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    target_date DATE,
+    -- Set once every milestone has been reached
+    completed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_goals_user_id ON goals(user_id);
+
+CREATE TABLE goal_habits (
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (goal_id, habit_id)
+);
+
+CREATE INDEX idx_goal_habits_habit_id ON goal_habits(habit_id);
+
+CREATE TYPE goal_milestone_kind AS ENUM ('total_completions', 'streak', 'cumulative_value');
+
+CREATE TABLE goal_milestones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    goal_id UUID NOT NULL REFERENCES goals(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    kind goal_milestone_kind NOT NULL,
+    target DOUBLE PRECISION NOT NULL CHECK (target > 0),
+    -- Display unit for cumulative values, for example "km"
+    unit TEXT,
+    position INT NOT NULL,
+    completed_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_goal_milestones_goal_id ON goal_milestones(goal_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS goal_milestones;
+DROP TYPE IF EXISTS goal_milestone_kind;
+DROP TABLE IF EXISTS goal_habits;
+DROP TABLE IF EXISTS goals;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Habit stacking: "after I complete stack_after_id, do this habit"
+ALTER TABLE habits
+ADD COLUMN stack_after_id UUID REFERENCES habits(id) ON DELETE SET NULL,
+ADD CONSTRAINT habit_stack_after_self_check CHECK (stack_after_id <> id);
+
+CREATE INDEX idx_habits_stack_after_id ON habits(stack_after_id) WHERE stack_after_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_habits_stack_after_id;
+
+ALTER TABLE habits
+DROP CONSTRAINT IF EXISTS habit_stack_after_self_check,
+DROP COLUMN IF EXISTS stack_after_id;
+-- +goose StatementEnd
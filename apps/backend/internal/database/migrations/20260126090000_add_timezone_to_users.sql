@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- IANA time zone name used to decide where a user's day starts and ends
+ALTER TABLE users
+ADD COLUMN timezone TEXT NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
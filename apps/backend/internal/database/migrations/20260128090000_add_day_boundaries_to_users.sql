@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- week_start is the first day of the user's week; day_ends_at is how many
+-- hours past midnight the user's day runs, so late-night check-ins count
+-- for the day before
+ALTER TABLE users
+ADD COLUMN week_start TEXT NOT NULL DEFAULT 'monday',
+ADD COLUMN day_ends_at SMALLINT NOT NULL DEFAULT 0,
+ADD CONSTRAINT users_week_start_check CHECK (week_start IN ('sunday', 'monday', 'saturday')),
+ADD CONSTRAINT users_day_ends_at_check CHECK (day_ends_at BETWEEN 0 AND 6);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+DROP CONSTRAINT IF EXISTS users_day_ends_at_check,
+DROP CONSTRAINT IF EXISTS users_week_start_check,
+DROP COLUMN IF EXISTS day_ends_at,
+DROP COLUMN IF EXISTS week_start;
+-- +goose StatementEnd
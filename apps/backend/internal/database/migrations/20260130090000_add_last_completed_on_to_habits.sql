@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Streaks are maintained when logs are written; current_streak is the streak
+-- ending on last_completed_on
+ALTER TABLE habits
+ADD COLUMN last_completed_on DATE;
+
+UPDATE habits h
+SET last_completed_on = (
+	SELECT MAX(l.log_date)
+	FROM habit_logs l
+	WHERE l.habit_id = h.id
+		AND l.completed
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habits
+DROP COLUMN IF EXISTS last_completed_on;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Per-user totals for each day, kept in step with habit_logs so analytics and
+-- the calendar do not rescan every log and habit
+CREATE TABLE daily_rollups (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+
+    -- Habits expected on the day: active, not paused and not skipped or
+    -- frozen, plus any completed anyway
+    scheduled_count INT NOT NULL DEFAULT 0,
+    completed_count INT NOT NULL DEFAULT 0,
+    -- Skipped and frozen logs
+    skipped_count INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, day)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS daily_rollups;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Daily history of each habit's strength score. Rows cover the days from the
+-- habit's creation without gaps and are dropped from the first affected day
+-- when logs or pauses change, to be rebuilt when next read.
+CREATE TABLE habit_strengths (
+    habit_id UUID NOT NULL REFERENCES habits(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+
+    -- 0 to 100, at the end of the day
+    score DOUBLE PRECISION NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (habit_id, day)
+);
+
+CREATE INDEX idx_habit_strengths_user_id_day ON habit_strengths(user_id, day);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS habit_strengths;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- When a completion was logged, with the time zone the user was in, so the
+-- time of day survives later time zone changes
+ALTER TABLE habit_logs
+ADD COLUMN completed_at TIMESTAMPTZ,
+ADD COLUMN completed_timezone TEXT;
+
+-- Existing completions were most likely logged when they were created
+UPDATE habit_logs l
+SET completed_at = l.created_at,
+	completed_timezone = u.timezone
+FROM users u
+WHERE u.id = l.user_id
+	AND l.completed;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_logs
+DROP COLUMN IF EXISTS completed_at,
+DROP COLUMN IF EXISTS completed_timezone;
+-- +goose StatementEnd
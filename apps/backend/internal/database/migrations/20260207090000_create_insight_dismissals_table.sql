@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Insights the user dismissed or snoozed, keyed by the insight's stable ID
+CREATE TABLE insight_dismissals (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    insight_id TEXT NOT NULL,
+
+    dismissed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Set when snoozed; a dismissed insight stays hidden for its rule's cooldown
+    snoozed_until TIMESTAMPTZ,
+
+    PRIMARY KEY (user_id, insight_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS insight_dismissals;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Progress towards each achievement of the catalogue, keyed by the
+-- achievement's key. unlocked_at is set once, when progress first reaches
+-- the target.
+CREATE TABLE achievements (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+
+    -- Best progress reached so far
+    progress INT NOT NULL DEFAULT 0,
+    unlocked_at TIMESTAMPTZ,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_achievements_unlocked ON achievements (user_id, unlocked_at DESC)
+WHERE unlocked_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS achievements;
+-- +goose StatementEnd
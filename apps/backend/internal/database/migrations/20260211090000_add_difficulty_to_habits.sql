@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE habit_difficulty AS ENUM ('easy', 'medium', 'hard');
+
+-- Weighs the XP a completion earns
+ALTER TABLE habits
+ADD COLUMN difficulty habit_difficulty NOT NULL DEFAULT 'medium';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habits
+DROP COLUMN IF EXISTS difficulty;
+
+DROP TYPE IF EXISTS habit_difficulty;
+-- +goose StatementEnd
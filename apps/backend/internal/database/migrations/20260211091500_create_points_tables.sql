@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE rewards (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    name TEXT NOT NULL,
+    description TEXT,
+    cost INT NOT NULL CHECK (cost > 0),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rewards_user_id ON rewards(user_id);
+
+CREATE TYPE points_source AS ENUM ('completion', 'achievement', 'reward');
+
+-- Every change to a user's points. Entries are never updated or deleted:
+-- an undone completion is reversed by an entry with negative amounts, and
+-- the balance is the sum of all entries.
+CREATE TABLE points_ledger (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    source points_source NOT NULL,
+    -- Spendable points; negative for spending and reversals
+    points INT NOT NULL,
+    -- XP towards levels; spending points does not take XP away
+    xp INT NOT NULL DEFAULT 0,
+    description TEXT NOT NULL,
+
+    -- What the entry is for, depending on its source
+    habit_id UUID REFERENCES habits(id) ON DELETE SET NULL,
+    log_date DATE,
+    achievement_key TEXT,
+    reward_id UUID REFERENCES rewards(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_points_ledger_user_id ON points_ledger(user_id, created_at DESC);
+CREATE INDEX idx_points_ledger_completion ON points_ledger(habit_id, log_date)
+WHERE source = 'completion';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS points_ledger;
+DROP TYPE IF EXISTS points_source;
+DROP TABLE IF EXISTS rewards;
+-- +goose StatementEnd
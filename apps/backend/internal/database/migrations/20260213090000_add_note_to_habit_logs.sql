@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A short reflection the user attached to the day's log
+ALTER TABLE habit_logs
+ADD COLUMN note TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE habit_logs
+DROP COLUMN IF EXISTS note;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE report_period AS ENUM ('weekly', 'monthly');
+
+-- Reviews of finished weeks and months. A report is stored the first time it
+-- is generated, so it stays the same even if logs are edited later.
+CREATE TABLE reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+    period report_period NOT NULL,
+    period_start DATE NOT NULL,
+    period_end DATE NOT NULL,
+    data JSONB NOT NULL,
+
+    generated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, period, period_start)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS reports;
+DROP TYPE IF EXISTS report_period;
+-- +goose StatementEnd
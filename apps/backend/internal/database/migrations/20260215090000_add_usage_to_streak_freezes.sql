@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A freeze records where it was spent, so the inventory no longer depends on
+-- frozen logs that can be overwritten, unmarked or deleted with their habit
+ALTER TABLE streak_freezes
+ADD COLUMN used_at TIMESTAMPTZ,
+ADD COLUMN used_on DATE,
+ADD COLUMN used_habit_id UUID REFERENCES habits(id) ON DELETE SET NULL,
+-- Kept so the usage history still names a habit that was deleted
+ADD COLUMN used_habit_name TEXT;
+
+-- Pair the frozen logs that exist today with the oldest freezes, per user
+WITH usage AS (
+    SELECT
+        hl.user_id,
+        hl.habit_id,
+        h.name,
+        hl.log_date,
+        hl.created_at,
+        ROW_NUMBER() OVER (PARTITION BY hl.user_id ORDER BY hl.created_at) AS n
+    FROM
+        habit_logs hl
+        JOIN habits h ON h.id = hl.habit_id
+    WHERE
+        hl.status = 'frozen'
+),
+freezes AS (
+    SELECT
+        id,
+        user_id,
+        ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY earned_on, created_at) AS n
+    FROM
+        streak_freezes
+)
+UPDATE streak_freezes sf
+SET
+    used_at = u.created_at,
+    used_on = u.log_date,
+    used_habit_id = u.habit_id,
+    used_habit_name = u.name
+FROM
+    freezes f
+    JOIN usage u ON u.user_id = f.user_id AND u.n = f.n
+WHERE
+    sf.id = f.id;
+
+CREATE INDEX idx_streak_freezes_user_id_unused ON streak_freezes(user_id) WHERE used_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_streak_freezes_user_id_unused;
+
+ALTER TABLE streak_freezes
+DROP COLUMN IF EXISTS used_habit_name,
+DROP COLUMN IF EXISTS used_habit_id,
+DROP COLUMN IF EXISTS used_on,
+DROP COLUMN IF EXISTS used_at;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Weekly habits used to count as scheduled every day; their rollups now hold
+-- the times per week target instead. Drop the stored rollups so they are
+-- rebuilt with the new totals.
+DELETE FROM daily_rollups;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM daily_rollups;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Stored reports counted weekly habits as scheduled every day, understating
+-- their rate. Drop them so finished periods are generated again with each
+-- weekly habit's times per week target.
+DELETE FROM reports;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type AchievementHandler struct {
+	achievementService *service.AchievementService
+}
+
+func NewAchievementHandler(achievementService *service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{
+		achievementService: achievementService,
+	}
+}
+
+func (h *AchievementHandler) GetAchievements(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	achievements, err := h.achievementService.GetAchievements(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(achievements))
+}
@@ -2,10 +2,14 @@ package handler
 
 import (
 	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/insight"
 	"github.com/reche13/habitum/internal/service"
 )
 
@@ -23,19 +27,64 @@ func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsH
 func (h *AnalyticsHandler) GetCompletionTrend(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 	
-	// Get period query param (default to "30d")
-	period := c.QueryParam("period")
-	if period == "" {
-		period = "30d"
+	q, err := parseRangeQuery(c, "30d")
+	if err != nil {
+		return err
 	}
 
-	// Validate period
-	validPeriods := map[string]bool{"7d": true, "30d": true, "90d": true, "all": true}
-	if !validPeriods[period] {
-		return errs.NewBadRequestError("Invalid period. Must be one of: 7d, 30d, 90d, all")
+	// Get granularity query param (default to "day")
+	granularity := c.QueryParam("granularity")
+	if granularity == "" {
+		granularity = service.GranularityDay
+	}
+
+	// Validate granularity
+	validGranularities := map[string]bool{
+		service.GranularityDay:   true,
+		service.GranularityWeek:  true,
+		service.GranularityMonth: true,
+		service.GranularityYear:  true,
+	}
+	if !validGranularities[granularity] {
+		return errs.NewBadRequestError("Invalid granularity. Must be one of: day, week, month, year")
+	}
+
+	// Optional per-habit series
+	byHabit := false
+	if byHabitStr := c.QueryParam("byHabit"); byHabitStr != "" {
+		byHabit, err = strconv.ParseBool(byHabitStr)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid byHabit. Must be true or false")
+		}
+	}
+
+	trend, err := h.analyticsService.GetCompletionTrend(c.Request().Context(), userID, q, granularity, byHabit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, trend)
+}
+
+func (h *AnalyticsHandler) GetStrengthTrend(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	q, err := parseRangeQuery(c, "30d")
+	if err != nil {
+		return err
 	}
 
-	trend, err := h.analyticsService.GetCompletionTrend(c.Request().Context(), userID, period)
+	// Optional habit to show instead of the average over all habits
+	var habitID *uuid.UUID
+	if habitIDStr := c.QueryParam("habitId"); habitIDStr != "" {
+		parsed, err := uuid.Parse(habitIDStr)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid habitId")
+		}
+		habitID = &parsed
+	}
+
+	trend, err := h.analyticsService.GetStrengthTrend(c.Request().Context(), userID, q, habitID)
 	if err != nil {
 		return err
 	}
@@ -46,7 +95,12 @@ func (h *AnalyticsHandler) GetCompletionTrend(c echo.Context) error {
 func (h *AnalyticsHandler) GetCategoryBreakdown(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 	
-	breakdown, err := h.analyticsService.GetCategoryBreakdown(c.Request().Context(), userID)
+	q, err := parseRangeQuery(c, "")
+	if err != nil {
+		return err
+	}
+
+	breakdown, err := h.analyticsService.GetCategoryBreakdown(c.Request().Context(), userID, q)
 	if err != nil {
 		return err
 	}
@@ -57,10 +111,13 @@ func (h *AnalyticsHandler) GetCategoryBreakdown(c echo.Context) error {
 func (h *AnalyticsHandler) GetDayOfWeekAnalysis(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 	
-	// Get period query param (optional)
-	period := c.QueryParam("period")
-	
-	analysis, err := h.analyticsService.GetDayOfWeekAnalysis(c.Request().Context(), userID, period)
+	// Period is optional and defaults to all time
+	q, err := parseRangeQuery(c, "")
+	if err != nil {
+		return err
+	}
+
+	analysis, err := h.analyticsService.GetDayOfWeekAnalysis(c.Request().Context(), userID, q)
 	if err != nil {
 		return err
 	}
@@ -71,7 +128,12 @@ func (h *AnalyticsHandler) GetDayOfWeekAnalysis(c echo.Context) error {
 func (h *AnalyticsHandler) GetMetrics(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 	
-	metrics, err := h.analyticsService.GetMetrics(c.Request().Context(), userID)
+	q, err := parseRangeQuery(c, "")
+	if err != nil {
+		return err
+	}
+
+	metrics, err := h.analyticsService.GetMetrics(c.Request().Context(), userID, q)
 	if err != nil {
 		return err
 	}
@@ -98,7 +160,12 @@ func (h *AnalyticsHandler) GetTopHabits(c echo.Context) error {
 		sortBy = "completion" // default
 	}
 
-	topHabits, err := h.analyticsService.GetTopHabits(c.Request().Context(), userID, limit, sortBy)
+	q, err := parseRangeQuery(c, "")
+	if err != nil {
+		return err
+	}
+
+	topHabits, err := h.analyticsService.GetTopHabits(c.Request().Context(), userID, limit, sortBy, q)
 	if err != nil {
 		return err
 	}
@@ -139,3 +206,157 @@ func (h *AnalyticsHandler) GetInsights(c echo.Context) error {
 	return c.JSON(200, insights)
 }
 
+func (h *AnalyticsHandler) GetChainAnalytics(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	q, err := parseRangeQuery(c, "30d")
+	if err != nil {
+		return err
+	}
+
+	chains, err := h.analyticsService.GetChainAnalytics(c.Request().Context(), userID, q)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, chains)
+}
+
+func (h *AnalyticsHandler) GetCorrelations(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	q, err := parseRangeQuery(c, "90d")
+	if err != nil {
+		return err
+	}
+
+	// Get limit query param
+	limitStr := c.QueryParam("limit")
+	limit := 5 // default
+	if limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			limit = 5
+		}
+	}
+
+	correlations, err := h.analyticsService.GetCorrelations(c.Request().Context(), userID, q, limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, correlations)
+}
+
+func (h *AnalyticsHandler) GetPredictions(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	predictions, err := h.analyticsService.GetPredictions(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, predictions)
+}
+
+func (h *AnalyticsHandler) GetTimeOfDayAnalysis(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	q, err := parseRangeQuery(c, "90d")
+	if err != nil {
+		return err
+	}
+
+	// Optional habit to show instead of every habit
+	var habitID *uuid.UUID
+	if habitIDStr := c.QueryParam("habitId"); habitIDStr != "" {
+		parsed, err := uuid.Parse(habitIDStr)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid habitId")
+		}
+		habitID = &parsed
+	}
+
+	analysis, err := h.analyticsService.GetTimeOfDayAnalysis(c.Request().Context(), userID, q, habitID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, analysis)
+}
+
+func (h *AnalyticsHandler) DismissInsight(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	dismissal, err := h.analyticsService.DismissInsight(c.Request().Context(), userID, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, dismissal)
+}
+
+func (h *AnalyticsHandler) SnoozeInsight(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var payload insight.SnoozePayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	dismissal, err := h.analyticsService.SnoozeInsight(c.Request().Context(), userID, c.Param("id"), &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(200, dismissal)
+}
+
+// parseRangeQuery reads the period, from, to and compare query params shared
+// by the analytics endpoints. from and to (YYYY-MM-DD) take precedence over
+// the period.
+func parseRangeQuery(c echo.Context, defaultPeriod string) (analytics.RangeQuery, error) {
+	q := analytics.RangeQuery{
+		Period:  c.QueryParam("period"),
+		Compare: c.QueryParam("compare"),
+	}
+	if q.Period == "" {
+		q.Period = defaultPeriod
+	}
+
+	// Validate period
+	validPeriods := map[string]bool{"": true, "7d": true, "30d": true, "90d": true, "all": true}
+	if !validPeriods[q.Period] {
+		return q, errs.NewBadRequestError("Invalid period. Must be one of: 7d, 30d, 90d, all")
+	}
+
+	if fromStr := c.QueryParam("from"); fromStr != "" {
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return q, errs.NewBadRequestError("Invalid from date. Use YYYY-MM-DD")
+		}
+		q.From = &from
+	}
+	if toStr := c.QueryParam("to"); toStr != "" {
+		to, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return q, errs.NewBadRequestError("Invalid to date. Use YYYY-MM-DD")
+		}
+		q.To = &to
+	}
+	if q.From != nil && q.To != nil && q.From.After(*q.To) {
+		return q, errs.NewBadRequestError("from must not be after to")
+	}
+
+	// Validate compare
+	validCompares := map[string]bool{"": true, service.ComparePrevious: true, service.CompareYearAgo: true}
+	if !validCompares[q.Compare] {
+		return q, errs.NewBadRequestError("Invalid compare. Must be one of: previous, year_ago")
+	}
+
+	return q, nil
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/goal"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type GoalHandler struct {
+	goalService *service.GoalService
+}
+
+func NewGoalHandler(goalService *service.GoalService) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+	}
+}
+
+func (h *GoalHandler) CreateGoal(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var payload goal.CreateGoalPayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	createdGoal, err := h.goalService.CreateGoal(c.Request().Context(), userID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(createdGoal))
+}
+
+func (h *GoalHandler) GetGoals(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	goals, err := h.goalService.GetGoals(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(goals))
+}
+
+func (h *GoalHandler) GetGoal(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	goalID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid goal ID format")
+	}
+
+	g, err := h.goalService.GetGoal(c.Request().Context(), userID, goalID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(g))
+}
+
+func (h *GoalHandler) UpdateGoal(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	goalID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid goal ID format")
+	}
+
+	var payload goal.UpdateGoalPayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	updatedGoal, err := h.goalService.UpdateGoal(c.Request().Context(), userID, goalID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(updatedGoal))
+}
+
+func (h *GoalHandler) DeleteGoal(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	goalID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid goal ID format")
+	}
+
+	if err := h.goalService.DeleteGoal(c.Request().Context(), userID, goalID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
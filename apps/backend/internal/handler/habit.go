@@ -150,6 +150,40 @@ func (h *HabitHandler) GetHabit(c echo.Context) error {
 	return c.JSON(http.StatusOK, model.SuccessResponse(habit))
 }
 
+func (h *HabitHandler) GetChallengeProgress(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	habitID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid habit ID format")
+	}
+
+	progress, err := h.habitService.GetChallengeProgress(c.Request().Context(), habitID, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(progress))
+}
+
+func (h *HabitHandler) GetHabitStats(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	habitID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid habit ID format")
+	}
+
+	stats, err := h.habitService.GetHabitStats(c.Request().Context(), habitID, userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(stats))
+}
+
 func (h *HabitHandler) UpdateHabit(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 
@@ -201,7 +235,7 @@ func (h *HabitHandler) MarkComplete(c echo.Context) error {
 		return errs.NewBadRequestError("Invalid habit ID format")
 	}
 
-	// Parse optional date parameter (defaults to today)
+	// Parse optional date parameter (defaults to today in the user's time zone)
 	var logDate time.Time
 	if dateParam := c.QueryParam("date"); dateParam != "" {
 		parsedDate, err := time.Parse("2006-01-02", dateParam)
@@ -209,8 +243,6 @@ func (h *HabitHandler) MarkComplete(c echo.Context) error {
 			return errs.NewBadRequestError("Invalid date format. Use YYYY-MM-DD")
 		}
 		logDate = parsedDate
-	} else {
-		logDate = time.Now().UTC()
 	}
 
 	// Verify habit exists and belongs to user
@@ -219,11 +251,22 @@ func (h *HabitHandler) MarkComplete(c echo.Context) error {
 		return err
 	}
 
+	// Parse optional value for quantitative habits
+	var value *float64
+	if valueParam := c.QueryParam("value"); valueParam != "" {
+		parsedValue, err := strconv.ParseFloat(valueParam, 64)
+		if err != nil || parsedValue < 0 {
+			return errs.NewBadRequestError("Invalid value. Use a non-negative number")
+		}
+		value = &parsedValue
+	}
+
 	// Mark as complete
 	payload := &habitlog.HabitLogPayload{
 		HabitID:   habitID,
 		LogDate:   logDate,
 		Completed: true,
+		Value:     value,
 	}
 
 	log, err := h.habitLogService.MarkComplete(c.Request().Context(), userID, habitID, payload)
@@ -237,18 +280,83 @@ func (h *HabitHandler) MarkComplete(c echo.Context) error {
 		return err
 	}
 
+	// Suggest the next habit stacked on this one
+	nextHabit, err := h.habitService.GetNextStackedHabit(c.Request().Context(), userID, habitID, log.LogDate)
+	if err != nil {
+		return err
+	}
+
 	response := map[string]interface{}{
 		"id":          log.ID,
 		"habitId":     log.HabitID,
 		"userId":      log.UserID,
 		"completedAt": log.CreatedAt,
 		"date":        log.LogDate.Format("2006-01-02"),
+		"value":       log.Value,
 		"habit":       updatedHabit,
+		"nextHabit":   nextHabit,
 	}
 
 	return c.JSON(http.StatusCreated, model.SuccessResponse(response))
 }
 
+func (h *HabitHandler) SetStatus(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	habitID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid habit ID format")
+	}
+
+	var payload habitlog.SetStatusPayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	// Date defaults to today in the user's time zone
+	var logDate time.Time
+	if payload.Date != "" {
+		parsedDate, err := time.Parse("2006-01-02", payload.Date)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid date format. Use YYYY-MM-DD")
+		}
+		logDate = parsedDate
+	}
+
+	// Verify habit exists and belongs to user
+	_, err = h.habitService.GetHabit(c.Request().Context(), habitID, userID)
+	if err != nil {
+		return err
+	}
+
+	log, err := h.habitLogService.SetStatus(c.Request().Context(), userID, habitID, logDate, payload.Status, payload.Value, payload.Note)
+	if err != nil {
+		return err
+	}
+
+	// Get updated habit with computed fields
+	updatedHabit, err := h.habitService.GetHabit(c.Request().Context(), habitID, userID)
+	if err != nil {
+		return err
+	}
+
+	response := map[string]interface{}{
+		"id":      log.ID,
+		"habitId": log.HabitID,
+		"userId":  log.UserID,
+		"status":  log.Status,
+		"date":    log.LogDate.Format("2006-01-02"),
+		"habit":   updatedHabit,
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(response))
+}
+
 func (h *HabitHandler) UnmarkComplete(c echo.Context) error {
 	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
 
@@ -258,7 +366,7 @@ func (h *HabitHandler) UnmarkComplete(c echo.Context) error {
 		return errs.NewBadRequestError("Invalid habit ID format")
 	}
 
-	// Parse optional date parameter (defaults to today)
+	// Parse optional date parameter (defaults to today in the user's time zone)
 	var logDate time.Time
 	if dateParam := c.QueryParam("date"); dateParam != "" {
 		parsedDate, err := time.Parse("2006-01-02", dateParam)
@@ -266,8 +374,6 @@ func (h *HabitHandler) UnmarkComplete(c echo.Context) error {
 			return errs.NewBadRequestError("Invalid date format. Use YYYY-MM-DD")
 		}
 		logDate = parsedDate
-	} else {
-		logDate = time.Now().UTC()
 	}
 
 	// Verify habit exists and belongs to user
@@ -305,16 +411,13 @@ func (h *HabitHandler) GetCompletions(c echo.Context) error {
 		return errs.NewBadRequestError("Invalid habit ID format")
 	}
 
-	// Parse optional date range parameters
+	// Parse optional date range parameters (defaults to the last 365 days)
 	var startDate, endDate time.Time
 	if startParam := c.QueryParam("startDate"); startParam != "" {
 		startDate, err = time.Parse("2006-01-02", startParam)
 		if err != nil {
 			return errs.NewBadRequestError("Invalid startDate format. Use YYYY-MM-DD")
 		}
-	} else {
-		// Default to 365 days ago
-		startDate = time.Now().UTC().AddDate(0, 0, -365)
 	}
 
 	if endParam := c.QueryParam("endDate"); endParam != "" {
@@ -322,8 +425,6 @@ func (h *HabitHandler) GetCompletions(c echo.Context) error {
 		if err != nil {
 			return errs.NewBadRequestError("Invalid endDate format. Use YYYY-MM-DD")
 		}
-	} else {
-		endDate = time.Now().UTC()
 	}
 
 	// Parse limit
@@ -379,7 +480,7 @@ func (h *HabitHandler) GetCompletionHistory(c echo.Context) error {
 		}
 	}
 
-	dates, totalDays, completedDays, err := h.habitLogService.GetCompletionHistory(c.Request().Context(), userID, habitID, year, allTime)
+	dates, frozenDates, totalDays, completedDays, err := h.habitLogService.GetCompletionHistory(c.Request().Context(), userID, habitID, year, allTime)
 	if err != nil {
 		return err
 	}
@@ -390,8 +491,14 @@ func (h *HabitHandler) GetCompletionHistory(c echo.Context) error {
 		dateStrings[i] = date.Format("2006-01-02")
 	}
 
+	frozenDateStrings := make([]string, len(frozenDates))
+	for i, date := range frozenDates {
+		frozenDateStrings[i] = date.Format("2006-01-02")
+	}
+
 	response := map[string]interface{}{
 		"dates":         dateStrings,
+		"frozenDates":   frozenDateStrings,
 		"totalDays":     totalDays,
 		"completedDays": completedDays,
 	}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/habittemplate"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type HabitTemplateHandler struct {
+	habitTemplateService *service.HabitTemplateService
+}
+
+func NewHabitTemplateHandler(habitTemplateService *service.HabitTemplateService) *HabitTemplateHandler {
+	return &HabitTemplateHandler{
+		habitTemplateService: habitTemplateService,
+	}
+}
+
+func (h *HabitTemplateHandler) GetTemplates(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	templates, err := h.habitTemplateService.GetTemplates(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+func (h *HabitTemplateHandler) DeleteTemplate(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	// Private templates are addressed by UUID, built-in ones by slug
+	templateID := c.Param("id")
+
+	if err := h.habitTemplateService.DeleteTemplate(c.Request().Context(), userID, templateID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *HabitTemplateHandler) CreateHabitFromTemplate(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	templateID := c.Param("id")
+	if templateID == "" {
+		return errs.NewBadRequestError("Template ID is required")
+	}
+
+	// Overrides are optional, an empty body creates the habit as-is
+	var payload habittemplate.CreateFromTemplatePayload
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&payload); err != nil {
+			return errs.NewBadRequestError("Invalid request payload")
+		}
+
+		if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+			return errs.NewValidationError(fieldErrors)
+		}
+	}
+
+	createdHabit, err := h.habitTemplateService.CreateHabitFromTemplate(c.Request().Context(), userID, templateID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(createdHabit))
+}
+
+func (h *HabitTemplateHandler) SaveHabitAsTemplate(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	habitID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid habit ID format")
+	}
+
+	var payload habittemplate.SaveAsTemplatePayload
+	if c.Request().ContentLength > 0 {
+		if err := c.Bind(&payload); err != nil {
+			return errs.NewBadRequestError("Invalid request payload")
+		}
+
+		if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+			return errs.NewValidationError(fieldErrors)
+		}
+	}
+
+	template, err := h.habitTemplateService.SaveHabitAsTemplate(c.Request().Context(), userID, habitID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(template))
+}
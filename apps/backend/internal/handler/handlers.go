@@ -11,6 +11,15 @@ type Handlers struct {
 	Calendar *CalendarHandler
 	Dashboard *DashboardHandler
 	Auth *AuthHandler
+	HabitTemplate *HabitTemplateHandler
+	Routine *RoutineHandler
+	Pause *PauseHandler
+	StreakFreeze *StreakFreezeHandler
+	Goal *GoalHandler
+	Achievement *AchievementHandler
+	Points *PointsHandler
+	Reward *RewardHandler
+	Report *ReportHandler
 }
 
 func NewHandlers(services *service.Services) *Handlers {
@@ -23,5 +32,14 @@ func NewHandlers(services *service.Services) *Handlers {
 		Calendar: NewCalendarHandler(services.Calendar),
 		Dashboard: NewDashboardHandler(services.Dashboard),
 		Auth: NewAuthHandler(services.Auth),
+		HabitTemplate: NewHabitTemplateHandler(services.HabitTemplate),
+		Routine: NewRoutineHandler(services.Routine),
+		Pause: NewPauseHandler(services.Pause),
+		StreakFreeze: NewStreakFreezeHandler(services.StreakFreeze),
+		Goal: NewGoalHandler(services.Goal),
+		Achievement: NewAchievementHandler(services.Achievement),
+		Points: NewPointsHandler(services.Points),
+		Reward: NewRewardHandler(services.Reward),
+		Report: NewReportHandler(services.Report),
 	}
 }
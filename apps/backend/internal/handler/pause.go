@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/pause"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type PauseHandler struct {
+	pauseService *service.PauseService
+}
+
+func NewPauseHandler(pauseService *service.PauseService) *PauseHandler {
+	return &PauseHandler{
+		pauseService: pauseService,
+	}
+}
+
+func (h *PauseHandler) CreatePause(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var payload pause.CreatePausePayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	createdPause, err := h.pauseService.CreatePause(c.Request().Context(), userID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(createdPause))
+}
+
+func (h *PauseHandler) GetPauses(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	pauses, err := h.pauseService.GetPauses(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(pauses))
+}
+
+func (h *PauseHandler) UpdatePause(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	pauseID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid pause ID format")
+	}
+
+	var payload pause.UpdatePausePayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	updatedPause, err := h.pauseService.UpdatePause(c.Request().Context(), userID, pauseID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(updatedPause))
+}
+
+func (h *PauseHandler) DeletePause(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	pauseID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid pause ID format")
+	}
+
+	if err := h.pauseService.DeletePause(c.Request().Context(), userID, pauseID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
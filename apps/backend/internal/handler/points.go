@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type PointsHandler struct {
+	pointsService *service.PointsService
+}
+
+func NewPointsHandler(pointsService *service.PointsService) *PointsHandler {
+	return &PointsHandler{
+		pointsService: pointsService,
+	}
+}
+
+func (h *PointsHandler) GetSummary(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	summary, err := h.pointsService.GetSummary(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(summary))
+}
+
+func (h *PointsHandler) GetLedger(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	page := 1
+	if pageParam := c.QueryParam("page"); pageParam != "" {
+		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 50
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		if l, err := strconv.Atoi(limitParam); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	entries, total, err := h.pointsService.GetLedger(c.Request().Context(), userID, page, limit)
+	if err != nil {
+		return err
+	}
+
+	meta := &model.Meta{
+		RequestID:  middleware.GetRequestID(c),
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: (total + limit - 1) / limit,
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponseWithMeta(entries, meta))
+}
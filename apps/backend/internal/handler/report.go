@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type ReportHandler struct {
+	reportService *service.ReportService
+}
+
+func NewReportHandler(reportService *service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetWeeklyReport handles GET /reports/weekly?week=YYYY-MM-DD. Any day of the
+// week selects it; without one the report covers last week.
+func (h *ReportHandler) GetWeeklyReport(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var week *time.Time
+	if weekParam := c.QueryParam("week"); weekParam != "" {
+		parsed, err := time.Parse("2006-01-02", weekParam)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid week format. Use YYYY-MM-DD")
+		}
+		week = &parsed
+	}
+
+	rp, err := h.reportService.GetWeeklyReport(c.Request().Context(), userID, week)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(rp))
+}
+
+// GetMonthlyReport handles GET /reports/monthly?month=YYYY-MM. Without a
+// month the report covers last month.
+func (h *ReportHandler) GetMonthlyReport(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var month *time.Time
+	if monthParam := c.QueryParam("month"); monthParam != "" {
+		parsed, err := time.Parse("2006-01", monthParam)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid month format. Use YYYY-MM")
+		}
+		month = &parsed
+	}
+
+	rp, err := h.reportService.GetMonthlyReport(c.Request().Context(), userID, month)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(rp))
+}
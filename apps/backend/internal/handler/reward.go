@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/points"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type RewardHandler struct {
+	rewardService *service.RewardService
+}
+
+func NewRewardHandler(rewardService *service.RewardService) *RewardHandler {
+	return &RewardHandler{
+		rewardService: rewardService,
+	}
+}
+
+func (h *RewardHandler) CreateReward(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var payload points.CreateRewardPayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	createdReward, err := h.rewardService.CreateReward(c.Request().Context(), userID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(createdReward))
+}
+
+func (h *RewardHandler) GetRewards(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	rewards, err := h.rewardService.GetRewards(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(rewards))
+}
+
+func (h *RewardHandler) GetReward(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	rewardID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid reward ID format")
+	}
+
+	rw, err := h.rewardService.GetReward(c.Request().Context(), userID, rewardID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(rw))
+}
+
+func (h *RewardHandler) UpdateReward(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	rewardID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid reward ID format")
+	}
+
+	var payload points.UpdateRewardPayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	updatedReward, err := h.rewardService.UpdateReward(c.Request().Context(), userID, rewardID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(updatedReward))
+}
+
+func (h *RewardHandler) DeleteReward(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	rewardID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid reward ID format")
+	}
+
+	if err := h.rewardService.DeleteReward(c.Request().Context(), userID, rewardID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *RewardHandler) RedeemReward(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	rewardID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid reward ID format")
+	}
+
+	redeemed, err := h.rewardService.RedeemReward(c.Request().Context(), userID, rewardID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(redeemed))
+}
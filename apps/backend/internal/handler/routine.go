@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/middleware"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/routine"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type RoutineHandler struct {
+	routineService *service.RoutineService
+}
+
+func NewRoutineHandler(routineService *service.RoutineService) *RoutineHandler {
+	return &RoutineHandler{
+		routineService: routineService,
+	}
+}
+
+func (h *RoutineHandler) CreateRoutine(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	var payload routine.CreateRoutinePayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	createdRoutine, err := h.routineService.CreateRoutine(c.Request().Context(), userID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(createdRoutine))
+}
+
+func (h *RoutineHandler) GetRoutines(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	routines, err := h.routineService.GetRoutines(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(routines))
+}
+
+func (h *RoutineHandler) GetRoutine(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	routineID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid routine ID format")
+	}
+
+	rt, err := h.routineService.GetRoutine(c.Request().Context(), userID, routineID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(rt))
+}
+
+func (h *RoutineHandler) UpdateRoutine(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	routineID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid routine ID format")
+	}
+
+	var payload routine.UpdateRoutinePayload
+	if err := c.Bind(&payload); err != nil {
+		return errs.NewBadRequestError("Invalid request payload")
+	}
+
+	if fieldErrors := middleware.ValidateStruct(&payload); fieldErrors != nil {
+		return errs.NewValidationError(fieldErrors)
+	}
+
+	updatedRoutine, err := h.routineService.UpdateRoutine(c.Request().Context(), userID, routineID, &payload)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(updatedRoutine))
+}
+
+func (h *RoutineHandler) DeleteRoutine(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	routineID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid routine ID format")
+	}
+
+	if err := h.routineService.DeleteRoutine(c.Request().Context(), userID, routineID); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *RoutineHandler) CompleteRoutine(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	routineID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid routine ID format")
+	}
+
+	// Parse optional date parameter (defaults to today in the user's time zone)
+	var logDate time.Time
+	if dateParam := c.QueryParam("date"); dateParam != "" {
+		parsedDate, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid date format. Use YYYY-MM-DD")
+		}
+		logDate = parsedDate
+	}
+
+	rt, err := h.routineService.CompleteRoutine(c.Request().Context(), userID, routineID, logDate)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(rt))
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/service"
+)
+
+type StreakFreezeHandler struct {
+	streakFreezeService *service.StreakFreezeService
+}
+
+func NewStreakFreezeHandler(streakFreezeService *service.StreakFreezeService) *StreakFreezeHandler {
+	return &StreakFreezeHandler{
+		streakFreezeService: streakFreezeService,
+	}
+}
+
+func (h *StreakFreezeHandler) GetInventory(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	inventory, err := h.streakFreezeService.GetInventory(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, model.SuccessResponse(inventory))
+}
+
+func (h *StreakFreezeHandler) UseFreeze(c echo.Context) error {
+	userID := uuid.MustParse("04b151e6-7631-4548-9384-1e11bbaa84e8")
+
+	idParam := c.Param("id")
+	habitID, err := uuid.Parse(idParam)
+	if err != nil {
+		return errs.NewBadRequestError("Invalid habit ID format")
+	}
+
+	// Parse optional date parameter (defaults to yesterday in the user's time zone)
+	var date time.Time
+	if dateParam := c.QueryParam("date"); dateParam != "" {
+		parsedDate, err := time.Parse("2006-01-02", dateParam)
+		if err != nil {
+			return errs.NewBadRequestError("Invalid date format. Use YYYY-MM-DD")
+		}
+		date = parsedDate
+	}
+
+	log, err := h.streakFreezeService.UseFreeze(c.Request().Context(), userID, habitID, date)
+	if err != nil {
+		return err
+	}
+
+	response := map[string]interface{}{
+		"id":      log.ID,
+		"habitId": log.HabitID,
+		"status":  log.Status,
+		"date":    log.LogDate.Format("2006-01-02"),
+	}
+
+	return c.JSON(http.StatusCreated, model.SuccessResponse(response))
+}
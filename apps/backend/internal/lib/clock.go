@@ -0,0 +1,117 @@
+package lib
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is a fixed point in time seen from a user's time zone. A request
+// captures one Clock when it starts so every "today" computed while serving
+// it agrees, even if the request runs across midnight. It also knows the
+// day the user's week starts on and how many hours past midnight their day
+// runs, so late-night check-ins count for the day before.
+type Clock struct {
+	now       time.Time
+	loc       *time.Location
+	weekStart time.Weekday
+	dayEndsAt time.Duration
+}
+
+type clockKey struct{}
+
+// NewClock returns a Clock frozen at now and reading dates in loc, with
+// weeks starting on Monday and days ending at midnight. A nil loc means UTC.
+func NewClock(now time.Time, loc *time.Location) Clock {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return Clock{now: now, loc: loc, weekStart: time.Monday}
+}
+
+// SystemClock returns a UTC Clock frozen at the current instant
+func SystemClock() Clock {
+	return NewClock(time.Now(), time.UTC)
+}
+
+// In returns the same instant read from a different time zone
+func (c Clock) In(loc *time.Location) Clock {
+	if loc == nil {
+		loc = time.UTC
+	}
+	c.loc = loc
+	return c
+}
+
+// WithDayBoundaries returns the clock with weeks starting on weekStart and
+// days ending dayEndsAt hours after midnight
+func (c Clock) WithDayBoundaries(weekStart time.Weekday, dayEndsAt int) Clock {
+	c.weekStart = weekStart
+	c.dayEndsAt = time.Duration(dayEndsAt) * time.Hour
+	return c
+}
+
+// Now returns the clock's instant in its time zone
+func (c Clock) Now() time.Time {
+	return c.now.In(c.loc)
+}
+
+// Location returns the clock's time zone
+func (c Clock) Location() *time.Location {
+	return c.loc
+}
+
+// DayEndsAt returns how many hours past midnight the clock's day runs
+func (c Clock) DayEndsAt() int {
+	return int(c.dayEndsAt / time.Hour)
+}
+
+// Date returns the civil date t counts for in the clock's time zone,
+// normalized to midnight UTC like every other date in the app. Instants
+// before the day's end hour belong to the previous day. The end hour is read
+// off the wall clock, so days that gain or lose an hour to daylight saving
+// still end at it.
+func (c Clock) Date(t time.Time) time.Time {
+	local := t.In(c.loc)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+	return NormalizeDate(wall.Add(-c.dayEndsAt))
+}
+
+// Today returns the clock's current civil date
+func (c Clock) Today() time.Time {
+	return c.Date(c.now)
+}
+
+// WeekStart returns the first day of the week containing date
+func (c Clock) WeekStart(date time.Time) time.Time {
+	date = NormalizeDate(date)
+	return date.AddDate(0, 0, -c.WeekdayIndex(date))
+}
+
+// WeekdayIndex returns the position of date within its week, 0 being the
+// day the week starts on
+func (c Clock) WeekdayIndex(date time.Time) int {
+	return (int(date.Weekday()) - int(c.weekStart) + 7) % 7
+}
+
+// Weekdays returns the days of the week in the clock's order
+func (c Clock) Weekdays() []time.Weekday {
+	days := make([]time.Weekday, 7)
+	for i := range days {
+		days[i] = time.Weekday((int(c.weekStart) + i) % 7)
+	}
+	return days
+}
+
+// WithClock returns a copy of ctx carrying c
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// ClockFromContext returns the Clock stored in ctx, or a UTC system clock
+// when there is none
+func ClockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok {
+		return c
+	}
+	return SystemClock()
+}
@@ -0,0 +1,226 @@
+package lib
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load location %s: %v", name, err)
+	}
+	return loc
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestClockDate(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+
+	// Clocks in New York jump from 02:00 EST to 03:00 EDT on 2026-03-08 and
+	// fall back from 02:00 EDT to 01:00 EST on 2026-11-01
+	est := time.FixedZone("EST", -5*60*60)
+	edt := time.FixedZone("EDT", -4*60*60)
+
+	tests := []struct {
+		name      string
+		loc       *time.Location
+		dayEndsAt int
+		instant   time.Time
+		want      time.Time
+	}{
+		{
+			name:    "UTC instant read in a zone ahead of UTC",
+			loc:     tokyo,
+			instant: time.Date(2026, 5, 10, 23, 30, 0, 0, time.UTC),
+			want:    date(2026, 5, 11),
+		},
+		{
+			name:    "UTC instant read in a zone behind UTC",
+			loc:     newYork,
+			instant: time.Date(2026, 5, 11, 2, 0, 0, 0, time.UTC),
+			want:    date(2026, 5, 10),
+		},
+		{
+			name:    "spring forward, just before the jump",
+			loc:     newYork,
+			instant: time.Date(2026, 3, 8, 1, 59, 0, 0, est),
+			want:    date(2026, 3, 8),
+		},
+		{
+			name:    "spring forward, just after the jump",
+			loc:     newYork,
+			instant: time.Date(2026, 3, 8, 3, 0, 0, 0, edt),
+			want:    date(2026, 3, 8),
+		},
+		{
+			name:    "spring forward, last minute of the short day",
+			loc:     newYork,
+			instant: time.Date(2026, 3, 8, 23, 59, 0, 0, edt),
+			want:    date(2026, 3, 8),
+		},
+		{
+			name:      "spring forward with a cutoff, before it",
+			loc:       newYork,
+			dayEndsAt: 3,
+			instant:   time.Date(2026, 3, 8, 1, 30, 0, 0, est),
+			want:      date(2026, 3, 7),
+		},
+		{
+			name:      "spring forward with a cutoff, after it",
+			loc:       newYork,
+			dayEndsAt: 3,
+			instant:   time.Date(2026, 3, 8, 3, 30, 0, 0, edt),
+			want:      date(2026, 3, 8),
+		},
+		{
+			name:    "fall back, first 01:30",
+			loc:     newYork,
+			instant: time.Date(2026, 11, 1, 1, 30, 0, 0, edt),
+			want:    date(2026, 11, 1),
+		},
+		{
+			name:    "fall back, repeated 01:30",
+			loc:     newYork,
+			instant: time.Date(2026, 11, 1, 1, 30, 0, 0, est),
+			want:    date(2026, 11, 1),
+		},
+		{
+			name:      "fall back with a cutoff, repeated hour is before it",
+			loc:       newYork,
+			dayEndsAt: 2,
+			instant:   time.Date(2026, 11, 1, 1, 30, 0, 0, est),
+			want:      date(2026, 10, 31),
+		},
+		{
+			name:      "fall back with a cutoff, after it",
+			loc:       newYork,
+			dayEndsAt: 2,
+			instant:   time.Date(2026, 11, 1, 2, 0, 0, 0, est),
+			want:      date(2026, 11, 1),
+		},
+		{
+			name:      "cutoff past midnight, before it",
+			loc:       newYork,
+			dayEndsAt: 4,
+			instant:   time.Date(2026, 5, 10, 3, 59, 0, 0, edt),
+			want:      date(2026, 5, 9),
+		},
+		{
+			name:      "cutoff past midnight, at it",
+			loc:       newYork,
+			dayEndsAt: 4,
+			instant:   time.Date(2026, 5, 10, 4, 0, 0, 0, edt),
+			want:      date(2026, 5, 10),
+		},
+		{
+			name:      "cutoff past midnight, late evening",
+			loc:       newYork,
+			dayEndsAt: 4,
+			instant:   time.Date(2026, 5, 10, 23, 0, 0, 0, edt),
+			want:      date(2026, 5, 10),
+		},
+		{
+			name:      "cutoff moves a new year's night check-in to the old year",
+			loc:       tokyo,
+			dayEndsAt: 2,
+			instant:   time.Date(2026, 12, 31, 16, 30, 0, 0, time.UTC),
+			want:      date(2026, 12, 31),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock(tt.instant, tt.loc).WithDayBoundaries(time.Monday, tt.dayEndsAt)
+
+			if got := clock.Date(tt.instant); !got.Equal(tt.want) {
+				t.Errorf("Date() = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+			if got := clock.Today(); !got.Equal(tt.want) {
+				t.Errorf("Today() = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+		})
+	}
+}
+
+func TestClockWeekStart(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekStart time.Weekday
+		date      time.Time
+		want      time.Time
+		wantIndex int
+	}{
+		{
+			name:      "Monday start, Monday",
+			weekStart: time.Monday,
+			date:      date(2026, 5, 11),
+			want:      date(2026, 5, 11),
+			wantIndex: 0,
+		},
+		{
+			name:      "Monday start, Sunday ends the week",
+			weekStart: time.Monday,
+			date:      date(2026, 5, 10),
+			want:      date(2026, 5, 4),
+			wantIndex: 6,
+		},
+		{
+			name:      "Sunday start, Sunday starts the week",
+			weekStart: time.Sunday,
+			date:      date(2026, 5, 10),
+			want:      date(2026, 5, 10),
+			wantIndex: 0,
+		},
+		{
+			name:      "Sunday start, Monday",
+			weekStart: time.Sunday,
+			date:      date(2026, 5, 11),
+			want:      date(2026, 5, 10),
+			wantIndex: 1,
+		},
+		{
+			name:      "Sunday start, Saturday ends the week",
+			weekStart: time.Sunday,
+			date:      date(2026, 5, 16),
+			want:      date(2026, 5, 10),
+			wantIndex: 6,
+		},
+		{
+			name:      "week across a month and year boundary",
+			weekStart: time.Monday,
+			date:      date(2027, 1, 1),
+			want:      date(2026, 12, 28),
+			wantIndex: 4,
+		},
+		{
+			name:      "time of day is ignored",
+			weekStart: time.Sunday,
+			date:      time.Date(2026, 5, 13, 22, 15, 0, 0, time.UTC),
+			want:      date(2026, 5, 10),
+			wantIndex: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock(tt.date, nil).WithDayBoundaries(tt.weekStart, 0)
+
+			if got := clock.WeekStart(tt.date); !got.Equal(tt.want) {
+				t.Errorf("WeekStart() = %s, want %s", got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+			}
+			if got := clock.WeekdayIndex(tt.date); got != tt.wantIndex {
+				t.Errorf("WeekdayIndex() = %d, want %d", got, tt.wantIndex)
+			}
+			if got := clock.Weekdays()[0]; got != tt.weekStart {
+				t.Errorf("Weekdays()[0] = %s, want %s", got, tt.weekStart)
+			}
+		})
+	}
+}
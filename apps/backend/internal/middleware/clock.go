@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/lib"
+)
+
+// Clock freezes the current time for the duration of the request so that
+// every service sees the same "now". Services move it into the user's time
+// zone once they know who the user is.
+func Clock() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := lib.WithClock(c.Request().Context(), lib.SystemClock())
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+	}
+}
@@ -63,6 +63,8 @@ func getValidationErrorMessage(err validator.FieldError) string {
 		return err.Field() + " must be at most " + err.Param() + " characters"
 	case "uuid":
 		return err.Field() + " must be a valid UUID"
+	case "timezone":
+		return err.Field() + " must be an IANA time zone such as Europe/Berlin"
 	default:
 		return err.Field() + " is invalid"
 	}
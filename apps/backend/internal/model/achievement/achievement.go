@@ -0,0 +1,27 @@
+package achievement
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Achievement is a user's progress towards one achievement of the catalogue
+type Achievement struct {
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Key        string     `json:"key" db:"key"`
+	Progress   int        `json:"progress" db:"progress"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty" db:"unlocked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Definition describes an achievement of the catalogue
+type Definition struct {
+	Key         string
+	Name        string
+	Description string
+	Icon        string
+	Group       string // "completions", "streaks", "consistency" or "mastery"
+	Target      int
+}
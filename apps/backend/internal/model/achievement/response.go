@@ -0,0 +1,23 @@
+package achievement
+
+import "time"
+
+// AchievementsResponse lists every achievement with the user's progress
+type AchievementsResponse struct {
+	UnlockedCount int                   `json:"unlockedCount"`
+	Total         int                   `json:"total"`
+	Data          []AchievementResponse `json:"data"`
+}
+
+// AchievementResponse is an achievement of the catalogue with the user's progress
+type AchievementResponse struct {
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Icon        string     `json:"icon"`
+	Group       string     `json:"group"`
+	Target      int        `json:"target"`
+	Progress    int        `json:"progress"`    // Capped at the target
+	Unlocked    bool       `json:"unlocked"`
+	UnlockedAt  *time.Time `json:"unlockedAt,omitempty"`
+}
@@ -1,21 +1,87 @@
 package analytics
 
-// CompletionTrendResponse represents daily completion data
+import "time"
+
+// RangeQuery selects the dates an analytics endpoint covers. From and To take
+// precedence over Period; a missing To means today. Compare asks for deltas
+// against the "previous" range of the same length or the range a year ago.
+type RangeQuery struct {
+	Period  string
+	From    *time.Time
+	To      *time.Time
+	Compare string
+}
+
+// DateRange is an inclusive range of dates
+type DateRange struct {
+	From string `json:"from"` // Format: "yyyy-MM-dd"
+	To   string `json:"to"`   // Format: "yyyy-MM-dd"
+}
+
+// Delta compares a metric with its value over the comparison range
+type Delta struct {
+	Previous  float64  `json:"previous"`
+	Change    float64  `json:"change"`              // Current minus previous
+	ChangePct *float64 `json:"changePct,omitempty"` // Relative change in percent, unset when previous is 0
+}
+
+// CompletionTrendResponse represents completion data per day, week, month or year
 type CompletionTrendResponse struct {
-	Data []CompletionTrendDataPoint `json:"data"`
+	Range       DateRange                  `json:"range"`
+	ComparedTo  *DateRange                 `json:"comparedTo,omitempty"`
+	Granularity string                     `json:"granularity"` // "day", "week", "month" or "year"
+	Data        []CompletionTrendDataPoint `json:"data"`
+	Habits      []HabitCompletionTrend     `json:"habits,omitempty"` // Set when the per-habit breakdown is requested
+	Summary     CompletionTrendSummary     `json:"summary"`
+	Deltas      map[string]Delta           `json:"deltas,omitempty"` // Keyed by summary field
+}
+
+// HabitCompletionTrend represents one habit's completion data, bucketed like the overall trend
+type HabitCompletionTrend struct {
+	HabitID string                     `json:"habitId"`
+	Name    string                     `json:"name"`
+	Data    []CompletionTrendDataPoint `json:"data"`
 }
 
-// CompletionTrendDataPoint represents completion data for a single day
+// CompletionTrendSummary totals the completion trend over its range
+type CompletionTrendSummary struct {
+	Completions    int     `json:"completions"`
+	TotalHabits    int     `json:"totalHabits"`    // Habits scheduled, summed over the days
+	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+}
+
+// CompletionTrendDataPoint represents completion data for a single day or a
+// longer bucket, clipped to the range
 type CompletionTrendDataPoint struct {
-	Date           string  `json:"date"`            // Format: "yyyy-MM-dd"
-	Completions    int     `json:"completions"`     // Number of completions on this day
-	TotalHabits    int     `json:"totalHabits"`     // Total active habits on this day
+	Date           string  `json:"date"`            // First day of the bucket. Format: "yyyy-MM-dd"
+	EndDate        string  `json:"endDate"`         // Last day of the bucket. Format: "yyyy-MM-dd"
+	Completions    int     `json:"completions"`     // Number of completions in this bucket
+	TotalHabits    int     `json:"totalHabits"`     // Habits scheduled, summed over the bucket's days
 	CompletionRate float64 `json:"completionRate"`  // Percentage (0-100)
 }
 
+// StrengthTrendResponse represents daily habit strength scores
+type StrengthTrendResponse struct {
+	HabitID    string                   `json:"habitId,omitempty"` // Set when the trend is for one habit
+	Range      DateRange                `json:"range"`
+	ComparedTo *DateRange               `json:"comparedTo,omitempty"`
+	Data       []StrengthTrendDataPoint `json:"data"`
+	Average    float64                  `json:"average"`          // Mean strength over the days with active habits
+	Deltas     map[string]Delta         `json:"deltas,omitempty"` // Keyed by "average"
+}
+
+// StrengthTrendDataPoint represents the strength score on a single day
+type StrengthTrendDataPoint struct {
+	Date        string  `json:"date"`        // Format: "yyyy-MM-dd"
+	Strength    float64 `json:"strength"`    // 0-100, averaged over the habits active on this day
+	TotalHabits int     `json:"totalHabits"` // Habits active on this day
+}
+
 // CategoryBreakdownResponse represents completion stats by category
 type CategoryBreakdownResponse struct {
-	Data []CategoryBreakdownDataPoint `json:"data"`
+	Range      DateRange                    `json:"range"`
+	ComparedTo *DateRange                   `json:"comparedTo,omitempty"`
+	Data       []CategoryBreakdownDataPoint `json:"data"`
 }
 
 // CategoryBreakdownDataPoint represents stats for a single category
@@ -26,33 +92,42 @@ type CategoryBreakdownDataPoint struct {
 	HabitCount     int     `json:"habitCount"`
 	AvgCompletionRate float64 `json:"avgCompletionRate"` // Percentage (0-100)
 	TotalCompletions int     `json:"totalCompletions"`
+	Deltas         map[string]Delta `json:"deltas,omitempty"` // Keyed by field
 }
 
 // DayOfWeekResponse represents completion stats by day of week
 type DayOfWeekResponse struct {
-	Data []DayOfWeekDataPoint `json:"data"`
+	Range      DateRange            `json:"range"`
+	ComparedTo *DateRange           `json:"comparedTo,omitempty"`
+	Data       []DayOfWeekDataPoint `json:"data"`
 }
 
 // DayOfWeekDataPoint represents stats for a single day of week
 type DayOfWeekDataPoint struct {
 	Day            string  `json:"day"`            // "Monday", "Tuesday", etc.
-	DayIndex       int     `json:"dayIndex"`       // 0=Monday, 6=Sunday
+	DayIndex       int     `json:"dayIndex"`       // 0 = first day of the user's week
 	Completions    int     `json:"completions"`
 	TotalHabits    int     `json:"totalHabits"`
 	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+	Deltas         map[string]Delta `json:"deltas,omitempty"` // Keyed by field
 }
 
 // MetricsResponse represents overall analytics metrics
 type MetricsResponse struct {
+	Range             DateRange `json:"range"`
+	ComparedTo        *DateRange `json:"comparedTo,omitempty"`
 	AvgCompletionRate float64 `json:"avgCompletionRate"` // Average across all habits
-	AvgStreak         float64 `json:"avgStreak"`         // Average current streak
-	TotalCompletions  int     `json:"totalCompletions"`   // Total all-time completions
+	AvgStreak         float64 `json:"avgStreak"`         // Average streak at the end of the range
+	TotalCompletions  int     `json:"totalCompletions"`   // Total completions in the range
 	ConsistencyScore  float64 `json:"consistencyScore"`  // Percentage (0-100)
+	Deltas            map[string]Delta `json:"deltas,omitempty"` // Keyed by field
 }
 
 // TopHabitsResponse represents top performing habits
 type TopHabitsResponse struct {
-	Data []TopHabitDataPoint `json:"data"`
+	Range      DateRange           `json:"range"`
+	ComparedTo *DateRange          `json:"comparedTo,omitempty"`
+	Data       []TopHabitDataPoint `json:"data"`
 }
 
 // TopHabitDataPoint represents a single top habit
@@ -61,8 +136,10 @@ type TopHabitDataPoint struct {
 	Name           string  `json:"name"`
 	Category       string  `json:"category"`
 	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+	Strength       float64 `json:"strength"`       // 0-100
 	CurrentStreak  int     `json:"currentStreak"`
 	LongestStreak  int     `json:"longestStreak"`
+	Deltas         map[string]Delta `json:"deltas,omitempty"` // Keyed by completionRate and strength
 }
 
 // StreakLeaderboardResponse represents habits sorted by streak
@@ -86,9 +163,107 @@ type InsightsResponse struct {
 
 // Insight represents a single insight
 type Insight struct {
+	ID          string `json:"id"`          // Stable across requests, used to dismiss or snooze the insight
+	RuleID      string `json:"ruleId"`
 	Type        string `json:"type"`        // "positive", "suggestion", "achievement"
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string `json:"title"`       // English rendering of TitleKey
+	Description string `json:"description"` // English rendering of MessageKey
 	Priority    string `json:"priority"`    // "high", "medium", "low"
+	// Message keys and arguments, for clients that localize insights
+	TitleKey   string            `json:"titleKey"`
+	MessageKey string            `json:"messageKey"`
+	Args       map[string]string `json:"args"`
 }
 
+
+// ChainAnalyticsResponse represents how often habit stacks are followed through
+type ChainAnalyticsResponse struct {
+	Range      DateRange        `json:"range"`
+	ComparedTo *DateRange       `json:"comparedTo,omitempty"`
+	Data       []ChainDataPoint `json:"data"`
+}
+
+// ChainDataPoint represents one stacking chain, from its first habit to its last
+type ChainDataPoint struct {
+	HabitIDs       []string             `json:"habitIds"`
+	HabitNames     []string             `json:"habitNames"`
+	DaysStarted    int                  `json:"daysStarted"`    // Days the first habit was completed
+	DaysCompleted  int                  `json:"daysCompleted"`  // Days every habit of the chain was completed
+	CompletionRate float64              `json:"completionRate"` // Percentage of started days completed in full
+	Links          []ChainLinkDataPoint `json:"links"`
+	Deltas         map[string]Delta     `json:"deltas,omitempty"` // Keyed by field
+}
+
+// ChainLinkDataPoint represents a single "after X do Y" link of a chain
+type ChainLinkDataPoint struct {
+	FromHabitID   string  `json:"fromHabitId"`
+	FromHabitName string  `json:"fromHabitName"`
+	ToHabitID     string  `json:"toHabitId"`
+	ToHabitName   string  `json:"toHabitName"`
+	Held          int     `json:"held"`      // Days the chain continued past this link
+	Broken        int     `json:"broken"`    // Days the chain stopped at this link
+	BreakRate     float64 `json:"breakRate"` // Percentage (0-100)
+}
+
+// CorrelationsResponse represents the strongest relationships between a user's habits
+type CorrelationsResponse struct {
+	Range    DateRange                `json:"range"`
+	Positive []CorrelationDataPoint `json:"positive"` // Strongest first
+	Negative []CorrelationDataPoint `json:"negative"` // Strongest first
+}
+
+// CorrelationDataPoint represents how completing one habit relates to completing another,
+// on the same day or on the next day
+type CorrelationDataPoint struct {
+	Type           string  `json:"type"`           // "same_day" or "next_day"
+	HabitID        string  `json:"habitId"`
+	HabitName      string  `json:"habitName"`
+	OtherHabitID   string  `json:"otherHabitId"`   // The habit completed the same day or the day after
+	OtherHabitName string  `json:"otherHabitName"`
+	Coefficient    float64 `json:"coefficient"`    // Phi coefficient (-1 to 1)
+	Samples        int     `json:"samples"`        // Days both habits were scheduled
+	RateWith       float64 `json:"rateWith"`       // Percentage of samples the other habit was completed when this one was
+	RateWithout    float64 `json:"rateWithout"`    // Percentage of samples the other habit was completed when this one was not
+}
+
+// PredictionsResponse represents how likely each habit scheduled today is to be completed
+type PredictionsResponse struct {
+	Date         string               `json:"date"`         // Format: "yyyy-MM-dd"
+	Data         []PredictionDataPoint `json:"data"`         // Least likely first
+	AtRiskCount  int                  `json:"atRiskCount"`
+}
+
+// PredictionDataPoint represents one habit's estimated chance of being completed today
+type PredictionDataPoint struct {
+	HabitID        string  `json:"habitId"`
+	Name           string  `json:"name"`
+	Probability    float64 `json:"probability"`    // 0-1; 1 once completed today
+	CompletedToday bool    `json:"completedToday"`
+	CurrentStreak  int     `json:"currentStreak"`
+	StreakAtRisk   bool    `json:"streakAtRisk"`   // A running streak ends unless the habit is completed
+	WeekdayRate    float64 `json:"weekdayRate"`    // Recent completion rate on today's day of the week (0-1)
+	RecentRate     float64 `json:"recentRate"`     // Completion rate over the last two weeks (0-1)
+	Strength       float64 `json:"strength"`       // Strength score (0-100)
+}
+
+// TimeOfDayResponse represents when in the day habits are completed
+type TimeOfDayResponse struct {
+	Range   DateRange          `json:"range"`
+	Overall []HourDataPoint    `json:"overall"` // All habits combined, hours 0-23
+	Habits  []HabitTimeOfDay   `json:"habits"`
+}
+
+// HabitTimeOfDay represents the hour-of-day distribution of one habit's completions
+type HabitTimeOfDay struct {
+	HabitID     string          `json:"habitId"`
+	Name        string          `json:"name"`
+	Completions int             `json:"completions"` // Completions with a known time
+	Hours       []HourDataPoint `json:"hours"`       // Hours 0-23
+	TypicalTime *string         `json:"typicalTime,omitempty"` // Format: "HH:mm"; set when completions cluster around one time
+}
+
+// HourDataPoint represents the completions logged within one hour of the day
+type HourDataPoint struct {
+	Hour        int `json:"hour"` // 0-23, in the time zone the user was in
+	Completions int `json:"completions"`
+}
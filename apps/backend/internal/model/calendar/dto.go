@@ -1,5 +1,7 @@
 package calendar
 
+import "github.com/reche13/habitum/internal/model/habitlog"
+
 // CompletionsResponse represents completions for a date range
 type CompletionsResponse struct {
 	Completions []CompletionDay `json:"completions"`
@@ -13,6 +15,8 @@ type CompletionDay struct {
 	CompletionRate  float64       `json:"completionRate"`  // Percentage (0-100)
 	TotalHabits     int           `json:"totalHabits"`
 	CompletedHabits int           `json:"completedHabits"`
+	// Statuses maps habit IDs to the status logged on this day
+	Statuses map[string]habitlog.Status `json:"statuses"`
 }
 
 // HabitInfo represents basic habit info for calendar
@@ -57,9 +61,10 @@ type YearResponse struct {
 // DayData represents a single day in month/week view
 type DayData struct {
 	Date           string   `json:"date"`           // Format: "yyyy-MM-dd"
-	DayOfWeek      int      `json:"dayOfWeek,omitempty"` // 0=Monday, 6=Sunday
+	DayOfWeek      int      `json:"dayOfWeek,omitempty"` // 0 = first day of the user's week
 	Completions    []string `json:"completions"`    // Array of habit IDs
 	CompletionRate float64  `json:"completionRate"` // Percentage (0-100)
+	Statuses map[string]habitlog.Status `json:"statuses"` // Habit ID -> logged status
 }
 
 // HeatmapDay represents a day in year heatmap
@@ -7,7 +7,10 @@ type DashboardResponse struct {
 	Today           TodayStats        `json:"today"`
 	HabitsToComplete []HabitSummary   `json:"habitsToComplete"`
 	HabitsCompleted  []HabitSummary   `json:"habitsCompleted"`
+	UpNext           []HabitSummary   `json:"upNext"` // Habits stacked on one completed today
+	Routines         []RoutineSummary `json:"routines"`
 	ActiveStreaks    []StreakSummary  `json:"activeStreaks"`
+	StreaksAtRisk    []HabitSummary   `json:"streaksAtRisk"` // Least likely to be completed first
 	QuickStats       QuickStats       `json:"quickStats"`
 	Achievements     []AchievementSummary `json:"recentAchievements,omitempty"`
 }
@@ -33,6 +36,24 @@ type HabitSummary struct {
 	CurrentStreak   int        `json:"currentStreak"`
 	CompletedToday  bool       `json:"completedToday"`
 	CompletedTodayAt *time.Time `json:"completedTodayAt,omitempty"`
+	// Progress towards this week's target, for weekly habits
+	CompletedThisWeek int     `json:"completedThisWeek,omitempty"`
+	WeeklyTarget    int        `json:"weeklyTarget,omitempty"`
+	// Estimated chance (0-1) of being completed today, for habits scheduled today
+	Likelihood      *float64   `json:"likelihood,omitempty"`
+	StreakAtRisk    bool       `json:"streakAtRisk,omitempty"`
+}
+
+// RoutineSummary represents today's progress of a routine
+type RoutineSummary struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	TimeOfDay      string   `json:"timeOfDay"`
+	HabitIDs       []string `json:"habitIds"`
+	CompletedCount int      `json:"completedCount"`
+	TotalCount     int      `json:"totalCount"`
+	CompletionRate float64  `json:"completionRate"` // Percentage (0-100)
+	Completed      bool     `json:"completed"`
 }
 
 // StreakSummary represents a habit with active streak
@@ -53,7 +74,7 @@ type QuickStats struct {
 	TotalHabits    int     `json:"totalHabits"`
 }
 
-// AchievementSummary represents an achievement (placeholder for future)
+// AchievementSummary represents an unlocked achievement
 type AchievementSummary struct {
 	ID          string     `json:"id"`
 	Name        string     `json:"name"`
@@ -0,0 +1,32 @@
+package goal
+
+import "github.com/google/uuid"
+
+// MilestonePayload describes a milestone. An ID, or else the same kind and
+// target, keeps an existing milestone and the time it was reached.
+type MilestonePayload struct {
+	ID     *uuid.UUID    `json:"id,omitempty"`
+	Name   string        `json:"name" validate:"required,min=1,max=100"`
+	Kind   MilestoneKind `json:"kind" validate:"required,oneof=total_completions streak cumulative_value"`
+	Target float64       `json:"target" validate:"required,gt=0"`
+	Unit   *string       `json:"unit,omitempty" validate:"omitempty,max=20"`
+}
+
+type CreateGoalPayload struct {
+	Name        string             `json:"name" validate:"required,min=1,max=100"`
+	Description *string            `json:"description,omitempty"`
+	StartDate   *string            `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	TargetDate  *string            `json:"target_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	HabitIDs    []uuid.UUID        `json:"habit_ids" validate:"required,min=1,dive,required"`
+	Milestones  []MilestonePayload `json:"milestones" validate:"required,min=1,dive"`
+}
+
+// UpdateGoalPayload changes the set fields. Milestones, when given, replace
+// the existing ones.
+type UpdateGoalPayload struct {
+	Name        *string             `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string             `json:"description,omitempty"`
+	TargetDate  *string             `json:"target_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	HabitIDs    *[]uuid.UUID        `json:"habit_ids,omitempty" validate:"omitempty,min=1,dive,required"`
+	Milestones  *[]MilestonePayload `json:"milestones,omitempty" validate:"omitempty,min=1,dive"`
+}
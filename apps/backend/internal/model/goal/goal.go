@@ -0,0 +1,48 @@
+package goal
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+)
+
+type MilestoneKind string
+
+const (
+	// TotalCompletions counts completions of the linked habits
+	TotalCompletions MilestoneKind = "total_completions"
+	// Streak is the best streak reached by any linked habit
+	Streak MilestoneKind = "streak"
+	// CumulativeValue sums the values logged for the linked habits
+	CumulativeValue MilestoneKind = "cumulative_value"
+)
+
+type Goal struct {
+	model.Base
+
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	Description *string    `json:"description,omitempty" db:"description"`
+	StartDate   time.Time  `json:"start_date" db:"start_date"`
+	TargetDate  *time.Time `json:"target_date,omitempty" db:"target_date"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+type Milestone struct {
+	model.Base
+
+	GoalID      uuid.UUID     `json:"goal_id" db:"goal_id"`
+	Name        string        `json:"name" db:"name"`
+	Kind        MilestoneKind `json:"kind" db:"kind"`
+	Target      float64       `json:"target" db:"target"`
+	Unit        *string       `json:"unit,omitempty" db:"unit"`
+	Position    int           `json:"position" db:"position"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// Link connects a habit to a goal
+type Link struct {
+	GoalID  uuid.UUID `json:"goal_id" db:"goal_id"`
+	HabitID uuid.UUID `json:"habit_id" db:"habit_id"`
+}
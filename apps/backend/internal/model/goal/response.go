@@ -0,0 +1,32 @@
+package goal
+
+import "time"
+
+// GoalResponse includes the goal with its habits and milestone progress
+type GoalResponse struct {
+	Goal
+	Habits     []GoalHabit         `json:"habits"`
+	Milestones []MilestoneProgress `json:"milestones"`
+	Progress   float64             `json:"progress"` // Percentage (0-100), average over milestones
+}
+
+// GoalHabit represents a habit linked to a goal
+type GoalHabit struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Icon  *string `json:"icon,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// MilestoneProgress is a milestone with its current value
+type MilestoneProgress struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Kind        MilestoneKind `json:"kind"`
+	Target      float64       `json:"target"`
+	Unit        *string       `json:"unit,omitempty"`
+	Current     float64       `json:"current"`
+	Progress    float64       `json:"progress"` // Percentage (0-100)
+	Completed   bool          `json:"completed"`
+	CompletedAt *time.Time    `json:"completedAt,omitempty"`
+}
@@ -1,5 +1,7 @@
 package habit
 
+import "github.com/google/uuid"
+
 type CreateHabitPayload struct {
 	Name string `json:"name" validate:"required,min=1,max=100"`
 	Description *string `json:"description"`
@@ -8,6 +10,14 @@ type CreateHabitPayload struct {
 	Category Category `json:"category" validate:"required"`
 	Frequency Frequency `json:"frequency" validate:"required"`
 	TimesPerWeek *int `json:"times_per_week,omitempty"`
+	// Difficulty defaults to medium
+	Difficulty Difficulty `json:"difficulty,omitempty" validate:"omitempty,oneof=easy medium hard"`
+	// Challenge window: an end date or a duration turns the habit into a
+	// challenge starting on StartDate (today when omitted)
+	StartDate *string `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	DurationDays *int `json:"duration_days,omitempty" validate:"omitempty,min=1,max=366"`
+	StackAfterID *uuid.UUID `json:"stack_after_id,omitempty"`
 }
 
 type UpdateHabitPayload struct {
@@ -18,4 +28,9 @@ type UpdateHabitPayload struct {
 	Category *Category `json:"category,omitempty"`
 	Frequency *Frequency `json:"frequency,omitempty"`
 	TimesPerWeek *int `json:"times_per_week,omitempty"`
-}
\ No newline at end of file
+	Difficulty *Difficulty `json:"difficulty,omitempty" validate:"omitempty,oneof=easy medium hard"`
+	StartDate *string `json:"start_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	// StackAfterID stacks the habit on another one; the nil UUID unstacks it
+	StackAfterID *uuid.UUID `json:"stack_after_id,omitempty"`
+}
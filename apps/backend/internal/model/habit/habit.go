@@ -14,6 +14,15 @@ const (
 	Weekly Frequency = "weekly"
 )
 
+// Difficulty weighs the XP a completion earns
+type Difficulty string
+
+const (
+	Easy Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard Difficulty = "hard"
+)
+
 type Category string
 
 const (
@@ -29,6 +38,22 @@ const (
 	Other Category = "other"
 )
 
+// Categories returns all known categories in display order
+func Categories() []Category {
+	return []Category{
+		Health,
+		Productivity,
+		Learning,
+		Work,
+		Personal,
+		Mindfulness,
+		Social,
+		Creative,
+		Finance,
+		Other,
+	}
+}
+
 type Habit struct {
 	model.Base
 
@@ -40,7 +65,47 @@ type Habit struct {
 	Category Category `json:"category" db:"category"`
 	Frequency Frequency `json:"frequency" db:"frequency"`
 	TimesPerWeek *int `json:"times_per_week,omitempty" db:"times_per_week"`
+	Difficulty Difficulty `json:"difficulty" db:"difficulty"`
 	CurrentStreak int `json:"current_streak" db:"current_streak"`
 	LongestStreak int `json:"longest_streak" db:"longest_streak"`
+	// LastCompletedOn is the latest completed day (for weekly habits, of the
+	// latest week that met the target); the stored current streak is the
+	// streak ending on it
+	LastCompletedOn *time.Time `json:"last_completed_on,omitempty" db:"last_completed_on"`
 	ArchivedAt *time.Time `json:"archived_at,omitempty" db:"archived_at"`
+	// StartDate and EndDate are set together for time-boxed challenges
+	StartDate *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate *time.Time `json:"end_date,omitempty" db:"end_date"`
+	// StackAfterID is the habit this one is stacked on: after completing it,
+	// this habit is suggested next
+	StackAfterID *uuid.UUID `json:"stack_after_id,omitempty" db:"stack_after_id"`
+}
+
+// WeeklyTarget returns how many completions make a successful week. Weekly
+// habits without a times per week target need one.
+func (h *Habit) WeeklyTarget() int {
+	if h.Frequency != Weekly || h.TimesPerWeek == nil || *h.TimesPerWeek < 1 {
+		return 1
+	}
+	return *h.TimesPerWeek
+}
+
+// ChallengeWindow is the inclusive date range of a time-boxed challenge
+type ChallengeWindow struct {
+	StartDate time.Time
+	EndDate time.Time
+}
+
+// IsChallenge reports whether the habit only runs for a limited time
+func (h *Habit) IsChallenge() bool {
+	return h.StartDate != nil && h.EndDate != nil
+}
+
+// InChallengeWindow reports whether the (normalized) date falls inside the
+// challenge. Habits that are not challenges have no window.
+func (h *Habit) InChallengeWindow(date time.Time) bool {
+	if !h.IsChallenge() {
+		return true
+	}
+	return !date.Before(*h.StartDate) && !date.After(*h.EndDate)
 }
\ No newline at end of file
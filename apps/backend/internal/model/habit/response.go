@@ -9,6 +9,81 @@ type HabitResponse struct {
 	CompletedToday     bool       `json:"completedToday"`
 	CompletedTodayAt   *time.Time `json:"completedTodayAt,omitempty"`
 	CompletedThisWeek  int        `json:"completedThisWeek"`
+	// WeeklyTarget is the completions this week needs to count, for weekly
+	// habits; together with CompletedThisWeek it gives progress such as 2/3
+	WeeklyTarget       int        `json:"weeklyTarget,omitempty"`
+	Strength           float64    `json:"strength"` // 0-100, weighted towards recent outcomes
 	CompletionHistory  []string   `json:"completionHistory,omitempty"`
 }
 
+// ChallengeStatus describes where a challenge is in its lifetime
+type ChallengeStatus string
+
+const (
+	ChallengeUpcoming ChallengeStatus = "upcoming"
+	ChallengeActive ChallengeStatus = "active"
+	ChallengeSucceeded ChallengeStatus = "succeeded"
+	ChallengeFailed ChallengeStatus = "failed"
+)
+
+// ChallengeProgress summarises a time-boxed challenge. Periods are days for
+// daily habits and weeks for weekly ones.
+type ChallengeProgress struct {
+	HabitID            string          `json:"habitId"`
+	StartDate          string          `json:"startDate"`
+	EndDate            string          `json:"endDate"`
+	Status             ChallengeStatus `json:"status"`
+	Unit               string          `json:"unit"` // "day" or "week"
+	TotalPeriods       int             `json:"totalPeriods"`
+	PeriodsDone        int             `json:"periodsDone"`
+	PeriodsMissed      int             `json:"periodsMissed"`
+	PeriodsExcused     int             `json:"periodsExcused"`
+	PeriodsRemaining   int             `json:"periodsRemaining"`
+	PeriodsNeeded      int             `json:"periodsNeeded"`  // Still required to succeed
+	DaysRemaining      int             `json:"daysRemaining"`
+	SuccessThreshold   float64         `json:"successThreshold"`   // Percentage (0-100)
+	SuccessProbability float64         `json:"successProbability"` // Percentage (0-100)
+}
+
+// HabitStatsResponse gathers everything a habit's detail screen shows.
+// Streaks count days for daily habits and weeks for weekly ones.
+type HabitStatsResponse struct {
+	HabitID          string                `json:"habitId"`
+	Unit             string                `json:"unit"` // "day" or "week"
+	TotalCompletions int                   `json:"totalCompletions"`
+	FirstCompletion  *string               `json:"firstCompletion,omitempty"` // Format: "yyyy-MM-dd"
+	LastCompletion   *string               `json:"lastCompletion,omitempty"`  // Format: "yyyy-MM-dd"
+	AvgGapDays       *float64              `json:"avgGapDays,omitempty"`      // Average days between consecutive completions
+	CompletionRate   float64               `json:"completionRate"`            // Percentage (0-100) since creation
+	CurrentStreak    StreakSpan            `json:"currentStreak"`
+	LongestStreak    StreakSpan            `json:"longestStreak"`
+	StreakHistory    []StreakSpan          `json:"streakHistory"` // Newest first
+	Monthly          []MonthlyCompletion   `json:"monthly"`       // Oldest first
+	DayOfWeek        []DayOfWeekCompletion `json:"dayOfWeek"`
+}
+
+// StreakSpan is one streak of a habit. Dates are unset when there is none.
+type StreakSpan struct {
+	Length    int     `json:"length"`
+	StartDate *string `json:"startDate,omitempty"` // First day, or week start
+	EndDate   *string `json:"endDate,omitempty"`   // Last day, or week start
+	Active    bool    `json:"active"`
+}
+
+// MonthlyCompletion represents a habit's completion rate in one month
+type MonthlyCompletion struct {
+	Month          string  `json:"month"` // Format: "yyyy-MM"
+	Completions    int     `json:"completions"`
+	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+}
+
+// DayOfWeekCompletion represents a habit's completions on one day of the week
+type DayOfWeekCompletion struct {
+	Day         string `json:"day"`      // "Monday", "Tuesday", etc.
+	DayIndex    int    `json:"dayIndex"` // 0 = first day of the user's week
+	Completions int    `json:"completions"`
+	// Scheduled and CompletionRate are only set for daily habits
+	Scheduled      int     `json:"scheduled,omitempty"`
+	CompletionRate float64 `json:"completionRate,omitempty"` // Percentage (0-100)
+	Share          float64 `json:"share"`                    // Percentage (0-100) of all completions
+}
@@ -0,0 +1,18 @@
+package habit
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Strength is a habit's strength score at the end of a day. The score is an
+// exponential moving average of the habit's scheduled outcomes, from 0 to 100.
+type Strength struct {
+	HabitID   uuid.UUID `json:"habit_id" db:"habit_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Day       time.Time `json:"day" db:"day"`
+	Score     float64   `json:"score" db:"score"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
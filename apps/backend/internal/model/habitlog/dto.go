@@ -10,4 +10,34 @@ type HabitLogPayload struct {
 	HabitID uuid.UUID `json:"habit_id" db:"habit_id"`
 	LogDate time.Time `json:"log_date" db:"log_date"`
 	Completed bool `json:"completed" db:"completed"`
-}
\ No newline at end of file
+	// Status takes precedence over Completed when set
+	Status Status `json:"status,omitempty" db:"status" validate:"omitempty,oneof=completed skipped failed partial"`
+	Value *float64 `json:"value,omitempty" db:"value" validate:"omitempty,gte=0"`
+	// CompletedAt defaults to now for completions logged on their own day
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	// CompletedTimezone is the user's time zone when the completion was logged
+	CompletedTimezone *string `json:"-" db:"completed_timezone"`
+	// Note keeps the note logged earlier for the day when unset
+	Note *string `json:"note,omitempty" db:"note" validate:"omitempty,max=500"`
+}
+
+// ResolvedStatus returns the status to store for this payload
+func (p *HabitLogPayload) ResolvedStatus() Status {
+	if p.Status != "" {
+		return p.Status
+	}
+	if p.Completed {
+		return Completed
+	}
+	return Failed
+}
+
+// SetStatusPayload records a status other than a plain completion for a day,
+// for example marking a sick day as skipped
+type SetStatusPayload struct {
+	Status Status `json:"status" validate:"required,oneof=completed skipped failed partial"`
+	// Date defaults to today when empty
+	Date string `json:"date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Value *float64 `json:"value,omitempty" validate:"omitempty,gte=0"`
+	Note *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
@@ -7,11 +7,54 @@ import (
 	"github.com/reche13/habitum/internal/model"
 )
 
+type Status string
+
+const (
+	Completed Status = "completed"
+	Skipped Status = "skipped"
+	Failed Status = "failed"
+	Partial Status = "partial"
+	// Frozen marks a missed day covered by a streak freeze
+	Frozen Status = "frozen"
+)
+
+// IsExcused reports whether the status leaves the day out of streaks and
+// rates instead of counting it as missed
+func (s Status) IsExcused() bool {
+	return s == Skipped || s == Frozen
+}
+
 type HabitLog struct {
 	model.Base
 
 	UserID uuid.UUID `json:"user_id" db:"user_id"`
 	HabitID uuid.UUID `json:"habit_id" db:"habit_id"`
 	LogDate time.Time `json:"log_date" db:"log_date"`
+	Status Status `json:"status" db:"status"`
+	// Completed is derived from Status
 	Completed bool `json:"completed" db:"completed"`
-}
\ No newline at end of file
+	// Value is the amount logged for quantitative habits
+	Value *float64 `json:"value,omitempty" db:"value"`
+	// CompletedAt is when the completion was logged, in CompletedTimezone.
+	// It is only known for completions logged on their own day or given a time.
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CompletedTimezone *string `json:"completed_timezone,omitempty" db:"completed_timezone"`
+	// Note is a short reflection on the day
+	Note *string `json:"note,omitempty" db:"note"`
+}
+
+// LocalCompletedAt returns when the completion was logged, on the clock of
+// the time zone the user was in, and false when that is not known
+func (l *HabitLog) LocalCompletedAt() (time.Time, bool) {
+	if l.CompletedAt == nil {
+		return time.Time{}, false
+	}
+
+	loc := time.UTC
+	if l.CompletedTimezone != nil {
+		if tz, err := time.LoadLocation(*l.CompletedTimezone); err == nil {
+			loc = tz
+		}
+	}
+	return l.CompletedAt.In(loc), true
+}
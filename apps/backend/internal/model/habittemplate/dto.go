@@ -0,0 +1,13 @@
+package habittemplate
+
+// CreateFromTemplatePayload holds optional overrides applied on top of a template
+type CreateFromTemplatePayload struct {
+	Name  *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Icon  *string `json:"icon,omitempty"`
+	Color *string `json:"color,omitempty"`
+}
+
+// SaveAsTemplatePayload is used to save an existing habit as a private template
+type SaveAsTemplatePayload struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+}
@@ -0,0 +1,33 @@
+package habittemplate
+
+import (
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+	"github.com/reche13/habitum/internal/model/habit"
+)
+
+// HabitTemplate is a private template saved by a user from one of their habits
+type HabitTemplate struct {
+	model.Base
+
+	UserID       uuid.UUID       `json:"user_id" db:"user_id"`
+	Name         string          `json:"name" db:"name"`
+	Description  *string         `json:"description" db:"description"`
+	Icon         *string         `json:"icon" db:"icon"`
+	Color        *string         `json:"color" db:"color"`
+	Category     habit.Category  `json:"category" db:"category"`
+	Frequency    habit.Frequency `json:"frequency" db:"frequency"`
+	TimesPerWeek *int            `json:"times_per_week,omitempty" db:"times_per_week"`
+}
+
+// BuiltinTemplate is an entry of the embedded template catalogue
+type BuiltinTemplate struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Description  *string         `json:"description,omitempty"`
+	Icon         *string         `json:"icon,omitempty"`
+	Color        *string         `json:"color,omitempty"`
+	Category     habit.Category  `json:"category"`
+	Frequency    habit.Frequency `json:"frequency"`
+	TimesPerWeek *int            `json:"times_per_week,omitempty"`
+}
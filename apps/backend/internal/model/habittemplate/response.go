@@ -0,0 +1,28 @@
+package habittemplate
+
+import "github.com/reche13/habitum/internal/model/habit"
+
+// TemplatesResponse lists templates grouped by category
+type TemplatesResponse struct {
+	Data []CategoryTemplates `json:"data"`
+}
+
+// CategoryTemplates represents all templates of a single category
+type CategoryTemplates struct {
+	Category  habit.Category     `json:"category"`
+	Label     string             `json:"label"`
+	Templates []TemplateResponse `json:"templates"`
+}
+
+// TemplateResponse represents a built-in or user-defined template
+type TemplateResponse struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	Description  *string         `json:"description,omitempty"`
+	Icon         *string         `json:"icon,omitempty"`
+	Color        *string         `json:"color,omitempty"`
+	Category     habit.Category  `json:"category"`
+	Frequency    habit.Frequency `json:"frequency"`
+	TimesPerWeek *int            `json:"times_per_week,omitempty"`
+	BuiltIn      bool            `json:"builtIn"`
+}
@@ -0,0 +1,7 @@
+package insight
+
+// SnoozePayload hides an insight until a date, or for a number of days
+type SnoozePayload struct {
+	Until string `json:"until,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Days  int    `json:"days,omitempty" validate:"omitempty,min=1,max=365"`
+}
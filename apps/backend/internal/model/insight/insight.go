@@ -0,0 +1,24 @@
+package insight
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Dismissal hides an insight from the user, until SnoozedUntil when it was
+// snoozed and for its rule's cooldown otherwise
+type Dismissal struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	InsightID    string     `json:"insight_id" db:"insight_id"`
+	DismissedAt  time.Time  `json:"dismissed_at" db:"dismissed_at"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty" db:"snoozed_until"`
+}
+
+// HiddenAt reports whether the dismissal still hides its insight at now
+func (d *Dismissal) HiddenAt(now time.Time, cooldown time.Duration) bool {
+	if d.SnoozedUntil != nil {
+		return now.Before(*d.SnoozedUntil)
+	}
+	return now.Before(d.DismissedAt.Add(cooldown))
+}
@@ -0,0 +1,15 @@
+package pause
+
+import "github.com/google/uuid"
+
+type CreatePausePayload struct {
+	HabitID   *uuid.UUID `json:"habit_id,omitempty"`
+	StartDate string     `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   *string    `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Reason    *string    `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
+
+type UpdatePausePayload struct {
+	EndDate *string `json:"end_date,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Reason  *string `json:"reason,omitempty" validate:"omitempty,max=255"`
+}
@@ -0,0 +1,28 @@
+package pause
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+)
+
+// Pause freezes a habit, or every habit of a user when HabitID is nil,
+// between StartDate and EndDate (inclusive). An open pause has no EndDate.
+type Pause struct {
+	model.Base
+
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	HabitID   *uuid.UUID `json:"habit_id,omitempty" db:"habit_id"`
+	StartDate time.Time  `json:"start_date" db:"start_date"`
+	EndDate   *time.Time `json:"end_date,omitempty" db:"end_date"`
+	Reason    *string    `json:"reason,omitempty" db:"reason"`
+}
+
+// Covers reports whether the pause includes the given (normalized) date
+func (p *Pause) Covers(date time.Time) bool {
+	if date.Before(p.StartDate) {
+		return false
+	}
+	return p.EndDate == nil || !date.After(*p.EndDate)
+}
@@ -0,0 +1,13 @@
+package points
+
+type CreateRewardPayload struct {
+	Name        string  `json:"name" validate:"required,min=1,max=100"`
+	Description *string `json:"description"`
+	Cost        int     `json:"cost" validate:"required,min=1"`
+}
+
+type UpdateRewardPayload struct {
+	Name        *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty"`
+	Cost        *int    `json:"cost,omitempty" validate:"omitempty,min=1"`
+}
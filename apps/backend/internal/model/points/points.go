@@ -0,0 +1,50 @@
+package points
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+)
+
+type Source string
+
+const (
+	// SourceCompletion entries earn XP and points for a completed log, or reverse them
+	SourceCompletion Source = "completion"
+	// SourceAchievement entries reward unlocking an achievement
+	SourceAchievement Source = "achievement"
+	// SourceReward entries spend points on one of the user's rewards
+	SourceReward Source = "reward"
+)
+
+// LedgerEntry is one change to a user's points and XP
+type LedgerEntry struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Source         Source     `json:"source" db:"source"`
+	Points         int        `json:"points" db:"points"`
+	XP             int        `json:"xp" db:"xp"`
+	Description    string     `json:"description" db:"description"`
+	HabitID        *uuid.UUID `json:"habit_id,omitempty" db:"habit_id"`
+	LogDate        *time.Time `json:"log_date,omitempty" db:"log_date"`
+	AchievementKey *string    `json:"achievement_key,omitempty" db:"achievement_key"`
+	RewardID       *uuid.UUID `json:"reward_id,omitempty" db:"reward_id"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Balance totals a user's ledger
+type Balance struct {
+	Points int `db:"points"`
+	XP     int `db:"xp"`
+}
+
+// Reward is something the user can buy with points, such as "a new book"
+type Reward struct {
+	model.Base
+
+	UserID      uuid.UUID `json:"user_id" db:"user_id"`
+	Name        string    `json:"name" db:"name"`
+	Description *string   `json:"description,omitempty" db:"description"`
+	Cost        int       `json:"cost" db:"cost"`
+}
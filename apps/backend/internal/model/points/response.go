@@ -0,0 +1,18 @@
+package points
+
+// SummaryResponse represents a user's points, XP and level
+type SummaryResponse struct {
+	Points        int     `json:"points"` // Spendable balance
+	XP            int     `json:"xp"`     // Total XP earned
+	Level         int     `json:"level"`
+	LevelXP       int     `json:"levelXp"`       // XP at which the current level started
+	NextLevelXP   int     `json:"nextLevelXp"`   // XP at which the next level starts
+	LevelProgress float64 `json:"levelProgress"` // Percentage (0-100) of the way to the next level
+}
+
+// RedeemResponse represents a reward bought with points
+type RedeemResponse struct {
+	Reward  Reward      `json:"reward"`
+	Entry   LedgerEntry `json:"entry"`
+	Balance int         `json:"balance"` // Points left
+}
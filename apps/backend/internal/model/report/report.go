@@ -0,0 +1,25 @@
+package report
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Period string
+
+const (
+	Weekly  Period = "weekly"
+	Monthly Period = "monthly"
+)
+
+// Report is a stored review of a finished week or month
+type Report struct {
+	ID          uuid.UUID      `json:"id" db:"id"`
+	UserID      uuid.UUID      `json:"user_id" db:"user_id"`
+	Period      Period         `json:"period" db:"period"`
+	PeriodStart time.Time      `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time      `json:"period_end" db:"period_end"`
+	Data        ReportResponse `json:"data" db:"data"`
+	GeneratedAt time.Time      `json:"generated_at" db:"generated_at"`
+}
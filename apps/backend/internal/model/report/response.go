@@ -0,0 +1,61 @@
+package report
+
+import (
+	"time"
+
+	"github.com/reche13/habitum/internal/model/analytics"
+)
+
+// ReportResponse represents a review of one week or month
+type ReportResponse struct {
+	Period         Period              `json:"period"`
+	Range          analytics.DateRange `json:"range"`
+	ComparedTo     analytics.DateRange `json:"comparedTo"` // The period before
+	Final          bool                `json:"final"`      // The period is over and the report is stored
+	GeneratedAt    time.Time           `json:"generatedAt"`
+	Highlight      string              `json:"highlight"`
+	Completions    int                 `json:"completions"`
+	Scheduled      int                 `json:"scheduled"`
+	CompletionRate float64             `json:"completionRate"` // Percentage (0-100)
+	Comparison     analytics.Delta     `json:"comparison"`     // Completion rate against the period before
+	BestHabits     []ReportHabit       `json:"bestHabits"`
+	WorstHabits    []ReportHabit       `json:"worstHabits"`
+	StreaksStarted []ReportStreak      `json:"streaksStarted"`
+	StreaksBroken  []ReportStreak      `json:"streaksBroken"`
+	Achievements   []ReportAchievement `json:"achievements"`
+	Notes          []ReportNote        `json:"notes"`
+}
+
+// ReportHabit represents a habit's results over the period
+type ReportHabit struct {
+	HabitID        string  `json:"habitId"`
+	Name           string  `json:"name"`
+	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+	Completions    int     `json:"completions"`
+}
+
+// ReportStreak represents a streak that started or was broken in the period.
+// Length counts days, or weeks for weekly habits.
+type ReportStreak struct {
+	HabitID   string  `json:"habitId"`
+	Name      string  `json:"name"`
+	StartDate string  `json:"startDate"`          // Format: "yyyy-MM-dd"
+	Length    int     `json:"length"`             // By the end of the period, or when it was broken
+	BrokenOn  *string `json:"brokenOn,omitempty"` // The first missed day, or week for weekly habits
+}
+
+// ReportAchievement represents an achievement unlocked in the period
+type ReportAchievement struct {
+	Key        string    `json:"key"`
+	Name       string    `json:"name"`
+	Icon       string    `json:"icon"`
+	UnlockedAt time.Time `json:"unlockedAt"`
+}
+
+// ReportNote represents a note logged in the period
+type ReportNote struct {
+	HabitID string `json:"habitId"`
+	Name    string `json:"name"`
+	Date    string `json:"date"` // Format: "yyyy-MM-dd"
+	Note    string `json:"note"`
+}
@@ -0,0 +1,27 @@
+package rollup
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DailyRollup holds a user's habit totals for one day
+type DailyRollup struct {
+	UserID         uuid.UUID `json:"user_id" db:"user_id"`
+	Day            time.Time `json:"day" db:"day"`
+	ScheduledCount int       `json:"scheduled_count" db:"scheduled_count"`
+	CompletedCount int       `json:"completed_count" db:"completed_count"`
+	SkippedCount   int       `json:"skipped_count" db:"skipped_count"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CompletionRate returns the share of scheduled habits completed on the day,
+// as a percentage
+func (r DailyRollup) CompletionRate() float64 {
+	if r.ScheduledCount == 0 {
+		return 0
+	}
+	return float64(r.CompletedCount) / float64(r.ScheduledCount) * 100
+}
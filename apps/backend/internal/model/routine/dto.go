@@ -0,0 +1,15 @@
+package routine
+
+import "github.com/google/uuid"
+
+type CreateRoutinePayload struct {
+	Name      string      `json:"name" validate:"required,min=1,max=100"`
+	TimeOfDay TimeOfDay   `json:"time_of_day" validate:"omitempty,oneof=morning afternoon evening anytime"`
+	HabitIDs  []uuid.UUID `json:"habit_ids" validate:"required,min=1,dive,required"`
+}
+
+type UpdateRoutinePayload struct {
+	Name      *string      `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	TimeOfDay *TimeOfDay   `json:"time_of_day,omitempty" validate:"omitempty,oneof=morning afternoon evening anytime"`
+	HabitIDs  *[]uuid.UUID `json:"habit_ids,omitempty" validate:"omitempty,min=1,dive,required"`
+}
@@ -0,0 +1,30 @@
+package routine
+
+import "time"
+
+// RoutineResponse includes the routine with its ordered habits and today's progress
+type RoutineResponse struct {
+	Routine
+	Habits   []RoutineHabit `json:"habits"`
+	Progress Progress       `json:"progress"`
+}
+
+// RoutineHabit represents a member habit of a routine
+type RoutineHabit struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	Icon           *string    `json:"icon,omitempty"`
+	Color          *string    `json:"color,omitempty"`
+	Position       int        `json:"position"`
+	CompletedToday bool       `json:"completedToday"`
+	CompletedAt    *time.Time `json:"completedAt,omitempty"`
+}
+
+// Progress represents how many of the routine's habits are done on a date
+type Progress struct {
+	Date           string  `json:"date"` // Format: "yyyy-MM-dd"
+	CompletedCount int     `json:"completedCount"`
+	TotalCount     int     `json:"totalCount"`
+	CompletionRate float64 `json:"completionRate"` // Percentage (0-100)
+	Completed      bool    `json:"completed"`
+}
@@ -0,0 +1,30 @@
+package routine
+
+import (
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+)
+
+type TimeOfDay string
+
+const (
+	Morning   TimeOfDay = "morning"
+	Afternoon TimeOfDay = "afternoon"
+	Evening   TimeOfDay = "evening"
+	Anytime   TimeOfDay = "anytime"
+)
+
+type Routine struct {
+	model.Base
+
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	TimeOfDay TimeOfDay `json:"time_of_day" db:"time_of_day"`
+}
+
+// Member links a habit to a routine at a given position
+type Member struct {
+	RoutineID uuid.UUID `json:"routine_id" db:"routine_id"`
+	HabitID   uuid.UUID `json:"habit_id" db:"habit_id"`
+	Position  int       `json:"position" db:"position"`
+}
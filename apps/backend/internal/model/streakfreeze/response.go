@@ -0,0 +1,12 @@
+package streakfreeze
+
+// InventoryResponse lists the freezes left and how they were earned and spent
+type InventoryResponse struct {
+	Available     int            `json:"available"`
+	MaxAvailable  int            `json:"maxAvailable"`
+	MilestoneDays int            `json:"milestoneDays"`
+	EarnedTotal   int            `json:"earnedTotal"`
+	UsedTotal     int            `json:"usedTotal"`
+	Earned        []StreakFreeze `json:"earned"`
+	Usage         []Usage        `json:"usage"`
+}
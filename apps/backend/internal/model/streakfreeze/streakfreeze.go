@@ -0,0 +1,33 @@
+package streakfreeze
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model"
+)
+
+// StreakFreeze is a freeze earned by reaching a streak milestone. Spending it
+// logs the missed day with the frozen status and records the day on the
+// freeze, so it stays spent whatever happens to that log afterwards.
+type StreakFreeze struct {
+	model.Base
+
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	HabitID       *uuid.UUID `json:"habit_id,omitempty" db:"habit_id"`
+	EarnedOn      time.Time  `json:"earned_on" db:"earned_on"`
+	Streak        int        `json:"streak" db:"streak"`
+	UsedAt        *time.Time `json:"used_at,omitempty" db:"used_at"`
+	UsedOn        *time.Time `json:"used_on,omitempty" db:"used_on"`
+	UsedHabitID   *uuid.UUID `json:"used_habit_id,omitempty" db:"used_habit_id"`
+	UsedHabitName *string    `json:"used_habit_name,omitempty" db:"used_habit_name"`
+}
+
+// Usage is a day that was covered by a streak freeze. The habit ID is nil
+// once the habit was deleted.
+type Usage struct {
+	HabitID   *uuid.UUID `json:"habit_id" db:"habit_id"`
+	HabitName string     `json:"habit_name" db:"habit_name"`
+	Date      time.Time  `json:"date" db:"log_date"`
+	UsedAt    time.Time  `json:"used_at" db:"used_at"`
+}
@@ -8,4 +8,7 @@ type CreateUserPayload struct {
 type UpdateUserPayload struct {
 	Name  *string `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
 	Email *string `json:"email,omitempty" validate:"omitempty,email"`
+	Timezone *string `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	WeekStart *string `json:"week_start,omitempty" validate:"omitempty,oneof=sunday monday saturday"`
+	DayEndsAt *int `json:"day_ends_at,omitempty" validate:"omitempty,min=0,max=6"`
 }
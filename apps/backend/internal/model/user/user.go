@@ -20,4 +20,29 @@ type User struct {
 	OAuthProvider               *string    `json:"oauth_provider,omitempty" db:"oauth_provider"`
 	OAuthProviderID             *string    `json:"oauth_provider_id,omitempty" db:"oauth_provider_id"`
 	LastLoginAt                 *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	Timezone                    string     `json:"timezone" db:"timezone"`
+	WeekStart                   string     `json:"week_start" db:"week_start"`
+	DayEndsAt                   int        `json:"day_ends_at" db:"day_ends_at"`
+}
+
+// FirstWeekday returns the day the user's week starts on
+func (u *User) FirstWeekday() time.Weekday {
+	switch u.WeekStart {
+	case "sunday":
+		return time.Sunday
+	case "saturday":
+		return time.Saturday
+	default:
+		return time.Monday
+	}
+}
+
+// Location returns the user's time zone, falling back to UTC when the stored
+// name is unknown to this host
+func (u *User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
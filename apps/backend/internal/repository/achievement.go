@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/achievement"
+)
+
+type AchievementRepository struct {
+	db DBTX
+}
+
+func NewAchievementRepository(db DBTX) *AchievementRepository {
+	return &AchievementRepository{db: db}
+}
+
+// RecordProgress stores the progress towards each achievement, keyed by
+// achievement key, keeping the best progress reached. An achievement is
+// unlocked the first time its progress reaches its target. It returns the
+// achievements unlocked by this call.
+func (r *AchievementRepository) RecordProgress(
+	ctx context.Context,
+	userID uuid.UUID,
+	progress map[string]int,
+	targets map[string]int,
+) ([]achievement.Achievement, error) {
+	if len(progress) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, 0, len(progress))
+	values := make([]int32, 0, len(progress))
+	goals := make([]int32, 0, len(progress))
+	for key, value := range progress {
+		keys = append(keys, key)
+		values = append(values, int32(value))
+		goals = append(goals, int32(targets[key]))
+	}
+
+	stmt := `
+		WITH previous AS (
+			SELECT key, unlocked_at
+			FROM achievements
+			WHERE user_id = @user_id
+		),
+		upserted AS (
+			INSERT INTO achievements (user_id, key, progress, unlocked_at)
+			SELECT @user_id, t.key, t.progress, CASE WHEN t.progress >= t.target THEN NOW() END
+			FROM UNNEST(@keys::TEXT[], @progress::INT[], @targets::INT[])
+				AS t(key, progress, target)
+			ON CONFLICT (user_id, key)
+			DO UPDATE SET
+				progress = GREATEST(achievements.progress, EXCLUDED.progress),
+				unlocked_at = COALESCE(achievements.unlocked_at, EXCLUDED.unlocked_at),
+				updated_at = NOW()
+			RETURNING *
+		)
+		SELECT u.*
+		FROM upserted u
+		LEFT JOIN previous p ON p.key = u.key
+		WHERE u.unlocked_at IS NOT NULL
+			AND p.unlocked_at IS NULL
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":  userID,
+		"keys":     keys,
+		"progress": values,
+		"targets":  goals,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[achievement.Achievement])
+}
+
+// List returns the user's progress towards every achievement they have made
+// progress on
+func (r *AchievementRepository) List(ctx context.Context, userID uuid.UUID) ([]achievement.Achievement, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			achievements
+		WHERE
+			user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[achievement.Achievement])
+}
+
+// ListRecentUnlocked returns the user's latest unlocked achievements, newest
+// first
+func (r *AchievementRepository) ListRecentUnlocked(ctx context.Context, userID uuid.UUID, limit int) ([]achievement.Achievement, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			achievements
+		WHERE
+			user_id = @user_id
+			AND unlocked_at IS NOT NULL
+		ORDER BY
+			unlocked_at DESC
+		LIMIT @limit
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"limit":   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[achievement.Achievement])
+}
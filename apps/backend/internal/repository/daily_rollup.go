@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/rollup"
+)
+
+type DailyRollupRepository struct {
+	db DBTX
+}
+
+func NewDailyRollupRepository(db DBTX) *DailyRollupRepository {
+	return &DailyRollupRepository{db: db}
+}
+
+// Upsert stores the given rollups, replacing the totals of days that already
+// have one
+func (r *DailyRollupRepository) Upsert(ctx context.Context, userID uuid.UUID, rollups []rollup.DailyRollup) error {
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	days := make([]time.Time, len(rollups))
+	scheduled := make([]int32, len(rollups))
+	completed := make([]int32, len(rollups))
+	skipped := make([]int32, len(rollups))
+	for i, ru := range rollups {
+		days[i] = ru.Day
+		scheduled[i] = int32(ru.ScheduledCount)
+		completed[i] = int32(ru.CompletedCount)
+		skipped[i] = int32(ru.SkippedCount)
+	}
+
+	stmt := `
+		INSERT INTO daily_rollups (user_id, day, scheduled_count, completed_count, skipped_count)
+		SELECT @user_id, t.day, t.scheduled_count, t.completed_count, t.skipped_count
+		FROM UNNEST(@days::DATE[], @scheduled::INT[], @completed::INT[], @skipped::INT[])
+			AS t(day, scheduled_count, completed_count, skipped_count)
+		ON CONFLICT (user_id, day)
+		DO UPDATE SET
+			scheduled_count = EXCLUDED.scheduled_count,
+			completed_count = EXCLUDED.completed_count,
+			skipped_count = EXCLUDED.skipped_count,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"days":      days,
+		"scheduled": scheduled,
+		"completed": completed,
+		"skipped":   skipped,
+	})
+	return err
+}
+
+// ListRange returns the user's stored rollups between from and to, oldest
+// first. Days without a stored rollup are missing from the result.
+func (r *DailyRollupRepository) ListRange(
+	ctx context.Context,
+	userID uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]rollup.DailyRollup, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			daily_rollups
+		WHERE
+			user_id = @user_id
+			AND day BETWEEN @from AND @to
+		ORDER BY
+			day
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"from":    from,
+		"to":      to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[rollup.DailyRollup])
+}
+
+// DeleteFrom drops the user's rollups on and after from, so they are computed
+// again until they are next stored. A zero from drops all of them.
+func (r *DailyRollupRepository) DeleteFrom(ctx context.Context, userID uuid.UUID, from time.Time) error {
+	stmt := `
+		DELETE FROM daily_rollups
+		WHERE
+			user_id = @user_id
+			AND day >= @from
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"from":    from,
+	})
+	return err
+}
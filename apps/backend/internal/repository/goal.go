@@ -0,0 +1,458 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/goal"
+)
+
+type GoalRepository struct {
+	db DBTX
+}
+
+func NewGoalRepository(db DBTX) *GoalRepository {
+	return &GoalRepository{db: db}
+}
+
+// Create inserts a goal. A nil start date starts the goal today.
+func (r *GoalRepository) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	description *string,
+	startDate *time.Time,
+	targetDate *time.Time,
+) (*goal.Goal, error) {
+	stmt := `
+		INSERT INTO goals (user_id, name, description, start_date, target_date)
+		VALUES (@user_id, @name, @description, COALESCE(@start_date, CURRENT_DATE), @target_date)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"name":        name,
+		"description": description,
+		"start_date":  startDate,
+		"target_date": targetDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[goal.Goal])
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (r *GoalRepository) List(ctx context.Context, userID uuid.UUID) ([]goal.Goal, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			goals
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			completed_at IS NOT NULL,
+			target_date NULLS LAST,
+			created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	goals, err := pgx.CollectRows(rows, pgx.RowToStructByName[goal.Goal])
+	if err != nil {
+		return nil, err
+	}
+
+	if goals == nil {
+		return []goal.Goal{}, nil
+	}
+
+	return goals, nil
+}
+
+func (r *GoalRepository) GetByID(ctx context.Context, goalID uuid.UUID, userID uuid.UUID) (*goal.Goal, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			goals
+		WHERE
+			id = @goal_id
+			AND user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"goal_id": goalID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[goal.Goal])
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (r *GoalRepository) Update(
+	ctx context.Context,
+	goalID uuid.UUID,
+	userID uuid.UUID,
+	name *string,
+	description *string,
+	targetDate *time.Time,
+) (*goal.Goal, error) {
+	updates := []string{}
+	args := pgx.NamedArgs{
+		"goal_id": goalID,
+		"user_id": userID,
+	}
+
+	if name != nil {
+		updates = append(updates, "name = @name")
+		args["name"] = *name
+	}
+
+	if description != nil {
+		updates = append(updates, "description = @description")
+		args["description"] = *description
+	}
+
+	if targetDate != nil {
+		updates = append(updates, "target_date = @target_date")
+		args["target_date"] = *targetDate
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	stmt := `
+		UPDATE goals
+		SET ` + strings.Join(updates, ", ") + `
+		WHERE id = @goal_id
+			AND user_id = @user_id
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[goal.Goal])
+	if err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func (r *GoalRepository) Delete(ctx context.Context, goalID uuid.UUID, userID uuid.UUID) error {
+	stmt := `
+		DELETE FROM goals
+		WHERE id = @goal_id
+			AND user_id = @user_id
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"goal_id": goalID,
+		"user_id": userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetHabits replaces the habits linked to the goal. Habits that do not belong
+// to the user are ignored; the number of linked habits is returned so callers
+// can detect them.
+func (r *GoalRepository) SetHabits(
+	ctx context.Context,
+	goalID uuid.UUID,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+) (int, error) {
+	deleteStmt := `
+		DELETE FROM goal_habits
+		WHERE goal_id = @goal_id
+	`
+
+	_, err := r.db.Exec(ctx, deleteStmt, pgx.NamedArgs{
+		"goal_id": goalID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	insertStmt := `
+		INSERT INTO goal_habits (goal_id, habit_id)
+		SELECT @goal_id, habits.id
+		FROM habits
+		WHERE habits.id = ANY(@habit_ids)
+			AND habits.user_id = @user_id
+	`
+
+	tag, err := r.db.Exec(ctx, insertStmt, pgx.NamedArgs{
+		"goal_id":   goalID,
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ListLinks returns the habit links of all of the user's goals
+func (r *GoalRepository) ListLinks(ctx context.Context, userID uuid.UUID) ([]goal.Link, error) {
+	stmt := `
+		SELECT
+			goal_habits.goal_id,
+			goal_habits.habit_id
+		FROM
+			goal_habits
+		JOIN goals ON goals.id = goal_habits.goal_id
+		WHERE
+			goals.user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[goal.Link])
+}
+
+// ListOpenGoalIDsByHabit returns the goals that still have milestones to reach
+// and are linked to the habit
+func (r *GoalRepository) ListOpenGoalIDsByHabit(ctx context.Context, habitID uuid.UUID) ([]uuid.UUID, error) {
+	stmt := `
+		SELECT
+			goals.id
+		FROM
+			goals
+		JOIN goal_habits ON goal_habits.goal_id = goals.id
+		WHERE
+			goal_habits.habit_id = @habit_id
+			AND goals.completed_at IS NULL
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"habit_id": habitID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+// ReplaceMilestones makes the given milestones, in order, the goal's
+// milestones. A payload matching an existing milestone by ID, or else by kind
+// and target, updates it and keeps the time it was reached unless its kind or
+// target changed; the others are inserted and unmatched milestones deleted.
+func (r *GoalRepository) ReplaceMilestones(
+	ctx context.Context,
+	goalID uuid.UUID,
+	milestones []goal.MilestonePayload,
+) error {
+	existing, err := r.ListMilestones(ctx, []uuid.UUID{goalID})
+	if err != nil {
+		return err
+	}
+
+	matched := make([]*goal.Milestone, len(milestones))
+	claimed := make(map[uuid.UUID]bool)
+	for i, m := range milestones {
+		if m.ID == nil {
+			continue
+		}
+		for j := range existing {
+			if existing[j].ID == *m.ID && !claimed[existing[j].ID] {
+				matched[i] = &existing[j]
+				claimed[existing[j].ID] = true
+				break
+			}
+		}
+	}
+	for i, m := range milestones {
+		if matched[i] != nil || m.ID != nil {
+			continue
+		}
+		for j := range existing {
+			if existing[j].Kind == m.Kind && existing[j].Target == m.Target && !claimed[existing[j].ID] {
+				matched[i] = &existing[j]
+				claimed[existing[j].ID] = true
+				break
+			}
+		}
+	}
+
+	kept := make([]uuid.UUID, 0, len(claimed))
+	for id := range claimed {
+		kept = append(kept, id)
+	}
+
+	deleteStmt := `
+		DELETE FROM goal_milestones
+		WHERE goal_id = @goal_id
+			AND NOT (id = ANY(@kept_ids))
+	`
+
+	_, err = r.db.Exec(ctx, deleteStmt, pgx.NamedArgs{
+		"goal_id":  goalID,
+		"kept_ids": kept,
+	})
+	if err != nil {
+		return err
+	}
+
+	updateStmt := `
+		UPDATE goal_milestones
+		SET name = @name,
+			kind = @kind,
+			target = @target,
+			unit = @unit,
+			position = @position,
+			completed_at = CASE WHEN kind = @kind AND target = @target THEN completed_at END,
+			updated_at = NOW()
+		WHERE id = @milestone_id
+	`
+
+	insertStmt := `
+		INSERT INTO goal_milestones (goal_id, name, kind, target, unit, position)
+		VALUES (@goal_id, @name, @kind, @target, @unit, @position)
+	`
+
+	for i, m := range milestones {
+		args := pgx.NamedArgs{
+			"name":     m.Name,
+			"kind":     m.Kind,
+			"target":   m.Target,
+			"unit":     m.Unit,
+			"position": i,
+		}
+
+		stmt := insertStmt
+		if matched[i] != nil {
+			stmt = updateStmt
+			args["milestone_id"] = matched[i].ID
+		} else {
+			args["goal_id"] = goalID
+		}
+
+		if _, err := r.db.Exec(ctx, stmt, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListMilestones returns the milestones of the given goals, ordered by position
+func (r *GoalRepository) ListMilestones(ctx context.Context, goalIDs []uuid.UUID) ([]goal.Milestone, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			goal_milestones
+		WHERE
+			goal_id = ANY(@goal_ids)
+		ORDER BY
+			goal_id,
+			position
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"goal_ids": goalIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[goal.Milestone])
+}
+
+// CompleteMilestone records when a milestone was reached. Milestones that
+// were already reached keep their original time.
+func (r *GoalRepository) CompleteMilestone(ctx context.Context, milestoneID uuid.UUID) error {
+	stmt := `
+		UPDATE goal_milestones
+		SET completed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = @milestone_id
+			AND completed_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"milestone_id": milestoneID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Reopen clears the goal's completion after an edit left a milestone to reach
+func (r *GoalRepository) Reopen(ctx context.Context, goalID uuid.UUID) error {
+	stmt := `
+		UPDATE goals
+		SET completed_at = NULL,
+			updated_at = NOW()
+		WHERE id = @goal_id
+			AND completed_at IS NOT NULL
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"goal_id": goalID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Complete records when every milestone of the goal was reached
+func (r *GoalRepository) Complete(ctx context.Context, goalID uuid.UUID) error {
+	stmt := `
+		UPDATE goals
+		SET completed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = @goal_id
+			AND completed_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"goal_id": goalID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -4,36 +4,44 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/reche13/habitum/internal/model/habit"
 )
 
 
 type HabitRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewHabitRepository(db *pgxpool.Pool) *HabitRepository {
+func NewHabitRepository(db DBTX) *HabitRepository {
 	return &HabitRepository{db: db}
 }
 
-func (r *HabitRepository) Create(ctx context.Context, userID uuid.UUID, payload *habit.CreateHabitPayload) (*habit.Habit, error) {
+// Create inserts a habit. A non-nil window makes it a time-boxed challenge.
+func (r *HabitRepository) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *habit.CreateHabitPayload,
+	window *habit.ChallengeWindow,
+) (*habit.Habit, error) {
 	stmt := `
 		INSERT INTO habits (
 			user_id, name, description, icon, color,
-			category, frequency, times_per_week
+			category, frequency, times_per_week, difficulty,
+			start_date, end_date, stack_after_id
 		)
 		VALUES (
 			@user_id, @name, @description, @icon, @color,
-			@category, @frequency, @times_per_week
+			@category, @frequency, @times_per_week, @difficulty,
+			@start_date, @end_date, @stack_after_id
 		)
 		RETURNING *
 	`
 
-	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+	args := pgx.NamedArgs{
 		"user_id":      userID,
 		"name":         payload.Name,
 		"description":  payload.Description,
@@ -42,7 +50,20 @@ func (r *HabitRepository) Create(ctx context.Context, userID uuid.UUID, payload
 		"category":     payload.Category,
 		"frequency":    payload.Frequency,
 		"times_per_week": payload.TimesPerWeek,
-	})
+		"difficulty":   habit.Medium,
+		"start_date":   nil,
+		"end_date":     nil,
+		"stack_after_id": payload.StackAfterID,
+	}
+	if payload.Difficulty != "" {
+		args["difficulty"] = payload.Difficulty
+	}
+	if window != nil {
+		args["start_date"] = window.StartDate
+		args["end_date"] = window.EndDate
+	}
+
+	rows, err := r.db.Query(ctx, stmt, args)
 
 	if err != nil {
 		return nil, err
@@ -198,7 +219,15 @@ func (r *HabitRepository) GetByID(ctx context.Context, habitID uuid.UUID, userID
 	return &h, nil
 }
 
-func (r *HabitRepository) Update(ctx context.Context, habitID uuid.UUID, userID uuid.UUID, payload *habit.UpdateHabitPayload) (*habit.Habit, error) {
+// Update applies the set fields of payload. A non-nil window replaces the
+// challenge dates.
+func (r *HabitRepository) Update(
+	ctx context.Context,
+	habitID uuid.UUID,
+	userID uuid.UUID,
+	payload *habit.UpdateHabitPayload,
+	window *habit.ChallengeWindow,
+) (*habit.Habit, error) {
 	// Build dynamic update query
 	updates := []string{}
 	args := pgx.NamedArgs{
@@ -241,6 +270,26 @@ func (r *HabitRepository) Update(ctx context.Context, habitID uuid.UUID, userID
 		args["times_per_week"] = *payload.TimesPerWeek
 	}
 
+	if payload.Difficulty != nil {
+		updates = append(updates, "difficulty = @difficulty")
+		args["difficulty"] = *payload.Difficulty
+	}
+
+	if payload.StackAfterID != nil {
+		updates = append(updates, "stack_after_id = @stack_after_id")
+		if *payload.StackAfterID == uuid.Nil {
+			args["stack_after_id"] = nil
+		} else {
+			args["stack_after_id"] = *payload.StackAfterID
+		}
+	}
+
+	if window != nil {
+		updates = append(updates, "start_date = @start_date", "end_date = @end_date")
+		args["start_date"] = window.StartDate
+		args["end_date"] = window.EndDate
+	}
+
 	if len(updates) == 0 {
 		// No updates, just return the habit
 		return r.GetByID(ctx, habitID, userID)
@@ -296,31 +345,86 @@ func (r *HabitRepository) Delete(ctx context.Context, habitID uuid.UUID, userID
 	return nil
 }
 
+// UpdateStreaks stores the habit's streaks together with the day of its
+// latest completion, which the current streak ends on
 func (r *HabitRepository) UpdateStreaks(
 	ctx context.Context,
 	habitID uuid.UUID,
 	userID uuid.UUID,
 	currentStreak int,
 	longestStreak int,
+	lastCompletedOn *time.Time,
 ) error {
 	stmt := `
 		UPDATE habits
 		SET current_streak = @current_streak,
 			longest_streak = @longest_streak,
+			last_completed_on = @last_completed_on,
 			updated_at = NOW()
 		WHERE id = @habit_id
 			AND user_id = @user_id
 	`
 
 	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
-		"habit_id":      habitID,
-		"user_id":       userID,
-		"current_streak": currentStreak,
-		"longest_streak": longestStreak,
+		"habit_id":          habitID,
+		"user_id":           userID,
+		"current_streak":    currentStreak,
+		"longest_streak":    longestStreak,
+		"last_completed_on": lastCompletedOn,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByIDs returns the user's habits with the given IDs, in no particular order
+func (r *HabitRepository) GetByIDs(ctx context.Context, userID uuid.UUID, habitIDs []uuid.UUID) ([]habit.Habit, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			habits
+		WHERE
+			user_id = @user_id
+			AND id = ANY(@habit_ids)
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[habit.Habit])
+}
+
+// ArchiveEndedChallenges archives the user's challenges whose end date is
+// before today. They are archived as of the start of the day after the
+// challenge ended, in the user's time zone and with the user's day starting
+// dayEndsAt hours after midnight.
+func (r *HabitRepository) ArchiveEndedChallenges(ctx context.Context, userID uuid.UUID, today time.Time, timezone string, dayEndsAt int) error {
+	stmt := `
+		UPDATE habits
+		SET archived_at = ((end_date + 1) + make_interval(hours => @day_ends_at)) AT TIME ZONE @timezone,
+			updated_at = NOW()
+		WHERE user_id = @user_id
+			AND archived_at IS NULL
+			AND end_date < @today
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"today":       today,
+		"timezone":    timezone,
+		"day_ends_at": dayEndsAt,
 	})
 	if err != nil {
 		return err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
@@ -6,15 +6,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/reche13/habitum/internal/model/habitlog"
 )
 
 type HabitLogRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewHabitLogRepository(db *pgxpool.Pool) * HabitLogRepository {
+func NewHabitLogRepository(db DBTX) * HabitLogRepository {
 	return &HabitLogRepository{db:db}
 }
 
@@ -25,11 +24,17 @@ func (r *HabitLogRepository) Create(
 ) (*habitlog.HabitLog, error) {
 	stmt := `
 		INSERT INTO 
-		habit_logs (user_id, habit_id, log_date, completed) 
-		VALUES (@user_id, @habit_id, @log_date, @completed) 
+		habit_logs (user_id, habit_id, log_date, status, value, completed_at, completed_timezone, note) 
+		VALUES (@user_id, @habit_id, @log_date, @status, @value, @completed_at, @completed_timezone, @note) 
 		ON CONFLICT (habit_id, log_date) 
 		DO UPDATE SET
-		completed = EXCLUDED.completed, updated_at = NOW()
+		status = EXCLUDED.status, value = EXCLUDED.value,
+		note = COALESCE(EXCLUDED.note, habit_logs.note),
+		completed_at = CASE WHEN EXCLUDED.status = 'completed'
+			THEN COALESCE(EXCLUDED.completed_at, habit_logs.completed_at) END,
+		completed_timezone = CASE WHEN EXCLUDED.status = 'completed'
+			THEN COALESCE(EXCLUDED.completed_timezone, habit_logs.completed_timezone) END,
+		updated_at = NOW()
 		RETURNING *
 	`
 
@@ -37,7 +42,11 @@ func (r *HabitLogRepository) Create(
 		"user_id": userID,
 		"habit_id": payload.HabitID,
 		"log_date": payload.LogDate,
-		"completed": payload.Completed,
+		"status": payload.ResolvedStatus(),
+		"value": payload.Value,
+		"completed_at": payload.CompletedAt,
+		"completed_timezone": payload.CompletedTimezone,
+		"note": payload.Note,
 	})
 	if err != nil {
 		return nil, err
@@ -138,6 +147,38 @@ func (r *HabitLogRepository) GetByHabit(
 	return pgx.CollectRows(rows, pgx.RowToStructByName[habitlog.HabitLog])
 }
 
+// GetByHabits returns the logs of several habits in one query, ordered by
+// date
+func (r *HabitLogRepository) GetByHabits(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]habitlog.HabitLog, error) {
+	stmt := `
+		SELECT *
+		FROM habit_logs
+		WHERE user_id = @user_id
+		AND habit_id = ANY(@habit_ids)
+		AND log_date BETWEEN @from AND @to
+		ORDER BY log_date
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+		"from":      from,
+		"to":        to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[habitlog.HabitLog])
+}
+
 func (r *HabitLogRepository) DeleteByHabitAndDate(
 	ctx context.Context,
 	userID uuid.UUID,
@@ -223,3 +264,36 @@ func (r *HabitLogRepository) GetByHabitWithLimit(
 
 	return logs, total, nil
 }
+
+// CountCompletedByCategory returns the user's number of completions, keyed by
+// the category of the habit
+func (r *HabitLogRepository) CountCompletedByCategory(ctx context.Context, userID uuid.UUID) (map[string]int, error) {
+	stmt := `
+		SELECT h.category, COUNT(*)
+		FROM habit_logs l
+		JOIN habits h ON h.id = l.habit_id
+		WHERE l.user_id = @user_id
+			AND l.completed = true
+		GROUP BY h.category
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var category string
+		var count int
+		if err := rows.Scan(&category, &count); err != nil {
+			return nil, err
+		}
+		counts[category] = count
+	}
+
+	return counts, rows.Err()
+}
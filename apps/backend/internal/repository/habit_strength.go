@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/habit"
+)
+
+type HabitStrengthRepository struct {
+	db DBTX
+}
+
+func NewHabitStrengthRepository(db DBTX) *HabitStrengthRepository {
+	return &HabitStrengthRepository{db: db}
+}
+
+// Upsert stores the given scores, replacing the score of days that already
+// have one
+func (r *HabitStrengthRepository) Upsert(ctx context.Context, userID uuid.UUID, strengths []habit.Strength) error {
+	if len(strengths) == 0 {
+		return nil
+	}
+
+	habitIDs := make([]uuid.UUID, len(strengths))
+	days := make([]time.Time, len(strengths))
+	scores := make([]float64, len(strengths))
+	for i, st := range strengths {
+		habitIDs[i] = st.HabitID
+		days[i] = st.Day
+		scores[i] = st.Score
+	}
+
+	stmt := `
+		INSERT INTO habit_strengths (habit_id, user_id, day, score)
+		SELECT t.habit_id, @user_id, t.day, t.score
+		FROM UNNEST(@habit_ids::UUID[], @days::DATE[], @scores::DOUBLE PRECISION[])
+			AS t(habit_id, day, score)
+		ON CONFLICT (habit_id, day)
+		DO UPDATE SET
+			score = EXCLUDED.score,
+			updated_at = NOW()
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+		"days":      days,
+		"scores":    scores,
+	})
+	return err
+}
+
+// ListLatest returns the most recent stored score of each of the given habits
+// that has one
+func (r *HabitStrengthRepository) ListLatest(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+) ([]habit.Strength, error) {
+	stmt := `
+		SELECT DISTINCT ON (habit_id)
+			*
+		FROM
+			habit_strengths
+		WHERE
+			user_id = @user_id
+			AND habit_id = ANY(@habit_ids)
+		ORDER BY
+			habit_id,
+			day DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[habit.Strength])
+}
+
+// ListRange returns the stored scores of the given habits between from and
+// to, oldest first
+func (r *HabitStrengthRepository) ListRange(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+	from time.Time,
+	to time.Time,
+) ([]habit.Strength, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			habit_strengths
+		WHERE
+			user_id = @user_id
+			AND habit_id = ANY(@habit_ids)
+			AND day BETWEEN @from AND @to
+		ORDER BY
+			day
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_ids": habitIDs,
+		"from":      from,
+		"to":        to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[habit.Strength])
+}
+
+// DeleteFrom drops the habit's scores on and after from, so they are rebuilt
+// the next time they are read. A zero from drops all of them.
+func (r *HabitStrengthRepository) DeleteFrom(ctx context.Context, habitID uuid.UUID, from time.Time) error {
+	stmt := `
+		DELETE FROM habit_strengths
+		WHERE
+			habit_id = @habit_id
+			AND day >= @from
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"habit_id": habitID,
+		"from":     from,
+	})
+	return err
+}
+
+// DeleteFromForUser drops the scores of all the user's habits on and after
+// from. A zero from drops all of them.
+func (r *HabitStrengthRepository) DeleteFromForUser(ctx context.Context, userID uuid.UUID, from time.Time) error {
+	stmt := `
+		DELETE FROM habit_strengths
+		WHERE
+			user_id = @user_id
+			AND day >= @from
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"from":    from,
+	})
+	return err
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habittemplate"
+)
+
+type HabitTemplateRepository struct {
+	db DBTX
+}
+
+func NewHabitTemplateRepository(db DBTX) *HabitTemplateRepository {
+	return &HabitTemplateRepository{db: db}
+}
+
+// CreateFromHabit stores a copy of the habit's settings as a private template
+func (r *HabitTemplateRepository) CreateFromHabit(
+	ctx context.Context,
+	userID uuid.UUID,
+	h *habit.Habit,
+	name string,
+) (*habittemplate.HabitTemplate, error) {
+	stmt := `
+		INSERT INTO habit_templates (
+			user_id, name, description, icon, color,
+			category, frequency, times_per_week
+		)
+		VALUES (
+			@user_id, @name, @description, @icon, @color,
+			@category, @frequency, @times_per_week
+		)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":        userID,
+		"name":           name,
+		"description":    h.Description,
+		"icon":           h.Icon,
+		"color":          h.Color,
+		"category":       h.Category,
+		"frequency":      h.Frequency,
+		"times_per_week": h.TimesPerWeek,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[habittemplate.HabitTemplate])
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *HabitTemplateRepository) List(ctx context.Context, userID uuid.UUID) ([]habittemplate.HabitTemplate, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			habit_templates
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates, err := pgx.CollectRows(rows, pgx.RowToStructByName[habittemplate.HabitTemplate])
+	if err != nil {
+		return nil, err
+	}
+
+	if templates == nil {
+		return []habittemplate.HabitTemplate{}, nil
+	}
+
+	return templates, nil
+}
+
+func (r *HabitTemplateRepository) GetByID(ctx context.Context, templateID uuid.UUID, userID uuid.UUID) (*habittemplate.HabitTemplate, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			habit_templates
+		WHERE
+			id = @template_id
+			AND user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"template_id": templateID,
+		"user_id":     userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	t, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[habittemplate.HabitTemplate])
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *HabitTemplateRepository) Delete(ctx context.Context, templateID uuid.UUID, userID uuid.UUID) error {
+	stmt := `
+		DELETE FROM habit_templates
+		WHERE id = @template_id
+			AND user_id = @user_id
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"template_id": templateID,
+		"user_id":     userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
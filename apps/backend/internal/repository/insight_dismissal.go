@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/insight"
+)
+
+type InsightDismissalRepository struct {
+	db DBTX
+}
+
+func NewInsightDismissalRepository(db DBTX) *InsightDismissalRepository {
+	return &InsightDismissalRepository{db: db}
+}
+
+// Upsert dismisses the insight now, snoozed until snoozedUntil when set,
+// replacing any earlier dismissal of it
+func (r *InsightDismissalRepository) Upsert(
+	ctx context.Context,
+	userID uuid.UUID,
+	insightID string,
+	snoozedUntil *time.Time,
+) (*insight.Dismissal, error) {
+	stmt := `
+		INSERT INTO insight_dismissals (user_id, insight_id, snoozed_until)
+		VALUES (@user_id, @insight_id, @snoozed_until)
+		ON CONFLICT (user_id, insight_id)
+		DO UPDATE SET
+			dismissed_at = NOW(),
+			snoozed_until = EXCLUDED.snoozed_until
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":       userID,
+		"insight_id":    insightID,
+		"snoozed_until": snoozedUntil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	d, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[insight.Dismissal])
+	if err != nil {
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// List returns every insight the user dismissed or snoozed
+func (r *InsightDismissalRepository) List(ctx context.Context, userID uuid.UUID) ([]insight.Dismissal, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			insight_dismissals
+		WHERE
+			user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[insight.Dismissal])
+}
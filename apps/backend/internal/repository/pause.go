@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/pause"
+)
+
+type PauseRepository struct {
+	db DBTX
+}
+
+func NewPauseRepository(db DBTX) *PauseRepository {
+	return &PauseRepository{db: db}
+}
+
+func (r *PauseRepository) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID *uuid.UUID,
+	startDate time.Time,
+	endDate *time.Time,
+	reason *string,
+) (*pause.Pause, error) {
+	stmt := `
+		INSERT INTO pauses (user_id, habit_id, start_date, end_date, reason)
+		VALUES (@user_id, @habit_id, @start_date, @end_date, @reason)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":    userID,
+		"habit_id":   habitID,
+		"start_date": startDate,
+		"end_date":   endDate,
+		"reason":     reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[pause.Pause])
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// List returns every pause of the user, both account-wide and per-habit
+func (r *PauseRepository) List(ctx context.Context, userID uuid.UUID) ([]pause.Pause, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			pauses
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			start_date DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pauses, err := pgx.CollectRows(rows, pgx.RowToStructByName[pause.Pause])
+	if err != nil {
+		return nil, err
+	}
+
+	if pauses == nil {
+		return []pause.Pause{}, nil
+	}
+
+	return pauses, nil
+}
+
+func (r *PauseRepository) GetByID(ctx context.Context, pauseID uuid.UUID, userID uuid.UUID) (*pause.Pause, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			pauses
+		WHERE
+			id = @pause_id
+			AND user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"pause_id": pauseID,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[pause.Pause])
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *PauseRepository) Update(
+	ctx context.Context,
+	pauseID uuid.UUID,
+	userID uuid.UUID,
+	endDate *time.Time,
+	reason *string,
+) (*pause.Pause, error) {
+	updates := []string{}
+	args := pgx.NamedArgs{
+		"pause_id": pauseID,
+		"user_id":  userID,
+	}
+
+	if endDate != nil {
+		updates = append(updates, "end_date = @end_date")
+		args["end_date"] = *endDate
+	}
+
+	if reason != nil {
+		updates = append(updates, "reason = @reason")
+		args["reason"] = *reason
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	stmt := `
+		UPDATE pauses
+		SET ` + strings.Join(updates, ", ") + `
+		WHERE id = @pause_id
+			AND user_id = @user_id
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[pause.Pause])
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func (r *PauseRepository) Delete(ctx context.Context, pauseID uuid.UUID, userID uuid.UUID) error {
+	stmt := `
+		DELETE FROM pauses
+		WHERE id = @pause_id
+			AND user_id = @user_id
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"pause_id": pauseID,
+		"user_id":  userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
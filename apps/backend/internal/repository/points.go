@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/points"
+)
+
+type PointsRepository struct {
+	db DBTX
+}
+
+func NewPointsRepository(db DBTX) *PointsRepository {
+	return &PointsRepository{db: db}
+}
+
+// AddEntry appends an entry to the user's ledger
+func (r *PointsRepository) AddEntry(ctx context.Context, entry *points.LedgerEntry) (*points.LedgerEntry, error) {
+	stmt := `
+		INSERT INTO points_ledger (
+			user_id, source, points, xp, description,
+			habit_id, log_date, achievement_key, reward_id
+		)
+		VALUES (
+			@user_id, @source, @points, @xp, @description,
+			@habit_id, @log_date, @achievement_key, @reward_id
+		)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":         entry.UserID,
+		"source":          entry.Source,
+		"points":          entry.Points,
+		"xp":              entry.XP,
+		"description":     entry.Description,
+		"habit_id":        entry.HabitID,
+		"log_date":        entry.LogDate,
+		"achievement_key": entry.AchievementKey,
+		"reward_id":       entry.RewardID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	e, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.LedgerEntry])
+	if err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// Balance sums the user's ledger
+func (r *PointsRepository) Balance(ctx context.Context, userID uuid.UUID) (*points.Balance, error) {
+	stmt := `
+		SELECT
+			COALESCE(SUM(points), 0) AS points,
+			COALESCE(SUM(xp), 0) AS xp
+		FROM
+			points_ledger
+		WHERE
+			user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.Balance])
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// CompletionBalance sums the completion entries of a habit's log for a day,
+// which is zero when the day has earned nothing or was reversed
+func (r *PointsRepository) CompletionBalance(
+	ctx context.Context,
+	habitID uuid.UUID,
+	logDate time.Time,
+) (*points.Balance, error) {
+	stmt := `
+		SELECT
+			COALESCE(SUM(points), 0) AS points,
+			COALESCE(SUM(xp), 0) AS xp
+		FROM
+			points_ledger
+		WHERE
+			habit_id = @habit_id
+			AND log_date = @log_date
+			AND source = 'completion'
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"habit_id": habitID,
+		"log_date": logDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	b, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.Balance])
+	if err != nil {
+		return nil, err
+	}
+
+	return &b, nil
+}
+
+// ListEntries returns a page of the user's ledger, newest first, and the
+// total number of entries
+func (r *PointsRepository) ListEntries(
+	ctx context.Context,
+	userID uuid.UUID,
+	limit int,
+	offset int,
+) ([]points.LedgerEntry, int, error) {
+	countStmt := `
+		SELECT COUNT(*)
+		FROM points_ledger
+		WHERE user_id = @user_id
+	`
+
+	var total int
+	err := r.db.QueryRow(ctx, countStmt, pgx.NamedArgs{
+		"user_id": userID,
+	}).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	stmt := `
+		SELECT
+			*
+		FROM
+			points_ledger
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			created_at DESC
+		LIMIT @limit
+		OFFSET @offset
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+		"limit":   limit,
+		"offset":  offset,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries, err := pgx.CollectRows(rows, pgx.RowToStructByName[points.LedgerEntry])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// LockUser serializes changes to the user's balance until the surrounding
+// transaction ends, so concurrent spending cannot overdraw it
+func (r *PointsRepository) LockUser(ctx context.Context, userID uuid.UUID) error {
+	stmt := `
+		SELECT pg_advisory_xact_lock(hashtext('points:' || @user_id::TEXT))
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	return err
+}
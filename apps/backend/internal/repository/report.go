@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/report"
+)
+
+type ReportRepository struct {
+	db DBTX
+}
+
+func NewReportRepository(db DBTX) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+// Get returns the stored report of the period starting at periodStart
+func (r *ReportRepository) Get(
+	ctx context.Context,
+	userID uuid.UUID,
+	period report.Period,
+	periodStart time.Time,
+) (*report.Report, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			reports
+		WHERE
+			user_id = @user_id
+			AND period = @period
+			AND period_start = @period_start
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":      userID,
+		"period":       period,
+		"period_start": periodStart,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rp, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[report.Report])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rp, nil
+}
+
+// Create stores a report. When one was stored for the same period in the
+// meantime, that one is kept and returned.
+func (r *ReportRepository) Create(ctx context.Context, rp *report.Report) (*report.Report, error) {
+	stmt := `
+		INSERT INTO reports (user_id, period, period_start, period_end, data, generated_at)
+		VALUES (@user_id, @period, @period_start, @period_end, @data, @generated_at)
+		ON CONFLICT (user_id, period, period_start)
+		DO UPDATE SET user_id = reports.user_id
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":      rp.UserID,
+		"period":       rp.Period,
+		"period_start": rp.PeriodStart,
+		"period_end":   rp.PeriodEnd,
+		"data":         rp.Data,
+		"generated_at": rp.GeneratedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[report.Report])
+	if err != nil {
+		return nil, err
+	}
+
+	return &stored, nil
+}
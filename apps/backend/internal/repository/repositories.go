@@ -1,18 +1,59 @@
 package repository
 
-import "github.com/jackc/pgx/v5/pgxpool"
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
 
 
 type Repositories struct{
+	db DBTX
+
 	User *UserRepository
 	Habit *HabitRepository
 	HabitLog *HabitLogRepository
+	HabitTemplate *HabitTemplateRepository
+	Routine *RoutineRepository
+	Pause *PauseRepository
+	StreakFreeze *StreakFreezeRepository
+	Goal *GoalRepository
+	DailyRollup *DailyRollupRepository
+	HabitStrength *HabitStrengthRepository
+	InsightDismissal *InsightDismissalRepository
+	Achievement *AchievementRepository
+	Points *PointsRepository
+	Reward *RewardRepository
+	Report *ReportRepository
 }
 
-func NewRepositories(db *pgxpool.Pool) *Repositories {
+func NewRepositories(db DBTX) *Repositories {
 	return &Repositories{
+		db: db,
+
 		User: NewUserRepository(db),
 		Habit: NewHabitRepository(db),
 		HabitLog: NewHabitLogRepository(db),
+		HabitTemplate: NewHabitTemplateRepository(db),
+		Routine: NewRoutineRepository(db),
+		Pause: NewPauseRepository(db),
+		StreakFreeze: NewStreakFreezeRepository(db),
+		Goal: NewGoalRepository(db),
+		DailyRollup: NewDailyRollupRepository(db),
+		HabitStrength: NewHabitStrengthRepository(db),
+		InsightDismissal: NewInsightDismissalRepository(db),
+		Achievement: NewAchievementRepository(db),
+		Points: NewPointsRepository(db),
+		Reward: NewRewardRepository(db),
+		Report: NewReportRepository(db),
 	}
-}
\ No newline at end of file
+}
+
+// WithTx runs fn inside a transaction. The repositories passed to fn are bound
+// to the transaction, which is committed if fn returns nil and rolled back
+// otherwise. Calling WithTx on transaction-bound repositories uses a savepoint.
+func (r *Repositories) WithTx(ctx context.Context, fn func(tx *Repositories) error) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		return fn(NewRepositories(tx))
+	})
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/points"
+)
+
+type RewardRepository struct {
+	db DBTX
+}
+
+func NewRewardRepository(db DBTX) *RewardRepository {
+	return &RewardRepository{db: db}
+}
+
+func (r *RewardRepository) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *points.CreateRewardPayload,
+) (*points.Reward, error) {
+	stmt := `
+		INSERT INTO rewards (user_id, name, description, cost)
+		VALUES (@user_id, @name, @description, @cost)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"name":        payload.Name,
+		"description": payload.Description,
+		"cost":        payload.Cost,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rw, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.Reward])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rw, nil
+}
+
+func (r *RewardRepository) List(ctx context.Context, userID uuid.UUID) ([]points.Reward, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			rewards
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			cost, name
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[points.Reward])
+}
+
+func (r *RewardRepository) GetByID(ctx context.Context, rewardID uuid.UUID, userID uuid.UUID) (*points.Reward, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			rewards
+		WHERE
+			id = @reward_id
+			AND user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"reward_id": rewardID,
+		"user_id":   userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rw, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.Reward])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rw, nil
+}
+
+func (r *RewardRepository) Update(
+	ctx context.Context,
+	rewardID uuid.UUID,
+	userID uuid.UUID,
+	payload *points.UpdateRewardPayload,
+) (*points.Reward, error) {
+	updates := []string{}
+	args := pgx.NamedArgs{
+		"reward_id": rewardID,
+		"user_id":   userID,
+	}
+
+	if payload.Name != nil {
+		updates = append(updates, "name = @name")
+		args["name"] = *payload.Name
+	}
+
+	if payload.Description != nil {
+		updates = append(updates, "description = @description")
+		args["description"] = *payload.Description
+	}
+
+	if payload.Cost != nil {
+		updates = append(updates, "cost = @cost")
+		args["cost"] = *payload.Cost
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	stmt := `
+		UPDATE rewards
+		SET ` + strings.Join(updates, ", ") + `
+		WHERE id = @reward_id
+			AND user_id = @user_id
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rw, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[points.Reward])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rw, nil
+}
+
+func (r *RewardRepository) Delete(ctx context.Context, rewardID uuid.UUID, userID uuid.UUID) error {
+	stmt := `
+		DELETE FROM rewards
+		WHERE id = @reward_id
+			AND user_id = @user_id
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"reward_id": rewardID,
+		"user_id":   userID,
+	})
+	return err
+}
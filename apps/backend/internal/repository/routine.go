@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/routine"
+)
+
+type RoutineRepository struct {
+	db DBTX
+}
+
+func NewRoutineRepository(db DBTX) *RoutineRepository {
+	return &RoutineRepository{db: db}
+}
+
+func (r *RoutineRepository) Create(
+	ctx context.Context,
+	userID uuid.UUID,
+	name string,
+	timeOfDay routine.TimeOfDay,
+) (*routine.Routine, error) {
+	stmt := `
+		INSERT INTO routines (user_id, name, time_of_day)
+		VALUES (@user_id, @name, @time_of_day)
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id":     userID,
+		"name":        name,
+		"time_of_day": timeOfDay,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[routine.Routine])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rt, nil
+}
+
+func (r *RoutineRepository) List(ctx context.Context, userID uuid.UUID) ([]routine.Routine, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			routines
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			CASE time_of_day
+				WHEN 'morning' THEN 0
+				WHEN 'afternoon' THEN 1
+				WHEN 'evening' THEN 2
+				ELSE 3
+			END,
+			created_at
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	routines, err := pgx.CollectRows(rows, pgx.RowToStructByName[routine.Routine])
+	if err != nil {
+		return nil, err
+	}
+
+	if routines == nil {
+		return []routine.Routine{}, nil
+	}
+
+	return routines, nil
+}
+
+func (r *RoutineRepository) GetByID(ctx context.Context, routineID uuid.UUID, userID uuid.UUID) (*routine.Routine, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			routines
+		WHERE
+			id = @routine_id
+			AND user_id = @user_id
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"routine_id": routineID,
+		"user_id":    userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[routine.Routine])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rt, nil
+}
+
+func (r *RoutineRepository) Update(
+	ctx context.Context,
+	routineID uuid.UUID,
+	userID uuid.UUID,
+	payload *routine.UpdateRoutinePayload,
+) (*routine.Routine, error) {
+	updates := []string{}
+	args := pgx.NamedArgs{
+		"routine_id": routineID,
+		"user_id":    userID,
+	}
+
+	if payload.Name != nil {
+		updates = append(updates, "name = @name")
+		args["name"] = *payload.Name
+	}
+
+	if payload.TimeOfDay != nil {
+		updates = append(updates, "time_of_day = @time_of_day")
+		args["time_of_day"] = *payload.TimeOfDay
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+
+	stmt := `
+		UPDATE routines
+		SET ` + strings.Join(updates, ", ") + `
+		WHERE id = @routine_id
+			AND user_id = @user_id
+		RETURNING *
+	`
+
+	rows, err := r.db.Query(ctx, stmt, args)
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[routine.Routine])
+	if err != nil {
+		return nil, err
+	}
+
+	return &rt, nil
+}
+
+func (r *RoutineRepository) Delete(ctx context.Context, routineID uuid.UUID, userID uuid.UUID) error {
+	stmt := `
+		DELETE FROM routines
+		WHERE id = @routine_id
+			AND user_id = @user_id
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"routine_id": routineID,
+		"user_id":    userID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetHabits replaces the routine's members, keeping the order of habitIDs.
+// Habits that do not belong to the user are ignored; the number of inserted
+// members is returned so callers can detect them.
+func (r *RoutineRepository) SetHabits(
+	ctx context.Context,
+	routineID uuid.UUID,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+) (int, error) {
+	deleteStmt := `
+		DELETE FROM routine_habits
+		WHERE routine_id = @routine_id
+	`
+
+	_, err := r.db.Exec(ctx, deleteStmt, pgx.NamedArgs{
+		"routine_id": routineID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	insertStmt := `
+		INSERT INTO routine_habits (routine_id, habit_id, position)
+		SELECT @routine_id, members.habit_id, members.position - 1
+		FROM unnest(@habit_ids::uuid[]) WITH ORDINALITY AS members(habit_id, position)
+		JOIN habits ON habits.id = members.habit_id
+		WHERE habits.user_id = @user_id
+		ON CONFLICT (routine_id, habit_id) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, insertStmt, pgx.NamedArgs{
+		"routine_id": routineID,
+		"user_id":    userID,
+		"habit_ids":  habitIDs,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ListMembers returns the members of all of the user's routines, ordered by position
+func (r *RoutineRepository) ListMembers(ctx context.Context, userID uuid.UUID) ([]routine.Member, error) {
+	stmt := `
+		SELECT
+			routine_habits.routine_id,
+			routine_habits.habit_id,
+			routine_habits.position
+		FROM
+			routine_habits
+		JOIN routines ON routines.id = routine_habits.routine_id
+		WHERE
+			routines.user_id = @user_id
+		ORDER BY
+			routine_habits.routine_id,
+			routine_habits.position
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[routine.Member])
+}
+
+// ListMembersByRoutine returns the members of a single routine, ordered by position
+func (r *RoutineRepository) ListMembersByRoutine(ctx context.Context, routineID uuid.UUID) ([]routine.Member, error) {
+	stmt := `
+		SELECT
+			routine_id,
+			habit_id,
+			position
+		FROM
+			routine_habits
+		WHERE
+			routine_id = @routine_id
+		ORDER BY
+			position
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"routine_id": routineID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowToStructByName[routine.Member])
+}
@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/model/streakfreeze"
+)
+
+type StreakFreezeRepository struct {
+	db DBTX
+}
+
+func NewStreakFreezeRepository(db DBTX) *StreakFreezeRepository {
+	return &StreakFreezeRepository{db: db}
+}
+
+// Earn adds a freeze to the user's inventory. A habit earns at most one freeze
+// per day, so it returns false when the freeze was already earned.
+func (r *StreakFreezeRepository) Earn(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	earnedOn time.Time,
+	streak int,
+) (bool, error) {
+	stmt := `
+		INSERT INTO streak_freezes (user_id, habit_id, earned_on, streak)
+		VALUES (@user_id, @habit_id, @earned_on, @streak)
+		ON CONFLICT (habit_id, earned_on) DO NOTHING
+	`
+
+	tag, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":   userID,
+		"habit_id":  habitID,
+		"earned_on": earnedOn,
+		"streak":    streak,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListEarned returns every freeze the user has earned, most recent first
+func (r *StreakFreezeRepository) ListEarned(ctx context.Context, userID uuid.UUID) ([]streakfreeze.StreakFreeze, error) {
+	stmt := `
+		SELECT
+			*
+		FROM
+			streak_freezes
+		WHERE
+			user_id = @user_id
+		ORDER BY
+			earned_on DESC,
+			created_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	freezes, err := pgx.CollectRows(rows, pgx.RowToStructByName[streakfreeze.StreakFreeze])
+	if err != nil {
+		return nil, err
+	}
+
+	if freezes == nil {
+		return []streakfreeze.StreakFreeze{}, nil
+	}
+
+	return freezes, nil
+}
+
+// ListUsage returns every day covered by a freeze, most recent first
+func (r *StreakFreezeRepository) ListUsage(ctx context.Context, userID uuid.UUID) ([]streakfreeze.Usage, error) {
+	stmt := `
+		SELECT
+			sf.used_habit_id AS habit_id,
+			COALESCE(h.name, sf.used_habit_name, '') AS habit_name,
+			sf.used_on AS log_date,
+			sf.used_at
+		FROM
+			streak_freezes sf
+			LEFT JOIN habits h ON h.id = sf.used_habit_id
+		WHERE
+			sf.user_id = @user_id
+			AND sf.used_at IS NOT NULL
+		ORDER BY
+			sf.used_on DESC,
+			sf.used_at DESC
+	`
+
+	rows, err := r.db.Query(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := pgx.CollectRows(rows, pgx.RowToStructByName[streakfreeze.Usage])
+	if err != nil {
+		return nil, err
+	}
+
+	if usage == nil {
+		return []streakfreeze.Usage{}, nil
+	}
+
+	return usage, nil
+}
+
+// CountAvailable returns the number of earned freezes that have not been spent
+func (r *StreakFreezeRepository) CountAvailable(ctx context.Context, userID uuid.UUID) (int, error) {
+	stmt := `
+		SELECT
+			COUNT(*)
+		FROM
+			streak_freezes
+		WHERE
+			user_id = @user_id
+			AND used_at IS NULL
+	`
+
+	var available int
+	err := r.db.QueryRow(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	}).Scan(&available)
+	if err != nil {
+		return 0, err
+	}
+
+	return available, nil
+}
+
+// Spend marks the user's oldest unspent freeze as used to cover the habit on
+// date. It returns false when no freeze is left.
+func (r *StreakFreezeRepository) Spend(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	date time.Time,
+) (bool, error) {
+	stmt := `
+		UPDATE streak_freezes
+		SET
+			used_at = NOW(),
+			used_on = @used_on,
+			used_habit_id = @habit_id,
+			used_habit_name = (SELECT name FROM habits WHERE id = @habit_id),
+			updated_at = NOW()
+		WHERE
+			id = (
+				SELECT
+					id
+				FROM
+					streak_freezes
+				WHERE
+					user_id = @user_id
+					AND used_at IS NULL
+				ORDER BY
+					earned_on,
+					created_at
+				LIMIT 1
+				FOR UPDATE
+			)
+	`
+
+	tag, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id":  userID,
+		"habit_id": habitID,
+		"used_on":  date,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// LockUser serializes spending and earning the user's freezes until the
+// surrounding transaction ends, so concurrent writes cannot spend the same one
+func (r *StreakFreezeRepository) LockUser(ctx context.Context, userID uuid.UUID) error {
+	stmt := `
+		SELECT pg_advisory_xact_lock(hashtext('streak_freezes:' || @user_id::TEXT))
+	`
+
+	_, err := r.db.Exec(ctx, stmt, pgx.NamedArgs{
+		"user_id": userID,
+	})
+	return err
+}
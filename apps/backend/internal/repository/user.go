@@ -7,15 +7,14 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/reche13/habitum/internal/model/user"
 )
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db DBTX) *UserRepository {
 	return &UserRepository{db: db}
 }
 
@@ -122,6 +121,21 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, payload *user
 		args["email"] = *payload.Email
 	}
 
+	if payload.Timezone != nil {
+		updates = append(updates, "timezone = @timezone")
+		args["timezone"] = *payload.Timezone
+	}
+
+	if payload.WeekStart != nil {
+		updates = append(updates, "week_start = @week_start")
+		args["week_start"] = *payload.WeekStart
+	}
+
+	if payload.DayEndsAt != nil {
+		updates = append(updates, "day_ends_at = @day_ends_at")
+		args["day_ends_at"] = *payload.DayEndsAt
+	}
+
 	if len(updates) == 0 {
 		// No updates, just return the user
 		return r.GetByID(ctx, id)
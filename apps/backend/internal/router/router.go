@@ -14,6 +14,7 @@ func NewRouter(logger zerolog.Logger ,handlers *handler.Handlers) *echo.Echo {
 	
 	router.Use(mw.Recover())
 	router.Use(mw.RequestID())
+	router.Use(mw.Clock())
 	router.Use(mw.Logger(logger))
 	router.Use(mw.CORS())
 	router.Use(middleware.BodyLimit("2M"))
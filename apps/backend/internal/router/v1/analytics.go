@@ -7,10 +7,17 @@ import (
 
 func registerAnalyticsRoutes(analytics *echo.Group, h *handler.Handlers) {
 	analytics.GET("/completion-trend", h.Analytics.GetCompletionTrend)
+	analytics.GET("/strength-trend", h.Analytics.GetStrengthTrend)
 	analytics.GET("/category-breakdown", h.Analytics.GetCategoryBreakdown)
 	analytics.GET("/day-of-week", h.Analytics.GetDayOfWeekAnalysis)
+	analytics.GET("/time-of-day", h.Analytics.GetTimeOfDayAnalysis)
 	analytics.GET("/metrics", h.Analytics.GetMetrics)
 	analytics.GET("/top-habits", h.Analytics.GetTopHabits)
 	analytics.GET("/streak-leaderboard", h.Analytics.GetStreakLeaderboard)
 	analytics.GET("/insights", h.Analytics.GetInsights)
+	analytics.POST("/insights/:id/dismiss", h.Analytics.DismissInsight)
+	analytics.POST("/insights/:id/snooze", h.Analytics.SnoozeInsight)
+	analytics.GET("/chains", h.Analytics.GetChainAnalytics)
+	analytics.GET("/correlations", h.Analytics.GetCorrelations)
+	analytics.GET("/predictions", h.Analytics.GetPredictions)
 }
\ No newline at end of file
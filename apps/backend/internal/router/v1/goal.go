@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerGoalRoutes(goals *echo.Group, h *handler.Handlers) {
+	goals.POST("", h.Goal.CreateGoal)
+	goals.GET("", h.Goal.GetGoals)
+	goals.GET("/:id", h.Goal.GetGoal)
+	goals.PATCH("/:id", h.Goal.UpdateGoal)
+	goals.DELETE("/:id", h.Goal.DeleteGoal)
+}
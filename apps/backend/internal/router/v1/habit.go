@@ -11,10 +11,18 @@ func registerHabitRoutes(habits *echo.Group, h *handler.Handlers) {
 	habits.GET("/:id", h.Habit.GetHabit)
 	habits.PATCH("/:id", h.Habit.UpdateHabit)
 	habits.DELETE("/:id", h.Habit.DeleteHabit)
+	habits.GET("/:id/challenge", h.Habit.GetChallengeProgress)
+	habits.GET("/:id/stats", h.Habit.GetHabitStats)
+
+	// Template endpoints
+	habits.POST("/from-template/:id", h.HabitTemplate.CreateHabitFromTemplate)
+	habits.POST("/:id/save-as-template", h.HabitTemplate.SaveHabitAsTemplate)
 
 	// Completion endpoints
 	habits.POST("/:id/complete", h.Habit.MarkComplete)
 	habits.DELETE("/:id/complete", h.Habit.UnmarkComplete)
+	habits.PUT("/:id/status", h.Habit.SetStatus)
+	habits.POST("/:id/freeze", h.StreakFreeze.UseFreeze)
 	habits.GET("/:id/completions", h.Habit.GetCompletions)
 	habits.GET("/:id/completion-history", h.Habit.GetCompletionHistory)
 
@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerHabitTemplateRoutes(templates *echo.Group, h *handler.Handlers) {
+	templates.GET("", h.HabitTemplate.GetTemplates)
+	templates.DELETE("/:id", h.HabitTemplate.DeleteTemplate)
+}
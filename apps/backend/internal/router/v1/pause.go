@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerPauseRoutes(pauses *echo.Group, h *handler.Handlers) {
+	pauses.POST("", h.Pause.CreatePause)
+	pauses.GET("", h.Pause.GetPauses)
+	pauses.PATCH("/:id", h.Pause.UpdatePause)
+	pauses.DELETE("/:id", h.Pause.DeletePause)
+}
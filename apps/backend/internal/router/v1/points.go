@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerPointsRoutes(points *echo.Group, h *handler.Handlers) {
+	points.GET("", h.Points.GetSummary)
+	points.GET("/ledger", h.Points.GetLedger)
+}
@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerReportRoutes(reports *echo.Group, h *handler.Handlers) {
+	reports.GET("/weekly", h.Report.GetWeeklyReport)
+	reports.GET("/monthly", h.Report.GetMonthlyReport)
+}
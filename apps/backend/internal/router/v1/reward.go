@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerRewardRoutes(rewards *echo.Group, h *handler.Handlers) {
+	rewards.POST("", h.Reward.CreateReward)
+	rewards.GET("", h.Reward.GetRewards)
+	rewards.GET("/:id", h.Reward.GetReward)
+	rewards.PATCH("/:id", h.Reward.UpdateReward)
+	rewards.DELETE("/:id", h.Reward.DeleteReward)
+	rewards.POST("/:id/redeem", h.Reward.RedeemReward)
+}
@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerRoutineRoutes(routines *echo.Group, h *handler.Handlers) {
+	routines.POST("", h.Routine.CreateRoutine)
+	routines.GET("", h.Routine.GetRoutines)
+	routines.GET("/:id", h.Routine.GetRoutine)
+	routines.PATCH("/:id", h.Routine.UpdateRoutine)
+	routines.DELETE("/:id", h.Routine.DeleteRoutine)
+
+	routines.POST("/:id/complete", h.Routine.CompleteRoutine)
+}
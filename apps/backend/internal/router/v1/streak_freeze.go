@@ -0,0 +1,10 @@
+package v1
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/reche13/habitum/internal/handler"
+)
+
+func registerStreakFreezeRoutes(streakFreezes *echo.Group, h *handler.Handlers) {
+	streakFreezes.GET("", h.StreakFreeze.GetInventory)
+}
@@ -14,6 +14,33 @@ func RegisterAPIV1Routes(api *echo.Group, h *handler.Handlers) {
 	
 	habits := api.Group("/habits")
 	registerHabitRoutes(habits, h)
+
+	habitTemplates := api.Group("/habit-templates")
+	registerHabitTemplateRoutes(habitTemplates, h)
+
+	routines := api.Group("/routines")
+	registerRoutineRoutes(routines, h)
+
+	pauses := api.Group("/pauses")
+	registerPauseRoutes(pauses, h)
+
+	streakFreezes := api.Group("/streak-freezes")
+	registerStreakFreezeRoutes(streakFreezes, h)
+
+	goals := api.Group("/goals")
+	registerGoalRoutes(goals, h)
+
+	achievements := api.Group("/achievements")
+	registerAchievementRoutes(achievements, h)
+
+	points := api.Group("/points")
+	registerPointsRoutes(points, h)
+
+	rewards := api.Group("/rewards")
+	registerRewardRoutes(rewards, h)
+
+	reports := api.Group("/reports")
+	registerReportRoutes(reports, h)
 	
 	analytics := api.Group("/analytics")
 	registerAnalyticsRoutes(analytics, h)
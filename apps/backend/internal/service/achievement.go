@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/achievement"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// Completions in one category that unlock its mastery achievement
+const categoryMasteryTarget = 100
+
+// achievementCatalogue returns every achievement, in display order
+func achievementCatalogue() []achievement.Definition {
+	catalogue := []achievement.Definition{
+		{Key: "first_completion", Name: "First Step", Description: "Complete a habit for the first time", Icon: "sparkles", Group: "completions", Target: 1},
+		{Key: "streak_7", Name: "One Week Strong", Description: "Reach a 7-day streak on a daily habit", Icon: "flame", Group: "streaks", Target: 7},
+		{Key: "streak_30", Name: "Monthly Momentum", Description: "Reach a 30-day streak on a daily habit", Icon: "flame", Group: "streaks", Target: 30},
+		{Key: "streak_100", Name: "Centurion", Description: "Reach a 100-day streak on a daily habit", Icon: "flame", Group: "streaks", Target: 100},
+		{Key: "perfect_week", Name: "Perfect Week", Description: "Complete every scheduled habit on every day of a week", Icon: "calendar-check", Group: "consistency", Target: 7},
+		{Key: "completions_1000", Name: "Thousand Club", Description: "Log 1000 completions", Icon: "trophy", Group: "completions", Target: 1000},
+	}
+
+	for _, category := range habit.Categories() {
+		if category == habit.Other {
+			continue
+		}
+		label := strings.ToUpper(string(category[0])) + string(category[1:])
+		catalogue = append(catalogue, achievement.Definition{
+			Key:         "mastery_" + string(category),
+			Name:        label + " Master",
+			Description: "Log 100 completions of " + string(category) + " habits",
+			Icon:        "award",
+			Group:       "mastery",
+			Target:      categoryMasteryTarget,
+		})
+	}
+
+	return catalogue
+}
+
+// achievementDefinitions returns the catalogue keyed by achievement key
+func achievementDefinitions() map[string]achievement.Definition {
+	definitions := make(map[string]achievement.Definition)
+	for _, d := range achievementCatalogue() {
+		definitions[d.Key] = d
+	}
+	return definitions
+}
+
+// evaluateAchievements records the user's progress towards every achievement
+// after a log for logDate was written, unlocking those that reached their
+// target. It must run on transaction-bound repositories, after streaks and
+// rollups were updated.
+func evaluateAchievements(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	logDate time.Time,
+) ([]achievement.Achievement, error) {
+	byCategory, err := tx.HabitLog.CountCompletedByCategory(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	total := 0
+	for _, count := range byCategory {
+		total += count
+	}
+
+	habits, _, err := tx.Habit.List(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	longestDailyStreak := 0
+	for _, h := range habits {
+		if h.Frequency == habit.Daily && h.LongestStreak > longestDailyStreak {
+			longestDailyStreak = h.LongestStreak
+		}
+	}
+
+	perfectDays, err := bestPerfectWeekDays(ctx, tx, userID, habits, logDate)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := map[string]int{
+		"first_completion": total,
+		"streak_7":         longestDailyStreak,
+		"streak_30":        longestDailyStreak,
+		"streak_100":       longestDailyStreak,
+		"perfect_week":     perfectDays,
+		"completions_1000": total,
+	}
+	for _, category := range habit.Categories() {
+		if category != habit.Other {
+			progress["mastery_"+string(category)] = byCategory[string(category)]
+		}
+	}
+
+	targets := make(map[string]int, len(progress))
+	for key, d := range achievementDefinitions() {
+		targets[key] = d.Target
+	}
+
+	return tx.Achievement.RecordProgress(ctx, userID, progress, targets)
+}
+
+// bestPerfectWeekDays returns the most days, in the finished week containing
+// logDate and in last week, on which every scheduled habit was completed.
+// Daily habits are judged day by day on their rollups and weekly habits on
+// whether the week met their target, without which no day of it is perfect.
+// Days with nothing scheduled count as perfect, but a week must have some.
+func bestPerfectWeekDays(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	logDate time.Time,
+) (int, error) {
+	clock := lib.ClockFromContext(ctx)
+	thisWeek := clock.WeekStart(clock.Today())
+
+	pauses, err := loadPauseSchedule(ctx, tx.Pause, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	daily := make([]habit.Habit, 0, len(habits))
+	weekly := make([]habit.Habit, 0)
+	for _, h := range habits {
+		if h.Frequency == habit.Weekly {
+			weekly = append(weekly, h)
+		} else {
+			daily = append(daily, h)
+		}
+	}
+
+	best := 0
+	for _, weekStart := range []time.Time{clock.WeekStart(logDate), thisWeek.AddDate(0, 0, -7)} {
+		if !weekStart.Before(thisWeek) {
+			continue
+		}
+
+		weekEnd := weekStart.AddDate(0, 0, 6)
+		logs, err := tx.HabitLog.GetByDateRange(ctx, userID, weekStart, weekEnd)
+		if err != nil {
+			return 0, err
+		}
+
+		perfect, scheduled := 0, 0
+		for _, ru := range computeDailyRollups(userID, daily, logs, pauses, clock, weekStart, weekEnd) {
+			scheduled += ru.ScheduledCount
+			if ru.CompletedCount >= ru.ScheduledCount {
+				perfect++
+			}
+		}
+
+		logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+		for _, log := range logs {
+			logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+		}
+		for _, h := range weekly {
+			excluded := habitActiveDayFilter(h, pauses, logsByHabit[h.ID], clock)
+			target := weekTarget(weekStart, h.WeeklyTarget(), excluded)
+			if target == 0 {
+				continue
+			}
+			scheduled += target
+
+			completedDates := make([]time.Time, 0)
+			for _, log := range logsByHabit[h.ID] {
+				if log.Completed {
+					completedDates = append(completedDates, log.LogDate)
+				}
+			}
+			if !isWeekMet(weeklyCounts(completedDates, clock), weekStart, h.WeeklyTarget(), excluded) {
+				perfect = 0
+			}
+		}
+
+		if scheduled > 0 && perfect > best {
+			best = perfect
+		}
+	}
+
+	return best, nil
+}
+
+type AchievementService struct {
+	*BaseService
+	achievementRepo *repository.AchievementRepository
+	userRepo        *repository.UserRepository
+}
+
+func NewAchievementService(
+	achievementRepo *repository.AchievementRepository,
+	userRepo *repository.UserRepository,
+) *AchievementService {
+	return &AchievementService{
+		BaseService: &BaseService{
+			resourceName: "achievement",
+		},
+		achievementRepo: achievementRepo,
+		userRepo:        userRepo,
+	}
+}
+
+// GetAchievements lists the whole catalogue with the user's progress
+func (s *AchievementService) GetAchievements(ctx context.Context, userID uuid.UUID) (*achievement.AchievementsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	stored, err := s.achievementRepo.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	byKey := make(map[string]achievement.Achievement, len(stored))
+	for _, a := range stored {
+		byKey[a.Key] = a
+	}
+
+	catalogue := achievementCatalogue()
+	response := &achievement.AchievementsResponse{
+		Total: len(catalogue),
+		Data:  make([]achievement.AchievementResponse, 0, len(catalogue)),
+	}
+	for _, d := range catalogue {
+		a := byKey[d.Key]
+		response.Data = append(response.Data, achievement.AchievementResponse{
+			Key:         d.Key,
+			Name:        d.Name,
+			Description: d.Description,
+			Icon:        d.Icon,
+			Group:       d.Group,
+			Target:      d.Target,
+			Progress:    min(a.Progress, d.Target),
+			Unlocked:    a.UnlockedAt != nil,
+			UnlockedAt:  a.UnlockedAt,
+		})
+		if a.UnlockedAt != nil {
+			response.UnlockedCount++
+		}
+	}
+
+	return response, nil
+}
@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -10,6 +10,8 @@ import (
 	"github.com/reche13/habitum/internal/lib"
 	"github.com/reche13/habitum/internal/model/analytics"
 	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/rollup"
 	"github.com/reche13/habitum/internal/repository"
 )
 
@@ -17,11 +19,21 @@ type AnalyticsService struct {
 	*BaseService
 	habitRepo    *repository.HabitRepository
 	habitLogRepo *repository.HabitLogRepository
+	pauseRepo    *repository.PauseRepository
+	userRepo     *repository.UserRepository
+	rollupRepo   *repository.DailyRollupRepository
+	strengthRepo *repository.HabitStrengthRepository
+	insightRepo  *repository.InsightDismissalRepository
 }
 
 func NewAnalyticsService(
 	habitRepo *repository.HabitRepository,
 	habitLogRepo *repository.HabitLogRepository,
+	pauseRepo *repository.PauseRepository,
+	userRepo *repository.UserRepository,
+	rollupRepo *repository.DailyRollupRepository,
+	strengthRepo *repository.HabitStrengthRepository,
+	insightRepo *repository.InsightDismissalRepository,
 ) *AnalyticsService {
 	return &AnalyticsService{
 		BaseService: &BaseService{
@@ -29,118 +41,165 @@ func NewAnalyticsService(
 		},
 		habitRepo:    habitRepo,
 		habitLogRepo: habitLogRepo,
+		pauseRepo:    pauseRepo,
+		userRepo:     userRepo,
+		rollupRepo:   rollupRepo,
+		strengthRepo: strengthRepo,
+		insightRepo:  insightRepo,
 	}
 }
 
-func (s *AnalyticsService) GetCompletionTrend(ctx context.Context, userID uuid.UUID, period string) (*analytics.CompletionTrendResponse, error) {
-	// Calculate date range based on period
-	now := lib.NormalizeDate(time.Now().UTC())
-	var startDate time.Time
-
-	switch period {
-	case "7d":
-		startDate = now.AddDate(0, 0, -7)
-	case "30d":
-		startDate = now.AddDate(0, 0, -30)
-	case "90d":
-		startDate = now.AddDate(0, 0, -90)
-	case "all":
-		// Get the earliest habit creation date
-		habits, _, err := s.habitRepo.List(ctx, userID, nil)
-		if err != nil {
-			return nil, s.wrapError(err)
-		}
-		if len(habits) == 0 {
-			return &analytics.CompletionTrendResponse{Data: []analytics.CompletionTrendDataPoint{}}, nil
-		}
-		startDate = habits[0].CreatedAt
-		for _, h := range habits {
-			if h.CreatedAt.Before(startDate) {
-				startDate = h.CreatedAt
-			}
-		}
-		startDate = lib.NormalizeDate(startDate)
-	default:
-		// Default to 30 days
-		startDate = now.AddDate(0, 0, -30)
+// GetCompletionTrend returns completions per bucket of the given granularity.
+// With byHabit set, each habit's own series is included as well.
+func (s *AnalyticsService) GetCompletionTrend(
+	ctx context.Context,
+	userID uuid.UUID,
+	q analytics.RangeQuery,
+	granularity string,
+	byHabit bool,
+) (*analytics.CompletionTrendResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
+	clock := lib.ClockFromContext(ctx)
 
-	// Get all habits (including archived - we'll check archived date per day)
-	allHabits, _, err := s.habitRepo.List(ctx, userID, nil)
+	// Get all habits (including archived - rollups check archived date per day)
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
-	// Get all logs in the date range
-	logs, err := s.habitLogRepo.GetByDateRange(ctx, userID, startDate, now)
+	startDate, endDate, err := resolveRange(q, habits, clock)
+	if err != nil {
+		return nil, err
+	}
+	if granularity == "" {
+		granularity = GranularityDay
+	}
+	response := &analytics.CompletionTrendResponse{
+		Range:       newDateRange(startDate, endDate),
+		Granularity: granularity,
+		Data:        []analytics.CompletionTrendDataPoint{},
+	}
+	if len(habits) == 0 {
+		return response, nil
+	}
+
+	rollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, startDate, endDate)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
 
-	// Group logs by date and count completions
-	completionsByDate := make(map[string]int) // date -> count of completions
-	
-	for _, log := range logs {
-		if log.Completed {
-			dateKey := log.LogDate.Format("2006-01-02")
-			completionsByDate[dateKey]++
+	response.Data = trendBuckets(rollups, granularity, clock)
+	response.Summary = completionTrendSummary(rollups)
+
+	if byHabit {
+		response.Habits, err = s.habitCompletionTrends(ctx, userID, habits, granularity, startDate, endDate)
+		if err != nil {
+			return nil, s.wrapError(err)
 		}
 	}
 
-	// Build response data points for each day in range
-	dataPoints := make([]analytics.CompletionTrendDataPoint, 0)
-	currentDate := startDate
-	
-	for !currentDate.After(now) {
-		dateKey := currentDate.Format("2006-01-02")
-		completions := completionsByDate[dateKey]
-		
-		// Count active habits on this date (habits created on or before this date and not archived on this date)
-		totalHabits := 0
-		for _, h := range allHabits {
-			habitCreatedDate := lib.NormalizeDate(h.CreatedAt)
-			// Habit must be created on or before this date
-			if !currentDate.Before(habitCreatedDate) {
-				// Check if habit was archived on or before this date
-				if h.ArchivedAt == nil {
-					totalHabits++
-				} else {
-					archivedDate := lib.NormalizeDate(*h.ArchivedAt)
-					if currentDate.Before(archivedDate) {
-						totalHabits++
-					}
-				}
-			}
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		prevRollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, prevStart, prevEnd)
+		if err != nil {
+			return nil, s.wrapError(err)
 		}
+		previous := completionTrendSummary(prevRollups)
 
-		// Calculate completion rate
-		completionRate := 0.0
-		if totalHabits > 0 {
-			completionRate = (float64(completions) / float64(totalHabits)) * 100
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		response.Deltas = map[string]analytics.Delta{
+			"completions":    newDelta(float64(response.Summary.Completions), float64(previous.Completions)),
+			"totalHabits":    newDelta(float64(response.Summary.TotalHabits), float64(previous.TotalHabits)),
+			"completionRate": newDelta(response.Summary.CompletionRate, previous.CompletionRate),
 		}
+	}
 
-		dataPoints = append(dataPoints, analytics.CompletionTrendDataPoint{
-			Date:           dateKey,
-			Completions:    completions,
-			TotalHabits:    totalHabits,
-			CompletionRate: completionRate,
-		})
+	return response, nil
+}
 
-		// Move to next day
-		currentDate = currentDate.AddDate(0, 0, 1)
+// habitCompletionTrends buckets each habit's completions from startDate to
+// endDate, skipping habits that were neither scheduled nor logged in the range
+func (s *AnalyticsService) habitCompletionTrends(
+	ctx context.Context,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	granularity string,
+	startDate, endDate time.Time,
+) ([]analytics.HabitCompletionTrend, error) {
+	clock := lib.ClockFromContext(ctx)
+
+	logFrom, logTo := rollupLogRange(clock, startDate, endDate)
+	logs, err := s.habitLogRepo.GetByDateRange(ctx, userID, logFrom, logTo)
+	if err != nil {
+		return nil, err
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
 	}
 
-	return &analytics.CompletionTrendResponse{
-		Data: dataPoints,
-	}, nil
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make([]analytics.HabitCompletionTrend, 0)
+	for _, h := range habits {
+		// A habit's own rollups are its per-day schedule and completions
+		rollups := computeDailyRollups(userID, []habit.Habit{h}, logsByHabit[h.ID], pauses, clock, startDate, endDate)
+
+		tracked := false
+		for _, ru := range rollups {
+			if ru.ScheduledCount > 0 || ru.SkippedCount > 0 {
+				tracked = true
+				break
+			}
+		}
+		if !tracked {
+			continue
+		}
+
+		trends = append(trends, analytics.HabitCompletionTrend{
+			HabitID: h.ID.String(),
+			Name:    h.Name,
+			Data:    trendBuckets(rollups, granularity, clock),
+		})
+	}
+
+	return trends, nil
 }
 
-func (s *AnalyticsService) GetCategoryBreakdown(ctx context.Context, userID uuid.UUID) (*analytics.CategoryBreakdownResponse, error) {
+// completionTrendSummary totals a range of daily rollups
+func completionTrendSummary(rollups []rollup.DailyRollup) analytics.CompletionTrendSummary {
+	summary := analytics.CompletionTrendSummary{}
+	for _, ru := range rollups {
+		summary.Completions += ru.CompletedCount
+		summary.TotalHabits += ru.ScheduledCount
+	}
+	if summary.TotalHabits > 0 {
+		summary.CompletionRate = (float64(summary.Completions) / float64(summary.TotalHabits)) * 100
+	}
+	return summary
+}
+
+func (s *AnalyticsService) GetCategoryBreakdown(ctx context.Context, userID uuid.UUID, q analytics.RangeQuery) (*analytics.CategoryBreakdownResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
 	// Get all active habits
 	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
 	// Filter out archived habits
 	activeHabits := make([]habit.Habit, 0)
@@ -150,6 +209,60 @@ func (s *AnalyticsService) GetCategoryBreakdown(ctx context.Context, userID uuid
 		}
 	}
 
+	startDate, endDate, err := resolveRange(q, activeHabits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.CategoryBreakdownResponse{
+		Range: newDateRange(startDate, endDate),
+		Data:  []analytics.CategoryBreakdownDataPoint{},
+	}
+	if len(activeHabits) == 0 {
+		return response, nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logsByHabit, err := s.loadLogsByHabit(ctx, userID, activeHabits, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	response.Data = categoryBreakdown(activeHabits, logsByHabit, pauses, clock, startDate, endDate)
+
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		previous := make(map[string]analytics.CategoryBreakdownDataPoint)
+		for _, point := range categoryBreakdown(activeHabits, logsByHabit, pauses, clock, prevStart, prevEnd) {
+			previous[point.Category] = point
+		}
+
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		for i, point := range response.Data {
+			prev := previous[point.Category]
+			response.Data[i].Deltas = map[string]analytics.Delta{
+				"avgCompletionRate": newDelta(point.AvgCompletionRate, prev.AvgCompletionRate),
+				"totalCompletions":  newDelta(float64(point.TotalCompletions), float64(prev.TotalCompletions)),
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// categoryBreakdown groups the habits that existed by endDate by category,
+// with their completion rates and completions between startDate and endDate
+func categoryBreakdown(
+	habits []habit.Habit,
+	logsByHabit map[uuid.UUID][]habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+	startDate, endDate time.Time,
+) []analytics.CategoryBreakdownDataPoint {
 	// Group habits by category and calculate stats
 	categoryStats := make(map[string]struct {
 		habitCount int
@@ -157,38 +270,17 @@ func (s *AnalyticsService) GetCategoryBreakdown(ctx context.Context, userID uuid
 		totalCompletions int
 	})
 
-	for _, h := range activeHabits {
-		// Calculate completion rate for this habit
-		completionRate, err := CalculateCompletionRate(
-			ctx,
-			s.habitLogRepo,
-			userID,
-			h.ID,
-			h.CreatedAt,
-			h.Frequency,
-		)
-		if err != nil {
-			completionRate = 0
-		}
-
-		// Get total completions for this habit
-		startDate := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-		endDate := lib.NormalizeDate(time.Now().UTC())
-		logs, err := s.habitLogRepo.GetByHabit(ctx, userID, h.ID, startDate, endDate)
-		totalCompletions := 0
-		if err == nil {
-			for _, log := range logs {
-				if log.Completed {
-					totalCompletions++
-				}
-			}
+	for _, h := range habits {
+		habitStats, ok := habitStatsBetween(h, logsByHabit[h.ID], pauses, clock, startDate, endDate)
+		if !ok {
+			continue
 		}
 
 		categoryKey := string(h.Category)
 		stats := categoryStats[categoryKey]
 		stats.habitCount++
-		stats.completionRates = append(stats.completionRates, completionRate)
-		stats.totalCompletions += totalCompletions
+		stats.completionRates = append(stats.completionRates, habitStats.completionRate)
+		stats.totalCompletions += habitStats.completions
 		categoryStats[categoryKey] = stats
 	}
 
@@ -220,123 +312,82 @@ func (s *AnalyticsService) GetCategoryBreakdown(ctx context.Context, userID uuid
 		})
 	}
 
-	return &analytics.CategoryBreakdownResponse{
-		Data: dataPoints,
-	}, nil
+	sort.Slice(dataPoints, func(i, j int) bool {
+		return dataPoints[i].Category < dataPoints[j].Category
+	})
+
+	return dataPoints
 }
 
-func (s *AnalyticsService) GetDayOfWeekAnalysis(ctx context.Context, userID uuid.UUID, period string) (*analytics.DayOfWeekResponse, error) {
-	// Calculate date range based on period
-	now := lib.NormalizeDate(time.Now().UTC())
-	var startDate time.Time
-
-	switch period {
-	case "7d":
-		startDate = now.AddDate(0, 0, -7)
-	case "30d":
-		startDate = now.AddDate(0, 0, -30)
-	case "90d":
-		startDate = now.AddDate(0, 0, -90)
-	case "all":
-		// Get the earliest habit creation date
-		habits, _, err := s.habitRepo.List(ctx, userID, nil)
-		if err != nil {
-			return nil, s.wrapError(err)
-		}
-		if len(habits) == 0 {
-			return &analytics.DayOfWeekResponse{Data: []analytics.DayOfWeekDataPoint{}}, nil
-		}
-		startDate = habits[0].CreatedAt
-		for _, h := range habits {
-			if h.CreatedAt.Before(startDate) {
-				startDate = h.CreatedAt
-			}
-		}
-		startDate = lib.NormalizeDate(startDate)
-	default:
-		// Default to all time if period is empty or invalid
-		if period == "" {
-			habits, _, err := s.habitRepo.List(ctx, userID, nil)
-			if err != nil {
-				return nil, s.wrapError(err)
-			}
-			if len(habits) == 0 {
-				return &analytics.DayOfWeekResponse{Data: []analytics.DayOfWeekDataPoint{}}, nil
-			}
-			startDate = habits[0].CreatedAt
-			for _, h := range habits {
-				if h.CreatedAt.Before(startDate) {
-					startDate = h.CreatedAt
-				}
-			}
-			startDate = lib.NormalizeDate(startDate)
-		} else {
-			// Default to 30 days for invalid period
-			startDate = now.AddDate(0, 0, -30)
-		}
+func (s *AnalyticsService) GetDayOfWeekAnalysis(ctx context.Context, userID uuid.UUID, q analytics.RangeQuery) (*analytics.DayOfWeekResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
+	clock := lib.ClockFromContext(ctx)
 
-	// Get all habits
-	allHabits, _, err := s.habitRepo.List(ctx, userID, nil)
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
-	// Get all logs in the date range
-	logs, err := s.habitLogRepo.GetByDateRange(ctx, userID, startDate, now)
+	startDate, endDate, err := resolveRange(q, habits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.DayOfWeekResponse{
+		Range: newDateRange(startDate, endDate),
+		Data:  []analytics.DayOfWeekDataPoint{},
+	}
+	if len(habits) == 0 {
+		return response, nil
+	}
+
+	rollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, startDate, endDate)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	response.Data = dayOfWeekPoints(rollups, clock)
 
-	// Group completions by day of week (0=Monday, 6=Sunday)
-	completionsByDay := make(map[int]int) // day index -> count
-	habitsByDay := make(map[int]int)      // day index -> total habits active on that day
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		prevRollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, prevStart, prevEnd)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+		previous := dayOfWeekPoints(prevRollups, clock)
 
-	// Count completions by day of week
-	for _, log := range logs {
-		if log.Completed {
-			// Convert to weekday (Monday=0, Sunday=6)
-			weekday := int(log.LogDate.Weekday())
-			if weekday == 0 {
-				weekday = 7 // Sunday becomes 7, then we'll convert to 6
-			}
-			dayIndex := weekday - 1 // Monday=0, Sunday=6
-			completionsByDay[dayIndex]++
-		}
-	}
-
-	// Count total habits active per day of week
-	// For each day in the range, count active habits
-	currentDate := startDate
-	for !currentDate.After(now) {
-		weekday := int(currentDate.Weekday())
-		if weekday == 0 {
-			weekday = 7
-		}
-		dayIndex := weekday - 1
-
-		// Count active habits on this date
-		activeHabits := 0
-		for _, h := range allHabits {
-			habitCreatedDate := lib.NormalizeDate(h.CreatedAt)
-			if !currentDate.Before(habitCreatedDate) {
-				if h.ArchivedAt == nil {
-					activeHabits++
-				} else {
-					archivedDate := lib.NormalizeDate(*h.ArchivedAt)
-					if currentDate.Before(archivedDate) {
-						activeHabits++
-					}
-				}
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		for i, point := range response.Data {
+			prev := previous[i]
+			response.Data[i].Deltas = map[string]analytics.Delta{
+				"completions":    newDelta(float64(point.Completions), float64(prev.Completions)),
+				"totalHabits":    newDelta(float64(point.TotalHabits), float64(prev.TotalHabits)),
+				"completionRate": newDelta(point.CompletionRate, prev.CompletionRate),
 			}
 		}
-		habitsByDay[dayIndex] += activeHabits
+	}
 
-		currentDate = currentDate.AddDate(0, 0, 1)
+	return response, nil
+}
+
+// dayOfWeekPoints totals daily rollups by day of week, starting with the
+// first day of the user's week
+func dayOfWeekPoints(rollups []rollup.DailyRollup, clock lib.Clock) []analytics.DayOfWeekDataPoint {
+	// Group completions by day of week (0 = the first day of the user's week)
+	completionsByDay := make(map[int]int) // day index -> count
+	habitsByDay := make(map[int]int)      // day index -> total habits scheduled on that day
+
+	for _, ru := range rollups {
+		dayIndex := clock.WeekdayIndex(ru.Day)
+		completionsByDay[dayIndex] += ru.CompletedCount
+		habitsByDay[dayIndex] += ru.ScheduledCount
 	}
 
 	// Build response data points for each day of week
-	dayNames := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+	weekdays := clock.Weekdays()
 	dataPoints := make([]analytics.DayOfWeekDataPoint, 7)
 
 	for dayIndex := 0; dayIndex < 7; dayIndex++ {
@@ -350,7 +401,7 @@ func (s *AnalyticsService) GetDayOfWeekAnalysis(ctx context.Context, userID uuid
 		}
 
 		dataPoints[dayIndex] = analytics.DayOfWeekDataPoint{
-			Day:            dayNames[dayIndex],
+			Day:            weekdays[dayIndex].String(),
 			DayIndex:       dayIndex,
 			Completions:    completions,
 			TotalHabits:    totalHabits,
@@ -358,17 +409,22 @@ func (s *AnalyticsService) GetDayOfWeekAnalysis(ctx context.Context, userID uuid
 		}
 	}
 
-	return &analytics.DayOfWeekResponse{
-		Data: dataPoints,
-	}, nil
+	return dataPoints
 }
 
-func (s *AnalyticsService) GetMetrics(ctx context.Context, userID uuid.UUID) (*analytics.MetricsResponse, error) {
+func (s *AnalyticsService) GetMetrics(ctx context.Context, userID uuid.UUID, q analytics.RangeQuery) (*analytics.MetricsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
 	// Get all active habits
 	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
 	// Filter out archived habits
 	activeHabits := make([]habit.Habit, 0)
@@ -378,74 +434,112 @@ func (s *AnalyticsService) GetMetrics(ctx context.Context, userID uuid.UUID) (*a
 		}
 	}
 
+	startDate, endDate, err := resolveRange(q, activeHabits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.MetricsResponse{Range: newDateRange(startDate, endDate)}
 	if len(activeHabits) == 0 {
-		return &analytics.MetricsResponse{
-			AvgCompletionRate: 0,
-			AvgStreak:         0,
-			TotalCompletions:  0,
-			ConsistencyScore:  0,
-		}, nil
+		return response, nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
 
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logsByHabit, err := s.loadLogsByHabit(ctx, userID, activeHabits, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	current := rangeMetrics(activeHabits, logsByHabit, pauses, clock, startDate, endDate)
+	response.AvgCompletionRate = current.AvgCompletionRate
+	response.AvgStreak = current.AvgStreak
+	response.TotalCompletions = current.TotalCompletions
+	response.ConsistencyScore = current.ConsistencyScore
+
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		previous := rangeMetrics(activeHabits, logsByHabit, pauses, clock, prevStart, prevEnd)
+
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		response.Deltas = map[string]analytics.Delta{
+			"avgCompletionRate": newDelta(current.AvgCompletionRate, previous.AvgCompletionRate),
+			"avgStreak":         newDelta(current.AvgStreak, previous.AvgStreak),
+			"totalCompletions":  newDelta(float64(current.TotalCompletions), float64(previous.TotalCompletions)),
+			"consistencyScore":  newDelta(current.ConsistencyScore, previous.ConsistencyScore),
+		}
+	}
+
+	return response, nil
+}
+
+// rangeMetrics averages the stats of the habits that existed by endDate
+// between startDate and endDate
+func rangeMetrics(
+	habits []habit.Habit,
+	logsByHabit map[uuid.UUID][]habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+	startDate, endDate time.Time,
+) analytics.MetricsResponse {
 	// Calculate metrics
 	totalCompletionRate := 0.0
 	totalStreak := 0
 	totalCompletions := 0
+	counted := 0
 
-	for _, h := range activeHabits {
-		// Get completion rate
-		completionRate, err := CalculateCompletionRate(
-			ctx,
-			s.habitLogRepo,
-			userID,
-			h.ID,
-			h.CreatedAt,
-			h.Frequency,
-		)
-		if err != nil {
-			completionRate = 0
+	for _, h := range habits {
+		stats, ok := habitStatsBetween(h, logsByHabit[h.ID], pauses, clock, startDate, endDate)
+		if !ok {
+			continue
 		}
-		totalCompletionRate += completionRate
-
-		// Get current streak
-		totalStreak += h.CurrentStreak
+		counted++
+		totalCompletionRate += stats.completionRate
+		totalStreak += stats.streak
+		totalCompletions += stats.completions
+	}
 
-		// Get total completions for this habit
-		startDate := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-		endDate := lib.NormalizeDate(time.Now().UTC())
-		logs, err := s.habitLogRepo.GetByHabit(ctx, userID, h.ID, startDate, endDate)
-		if err == nil {
-			for _, log := range logs {
-				if log.Completed {
-					totalCompletions++
-				}
-			}
-		}
+	if counted == 0 {
+		return analytics.MetricsResponse{}
 	}
 
 	// Calculate averages
-	avgCompletionRate := totalCompletionRate / float64(len(activeHabits))
-	avgStreak := float64(totalStreak) / float64(len(activeHabits))
+	avgCompletionRate := totalCompletionRate / float64(counted)
+	avgStreak := float64(totalStreak) / float64(counted)
 
 	// Calculate consistency score (based on completion rate - higher is better)
 	// Simple approach: use average completion rate as consistency score
 	// Could be enhanced with variance calculation, but keeping it simple
 	consistencyScore := avgCompletionRate
 
-	return &analytics.MetricsResponse{
+	return analytics.MetricsResponse{
 		AvgCompletionRate: avgCompletionRate,
 		AvgStreak:        avgStreak,
 		TotalCompletions: totalCompletions,
 		ConsistencyScore: consistencyScore,
-	}, nil
+	}
 }
 
-func (s *AnalyticsService) GetTopHabits(ctx context.Context, userID uuid.UUID, limit int, sortBy string) (*analytics.TopHabitsResponse, error) {
+func (s *AnalyticsService) GetTopHabits(ctx context.Context, userID uuid.UUID, limit int, sortBy string, q analytics.RangeQuery) (*analytics.TopHabitsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
 	// Get all active habits
 	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
 	// Filter out archived habits
 	activeHabits := make([]habit.Habit, 0)
@@ -455,33 +549,57 @@ func (s *AnalyticsService) GetTopHabits(ctx context.Context, userID uuid.UUID, l
 		}
 	}
 
+	startDate, endDate, err := resolveRange(q, activeHabits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.TopHabitsResponse{
+		Range: newDateRange(startDate, endDate),
+		Data:  []analytics.TopHabitDataPoint{},
+	}
 	if len(activeHabits) == 0 {
-		return &analytics.TopHabitsResponse{Data: []analytics.TopHabitDataPoint{}}, nil
+		return response, nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logsByHabit, err := s.loadLogsByHabit(ctx, userID, activeHabits, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	strengths, err := loadHabitStrengths(ctx, s.strengthRepo, s.habitLogRepo, userID, activeHabits, pauses, endDate, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
 
 	// Calculate completion rate and prepare data for sorting
 	type habitWithStats struct {
 		habit          habit.Habit
 		completionRate float64
+		strength       float64
+		streak         int
 	}
 
 	habitsWithStats := make([]habitWithStats, 0, len(activeHabits))
 	for _, h := range activeHabits {
-		completionRate, err := CalculateCompletionRate(
-			ctx,
-			s.habitLogRepo,
-			userID,
-			h.ID,
-			h.CreatedAt,
-			h.Frequency,
-		)
-		if err != nil {
-			completionRate = 0
+		stats, ok := habitStatsBetween(h, logsByHabit[h.ID], pauses, clock, startDate, endDate)
+		if !ok {
+			continue
 		}
 
 		habitsWithStats = append(habitsWithStats, habitWithStats{
 			habit:          h,
-			completionRate: completionRate,
+			completionRate: stats.completionRate,
+			strength:       strengths[h.ID][0],
+			streak:         stats.streak,
 		})
 	}
 
@@ -497,10 +615,19 @@ func (s *AnalyticsService) GetTopHabits(ctx context.Context, userID uuid.UUID, l
 			}
 		}
 	case "streak":
-		// Sort by current streak (descending)
+		// Sort by streak at the end of the range (descending)
 		for i := 0; i < len(habitsWithStats)-1; i++ {
 			for j := i + 1; j < len(habitsWithStats); j++ {
-				if habitsWithStats[i].habit.CurrentStreak < habitsWithStats[j].habit.CurrentStreak {
+				if habitsWithStats[i].streak < habitsWithStats[j].streak {
+					habitsWithStats[i], habitsWithStats[j] = habitsWithStats[j], habitsWithStats[i]
+				}
+			}
+		}
+	case "strength":
+		// Sort by strength score (descending)
+		for i := 0; i < len(habitsWithStats)-1; i++ {
+			for j := i + 1; j < len(habitsWithStats); j++ {
+				if habitsWithStats[i].strength < habitsWithStats[j].strength {
 					habitsWithStats[i], habitsWithStats[j] = habitsWithStats[j], habitsWithStats[i]
 				}
 			}
@@ -522,29 +649,131 @@ func (s *AnalyticsService) GetTopHabits(ctx context.Context, userID uuid.UUID, l
 	}
 
 	// Build response
-	dataPoints := make([]analytics.TopHabitDataPoint, len(habitsWithStats))
-	for i, hws := range habitsWithStats {
-		dataPoints[i] = analytics.TopHabitDataPoint{
+	for _, hws := range habitsWithStats {
+		response.Data = append(response.Data, analytics.TopHabitDataPoint{
 			HabitID:        hws.habit.ID.String(),
 			Name:           hws.habit.Name,
 			Category:       string(hws.habit.Category),
 			CompletionRate: hws.completionRate,
-			CurrentStreak:  hws.habit.CurrentStreak,
+			Strength:       hws.strength,
+			CurrentStreak:  hws.streak,
 			LongestStreak:  hws.habit.LongestStreak,
+		})
+	}
+
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		prevStrengths, err := loadHabitStrengths(ctx, s.strengthRepo, s.habitLogRepo, userID, activeHabits, pauses, prevEnd, prevEnd)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		for i, hws := range habitsWithStats {
+			prev, _ := habitStatsBetween(hws.habit, logsByHabit[hws.habit.ID], pauses, clock, prevStart, prevEnd)
+			response.Data[i].Deltas = map[string]analytics.Delta{
+				"completionRate": newDelta(hws.completionRate, prev.completionRate),
+				"strength":       newDelta(hws.strength, prevStrengths[hws.habit.ID][0]),
+			}
 		}
 	}
 
-	return &analytics.TopHabitsResponse{
-		Data: dataPoints,
-	}, nil
+	return response, nil
+}
+
+// habitRangeStats summarises one habit over a range of dates
+type habitRangeStats struct {
+	completionRate float64
+	completions    int
+	streak         int // At the end of the range
+}
+
+// habitStatsBetween computes the habit's stats between startDate and endDate.
+// The logs must reach back to the habit's first one for the streak, and a
+// streak ending today must already be refreshed. It reports false when the
+// habit did not exist yet by endDate.
+func habitStatsBetween(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+	startDate, endDate time.Time,
+) (habitRangeStats, bool) {
+	created := clock.Date(h.CreatedAt)
+	if created.After(endDate) {
+		return habitRangeStats{}, false
+	}
+	if startDate.Before(created) {
+		startDate = created
+	}
+
+	excluded := habitDayFilter(h, pauses)
+	stats := habitRangeStats{
+		completionRate: completionRateBetween(logs, h.Frequency, h.WeeklyTarget(), excluded, clock, startDate, endDate),
+	}
+
+	completedDates := make([]time.Time, 0)
+	for _, log := range logs {
+		if !log.Completed || log.LogDate.After(endDate) {
+			continue
+		}
+		completedDates = append(completedDates, log.LogDate)
+		if !log.LogDate.Before(startDate) {
+			stats.completions++
+		}
+	}
+
+	switch {
+	case endDate.Equal(clock.Today()):
+		stats.streak = h.CurrentStreak
+	case len(completedDates) == 0:
+	case h.Frequency == habit.Daily:
+		stats.streak = calculateDailyStreak(completedDates, withExcusedDays(excluded, logs), endDate)
+	default:
+		stats.streak = calculateWeeklyStreak(completedDates, withExcusedDays(excluded, logs), clock, h.WeeklyTarget(), endDate)
+	}
+
+	return stats, true
+}
+
+// loadLogsByHabit loads the logs of the given habits up to to in one query,
+// grouped by habit
+func (s *AnalyticsService) loadLogsByHabit(
+	ctx context.Context,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	to time.Time,
+) (map[uuid.UUID][]habitlog.HabitLog, error) {
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+
+	logs, err := s.habitLogRepo.GetByHabits(ctx, userID, habitIDs, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), to)
+	if err != nil {
+		return nil, err
+	}
+
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog, len(habits))
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+	return logsByHabit, nil
 }
 
 func (s *AnalyticsService) GetStreakLeaderboard(ctx context.Context, userID uuid.UUID, limit int) (*analytics.StreakLeaderboardResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	// Get all active habits
 	habits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
 
 	// Filter out archived habits
 	activeHabits := make([]habit.Habit, 0)
@@ -558,6 +787,15 @@ func (s *AnalyticsService) GetStreakLeaderboard(ctx context.Context, userID uuid
 		return &analytics.StreakLeaderboardResponse{Data: []analytics.StreakLeaderboardDataPoint{}}, nil
 	}
 
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	// Sort by current streak (descending)
 	for i := 0; i < len(activeHabits)-1; i++ {
 		for j := i + 1; j < len(activeHabits); j++ {
@@ -589,126 +827,87 @@ func (s *AnalyticsService) GetStreakLeaderboard(ctx context.Context, userID uuid
 	}, nil
 }
 
-func (s *AnalyticsService) GetInsights(ctx context.Context, userID uuid.UUID) (*analytics.InsightsResponse, error) {
-	insights := make([]analytics.Insight, 0)
+// GetChainAnalytics reports, for each habit stacking chain, how often it was
+// completed in full and at which link it broke otherwise
+func (s *AnalyticsService) GetChainAnalytics(ctx context.Context, userID uuid.UUID, q analytics.RangeQuery) (*analytics.ChainAnalyticsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
 
-	// Get all active habits
-	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	allHabits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(allHabits, lib.ClockFromContext(ctx))
 
-	// Filter out archived habits
-	activeHabits := make([]habit.Habit, 0)
-	for _, h := range habits {
-		if h.ArchivedAt == nil {
-			activeHabits = append(activeHabits, h)
-		}
+	startDate, endDate, err := resolveRange(q, allHabits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.ChainAnalyticsResponse{
+		Range: newDateRange(startDate, endDate),
+		Data:  []analytics.ChainDataPoint{},
 	}
 
-	if len(activeHabits) == 0 {
-		return &analytics.InsightsResponse{Data: insights}, nil
+	chains := habitChains(allHabits)
+	if len(chains) == 0 {
+		return response, nil
 	}
 
-	// 1. Positive feedback - good streaks
-	bestStreak := 0
-	bestStreakHabit := ""
-	for _, h := range activeHabits {
-		if h.CurrentStreak > bestStreak {
-			bestStreak = h.CurrentStreak
-			bestStreakHabit = h.Name
-		}
+	var prevStart, prevEnd time.Time
+	logsFrom := startDate
+	if q.Compare != "" {
+		prevStart, prevEnd = comparisonRange(q.Compare, startDate, endDate)
+		logsFrom = prevStart
 	}
-	if bestStreak >= 7 {
-		insights = append(insights, analytics.Insight{
-			Type:        "positive",
-			Title:       "Great Streak!",
-			Description: bestStreakHabit + " has a " + fmt.Sprintf("%d", bestStreak) + "-day streak. Keep it up!",
-			Priority:    "high",
-		})
+
+	logs, err := s.habitLogRepo.GetByDateRange(ctx, userID, logsFrom, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
 
-	// 2. Suggestions - declining habits (low completion rate or broken streaks)
-	now := lib.NormalizeDate(time.Now().UTC())
-	thirtyDaysAgo := now.AddDate(0, 0, -30)
-	
-	for _, h := range activeHabits {
-		// Check recent completion rate
-		logs, err := s.habitLogRepo.GetByHabit(ctx, userID, h.ID, thirtyDaysAgo, now)
-		if err != nil {
-			continue
-		}
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
 
-		recentCompletions := 0
-		for _, log := range logs {
-			if log.Completed {
-				recentCompletions++
+	completed := make(map[uuid.UUID]map[string]bool)
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+		if log.Completed {
+			if completed[log.HabitID] == nil {
+				completed[log.HabitID] = make(map[string]bool)
 			}
+			completed[log.HabitID][dateKey(log.LogDate)] = true
 		}
+	}
 
-		// Calculate expected completions (rough estimate)
-		expectedCompletions := 30 // for daily habits
-		if h.Frequency == habit.Weekly {
-			expectedCompletions = 4 // roughly 4 weeks
-		}
-
-		completionRate := 0.0
-		if expectedCompletions > 0 {
-			completionRate = (float64(recentCompletions) / float64(expectedCompletions)) * 100
-		}
+	excluded := make(map[uuid.UUID]DayFilter, len(allHabits))
+	for _, h := range allHabits {
+		excluded[h.ID] = withExcusedDays(habitDayFilter(h, pauses), logsByHabit[h.ID])
+	}
 
-		// Suggest if completion rate is low or streak is broken
-		if completionRate < 50 && h.CurrentStreak == 0 {
-			insights = append(insights, analytics.Insight{
-				Type:        "suggestion",
-				Title:       "Get Back on Track",
-				Description: h.Name + " has been inactive. Try to complete it today!",
-				Priority:    "medium",
-			})
-		}
+	response.Data = make([]analytics.ChainDataPoint, len(chains))
+	for i, chain := range chains {
+		response.Data[i] = chainStats(chain, completed, excluded, startDate, endDate, clock)
 	}
 
-	// 3. Best day analysis (from day of week data)
-	dayOfWeekData, err := s.GetDayOfWeekAnalysis(ctx, userID, "30d")
-	if err == nil && len(dayOfWeekData.Data) > 0 {
-		bestDay := dayOfWeekData.Data[0]
-		for _, day := range dayOfWeekData.Data {
-			if day.CompletionRate > bestDay.CompletionRate {
-				bestDay = day
+	if q.Compare != "" {
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		for i, chain := range chains {
+			point := response.Data[i]
+			prev := chainStats(chain, completed, excluded, prevStart, prevEnd, clock)
+			response.Data[i].Deltas = map[string]analytics.Delta{
+				"daysStarted":    newDelta(float64(point.DaysStarted), float64(prev.DaysStarted)),
+				"daysCompleted":  newDelta(float64(point.DaysCompleted), float64(prev.DaysCompleted)),
+				"completionRate": newDelta(point.CompletionRate, prev.CompletionRate),
 			}
 		}
-		if bestDay.CompletionRate > 70 {
-			insights = append(insights, analytics.Insight{
-				Type:        "positive",
-				Title:       "Best Day",
-				Description: bestDay.Day + " is your most productive day with " + fmt.Sprintf("%.0f", bestDay.CompletionRate) + "% completion rate!",
-				Priority:    "low",
-			})
-		}
-	}
-
-	// 4. Overall achievement progress
-	metrics, err := s.GetMetrics(ctx, userID)
-	if err == nil {
-		if metrics.AvgCompletionRate >= 80 {
-			insights = append(insights, analytics.Insight{
-				Type:        "achievement",
-				Title:       "Excellent Consistency",
-				Description: "You're maintaining an 80%+ average completion rate across all habits!",
-				Priority:    "high",
-			})
-		} else if metrics.AvgCompletionRate < 50 {
-			insights = append(insights, analytics.Insight{
-				Type:        "suggestion",
-				Title:       "Room for Improvement",
-				Description: "Your average completion rate is below 50%. Focus on consistency!",
-				Priority:    "medium",
-			})
-		}
-	}
-
-	return &analytics.InsightsResponse{
-		Data: insights,
-	}, nil
-}
+	}
 
+	return response, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"time"
+
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/rollup"
+)
+
+const (
+	ComparePrevious = "previous"
+	CompareYearAgo  = "year_ago"
+)
+
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+	GranularityYear  = "year"
+)
+
+// maxRangeYears caps how far back an analytics query reaches
+const maxRangeYears = 5
+
+// resolveRange returns the dates an analytics query covers. Without a period
+// the range starts when the oldest habit was created; an unknown period means
+// the last 30 days. A to after today is clamped to today and ranges reach back
+// at most maxRangeYears; a from after to or after today is rejected.
+func resolveRange(q analytics.RangeQuery, habits []habit.Habit, clock lib.Clock) (time.Time, time.Time, error) {
+	today := clock.Today()
+	to := today
+	if q.To != nil && lib.NormalizeDate(*q.To).Before(today) {
+		to = lib.NormalizeDate(*q.To)
+	}
+
+	var from time.Time
+	if q.From != nil {
+		from = lib.NormalizeDate(*q.From)
+		if from.After(today) {
+			return time.Time{}, time.Time{}, errs.NewBadRequestError("from must not be in the future")
+		}
+		if from.After(to) {
+			return time.Time{}, time.Time{}, errs.NewBadRequestError("from must not be after to")
+		}
+	} else {
+		switch q.Period {
+		case "7d":
+			from = to.AddDate(0, 0, -7)
+		case "30d":
+			from = to.AddDate(0, 0, -30)
+		case "90d":
+			from = to.AddDate(0, 0, -90)
+		case "all", "":
+			from = to
+			if len(habits) > 0 {
+				if earliest := earliestHabitDate(habits, clock); earliest.Before(to) {
+					from = earliest
+				}
+			}
+		default:
+			from = to.AddDate(0, 0, -30)
+		}
+	}
+
+	if earliest := to.AddDate(-maxRangeYears, 0, 0); from.Before(earliest) {
+		from = earliest
+	}
+
+	return from, to, nil
+}
+
+// comparisonRange returns the range from to to is compared with: the range
+// of the same length just before it, or the same dates a year earlier
+func comparisonRange(compare string, from, to time.Time) (time.Time, time.Time) {
+	if compare == CompareYearAgo {
+		return from.AddDate(-1, 0, 0), to.AddDate(-1, 0, 0)
+	}
+
+	days := int(to.Sub(from).Hours()/24) + 1
+	previousTo := from.AddDate(0, 0, -1)
+	return previousTo.AddDate(0, 0, -(days - 1)), previousTo
+}
+
+// newDateRange formats an inclusive range of dates
+func newDateRange(from, to time.Time) analytics.DateRange {
+	return analytics.DateRange{From: dateKey(from), To: dateKey(to)}
+}
+
+// newDelta compares current with previous
+func newDelta(current, previous float64) analytics.Delta {
+	delta := analytics.Delta{
+		Previous: previous,
+		Change:   current - previous,
+	}
+	if previous != 0 {
+		pct := (current - previous) / previous * 100
+		delta.ChangePct = &pct
+	}
+	return delta
+}
+
+// bucketStart returns the first day of the bucket containing day
+func bucketStart(day time.Time, granularity string, clock lib.Clock) time.Time {
+	switch granularity {
+	case GranularityWeek:
+		return clock.WeekStart(day)
+	case GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	case GranularityYear:
+		return time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return day
+	}
+}
+
+// trendBuckets sums consecutive daily rollups into one data point per bucket.
+// A bucket's denominator is the daily habits scheduled on each of its days,
+// counting only the days they were active and not excused, plus the
+// weekTarget of each weekly habit for every week in the bucket. A week that
+// crosses the bucket's edge adds the part of its target that falls on the
+// bucket's days, as spread by computeDailyRollups.
+func trendBuckets(rollups []rollup.DailyRollup, granularity string, clock lib.Clock) []analytics.CompletionTrendDataPoint {
+	data := make([]analytics.CompletionTrendDataPoint, 0)
+	var current time.Time
+	for _, ru := range rollups {
+		start := bucketStart(ru.Day, granularity, clock)
+		if len(data) == 0 || !start.Equal(current) {
+			current = start
+			data = append(data, analytics.CompletionTrendDataPoint{Date: dateKey(ru.Day)})
+		}
+
+		point := &data[len(data)-1]
+		point.EndDate = dateKey(ru.Day)
+		point.Completions += ru.CompletedCount
+		point.TotalHabits += ru.ScheduledCount
+	}
+
+	for i, point := range data {
+		if point.TotalHabits > 0 {
+			data[i].CompletionRate = (float64(point.Completions) / float64(point.TotalHabits)) * 100
+		}
+	}
+
+	return data
+}
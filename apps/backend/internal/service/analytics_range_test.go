@@ -0,0 +1,126 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+)
+
+func TestResolveRange(t *testing.T) {
+	clock := weekClock("2026-05-20", time.Monday)
+
+	var oldHabit habit.Habit
+	oldHabit.CreatedAt = day("2019-03-01")
+
+	datePtr := func(value string) *time.Time {
+		d := day(value)
+		return &d
+	}
+
+	tests := []struct {
+		name     string
+		q        analytics.RangeQuery
+		habits   []habit.Habit
+		wantFrom string
+		wantTo   string
+		wantErr  bool
+	}{
+		{
+			name:     "period ending today",
+			q:        analytics.RangeQuery{Period: "7d"},
+			wantFrom: "2026-05-13",
+			wantTo:   "2026-05-20",
+		},
+		{
+			name:     "explicit range",
+			q:        analytics.RangeQuery{From: datePtr("2026-04-01"), To: datePtr("2026-04-30")},
+			wantFrom: "2026-04-01",
+			wantTo:   "2026-04-30",
+		},
+		{
+			name:     "to in the future is clamped to today",
+			q:        analytics.RangeQuery{From: datePtr("2026-05-01"), To: datePtr("2026-06-30")},
+			wantFrom: "2026-05-01",
+			wantTo:   "2026-05-20",
+		},
+		{
+			name:     "all time reaches back at most five years",
+			q:        analytics.RangeQuery{Period: "all"},
+			habits:   []habit.Habit{oldHabit},
+			wantFrom: "2021-05-20",
+			wantTo:   "2026-05-20",
+		},
+		{
+			name:     "explicit from reaches back at most five years",
+			q:        analytics.RangeQuery{From: datePtr("2010-01-01"), To: datePtr("2026-01-31")},
+			wantFrom: "2021-01-31",
+			wantTo:   "2026-01-31",
+		},
+		{
+			name:    "from in the future",
+			q:       analytics.RangeQuery{From: datePtr("2026-05-21")},
+			wantErr: true,
+		},
+		{
+			name:    "from after to",
+			q:       analytics.RangeQuery{From: datePtr("2026-05-10"), To: datePtr("2026-05-01")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := resolveRange(tt.q, tt.habits, clock)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveRange() = %s..%s, want an error", dateKey(from), dateKey(to))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRange() error = %v", err)
+			}
+			if dateKey(from) != tt.wantFrom || dateKey(to) != tt.wantTo {
+				t.Errorf("resolveRange() = %s..%s, want %s..%s", dateKey(from), dateKey(to), tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestTrendBucketsWeeklyHabit(t *testing.T) {
+	clock := weekClock("2026-06-01", time.Monday)
+	h := weeklyHabit(3)
+
+	t.Run("week meeting its target", func(t *testing.T) {
+		logs := completedLogs("2026-05-04", "2026-05-06", "2026-05-09")
+		rollups := computeDailyRollups(uuid.Nil, []habit.Habit{h}, logs, NewPauseSchedule(nil), clock, day("2026-05-04"), day("2026-05-10"))
+
+		data := trendBuckets(rollups, GranularityWeek, clock)
+		if len(data) != 1 {
+			t.Fatalf("trendBuckets() returned %d buckets, want 1", len(data))
+		}
+		if data[0].TotalHabits != 3 || data[0].Completions != 3 || data[0].CompletionRate != 100 {
+			t.Errorf("week bucket = %d of %d, %v%%, want 3 of 3, 100%%",
+				data[0].Completions, data[0].TotalHabits, data[0].CompletionRate)
+		}
+	})
+
+	t.Run("week across a month edge", func(t *testing.T) {
+		// The week of April 27 ends on May 3; the four weeks after it are in May
+		rollups := computeDailyRollups(uuid.Nil, []habit.Habit{h}, nil, NewPauseSchedule(nil), clock, day("2026-04-27"), day("2026-05-31"))
+
+		data := trendBuckets(rollups, GranularityMonth, clock)
+		if len(data) != 2 {
+			t.Fatalf("trendBuckets() returned %d buckets, want 2", len(data))
+		}
+		if data[0].TotalHabits == 0 || data[0].TotalHabits >= 3 {
+			t.Errorf("April bucket = %d scheduled, want part of one week's target", data[0].TotalHabits)
+		}
+		if total := data[0].TotalHabits + data[1].TotalHabits; total != 15 {
+			t.Errorf("buckets = %d scheduled, want 15 for five weeks", total)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/reche13/habitum/internal/lib"
 	"github.com/reche13/habitum/internal/model/calendar"
+	"github.com/reche13/habitum/internal/model/habit"
 	"github.com/reche13/habitum/internal/model/habitlog"
 	"github.com/reche13/habitum/internal/repository"
 )
@@ -15,11 +16,17 @@ type CalendarService struct {
 	*BaseService
 	habitRepo    *repository.HabitRepository
 	habitLogRepo *repository.HabitLogRepository
+	pauseRepo    *repository.PauseRepository
+	userRepo     *repository.UserRepository
+	rollupRepo   *repository.DailyRollupRepository
 }
 
 func NewCalendarService(
 	habitRepo *repository.HabitRepository,
 	habitLogRepo *repository.HabitLogRepository,
+	pauseRepo *repository.PauseRepository,
+	userRepo *repository.UserRepository,
+	rollupRepo *repository.DailyRollupRepository,
 ) *CalendarService {
 	return &CalendarService{
 		BaseService: &BaseService{
@@ -27,6 +34,9 @@ func NewCalendarService(
 		},
 		habitRepo:    habitRepo,
 		habitLogRepo: habitLogRepo,
+		pauseRepo:    pauseRepo,
+		userRepo:     userRepo,
+		rollupRepo:   rollupRepo,
 	}
 }
 
@@ -45,7 +55,7 @@ func (s *CalendarService) GetCompletions(
 		return nil, s.wrapError(err)
 	}
 
-	// Filter by habit IDs if provided, and only completed logs
+	// Filter by habit IDs if provided
 	logs := make([]habitlog.HabitLog, 0)
 	if len(habitIDs) > 0 {
 		habitIDMap := make(map[uuid.UUID]bool)
@@ -53,17 +63,12 @@ func (s *CalendarService) GetCompletions(
 			habitIDMap[id] = true
 		}
 		for _, log := range allLogs {
-			if habitIDMap[log.HabitID] && log.Completed {
+			if habitIDMap[log.HabitID] {
 				logs = append(logs, log)
 			}
 		}
 	} else {
-		// Only completed logs
-		for _, log := range allLogs {
-			if log.Completed {
-				logs = append(logs, log)
-			}
-		}
+		logs = allLogs
 	}
 
 	// Get habits for habit info
@@ -71,6 +76,7 @@ func (s *CalendarService) GetCompletions(
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(allHabits, lib.ClockFromContext(ctx))
 
 	habitMap := make(map[uuid.UUID]struct {
 		ID    uuid.UUID
@@ -78,8 +84,13 @@ func (s *CalendarService) GetCompletions(
 		Color *string
 		Icon  *string
 	})
+	// Challenges only show up on days inside their window
+	challenges := make(map[uuid.UUID]habit.Habit)
 	for _, h := range allHabits {
 		if h.ArchivedAt == nil {
+			if h.IsChallenge() {
+				challenges[h.ID] = h
+			}
 			habitMap[h.ID] = struct {
 				ID    uuid.UUID
 				Name  string
@@ -105,13 +116,23 @@ func (s *CalendarService) GetCompletions(
 		habitMap = filteredHabits
 	}
 
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	// Group logs by date
 	completionsByDate := make(map[string][]uuid.UUID) // date -> habit IDs
+	statusesByDate := make(map[string]map[uuid.UUID]habitlog.Status)
 	for _, log := range logs {
+		dateKey := log.LogDate.Format("2006-01-02")
 		if log.Completed {
-			dateKey := log.LogDate.Format("2006-01-02")
 			completionsByDate[dateKey] = append(completionsByDate[dateKey], log.HabitID)
 		}
+		if statusesByDate[dateKey] == nil {
+			statusesByDate[dateKey] = make(map[uuid.UUID]habitlog.Status)
+		}
+		statusesByDate[dateKey][log.HabitID] = log.Status
 	}
 
 	// Build completion days
@@ -119,6 +140,7 @@ func (s *CalendarService) GetCompletions(
 	currentDate := normalizedStart
 	totalCompletions := 0
 	daysWithCompletions := 0
+	expectedCompletions := 0
 
 	for !currentDate.After(normalizedEnd) {
 		dateKey := currentDate.Format("2006-01-02")
@@ -127,10 +149,14 @@ func (s *CalendarService) GetCompletions(
 		// Count active habits on this date
 		totalHabits := 0
 		completedHabits := make([]calendar.HabitInfo, 0)
+		statuses := make(map[string]habitlog.Status)
 
 		for habitID, habitInfo := range habitMap {
-			// Check if habit was active on this date (simplified - assume all habits are active)
-			totalHabits++
+			if c, ok := challenges[habitID]; ok && !c.InChallengeWindow(currentDate) {
+				continue
+			}
+
+			completed := false
 			for _, completedID := range completedHabitIDs {
 				if completedID == habitID {
 					completedHabits = append(completedHabits, calendar.HabitInfo{
@@ -139,10 +165,23 @@ func (s *CalendarService) GetCompletions(
 						Color: lib.GetStringValue(habitInfo.Color),
 						Icon:  lib.GetStringValue(habitInfo.Icon),
 					})
+					completed = true
 					break
 				}
 			}
+
+			status, logged := statusesByDate[dateKey][habitID]
+			if logged {
+				statuses[habitID.String()] = status
+			}
+
+			// Paused and excused habits are not expected on this date unless done anyway
+			excused := logged && status.IsExcused()
+			if completed || (!excused && !pauses.IsPaused(habitID, currentDate)) {
+				totalHabits++
+			}
 		}
+		expectedCompletions += totalHabits
 
 		completionRate := 0.0
 		if totalHabits > 0 {
@@ -160,6 +199,7 @@ func (s *CalendarService) GetCompletions(
 			CompletionRate:  completionRate,
 			TotalHabits:     totalHabits,
 			CompletedHabits: len(completedHabits),
+			Statuses:        statuses,
 		})
 
 		currentDate = currentDate.AddDate(0, 0, 1)
@@ -168,11 +208,8 @@ func (s *CalendarService) GetCompletions(
 	// Calculate statistics
 	totalDays := int(normalizedEnd.Sub(normalizedStart).Hours()/24) + 1
 	overallCompletionRate := 0.0
-	if totalDays > 0 && len(habitMap) > 0 {
-		expectedCompletions := totalDays * len(habitMap)
-		if expectedCompletions > 0 {
-			overallCompletionRate = (float64(totalCompletions) / float64(expectedCompletions)) * 100
-		}
+	if expectedCompletions > 0 {
+		overallCompletionRate = (float64(totalCompletions) / float64(expectedCompletions)) * 100
 	}
 
 	return &calendar.CompletionsResponse{
@@ -213,6 +250,7 @@ func (s *CalendarService) GetMonth(
 			Date:           completion.Date,
 			Completions:    habitIDStrings,
 			CompletionRate: completion.CompletionRate,
+			Statuses:       completion.Statuses,
 		})
 	}
 
@@ -231,6 +269,12 @@ func (s *CalendarService) GetWeek(
 	week int,
 	habitIDs []uuid.UUID,
 ) (*calendar.WeekResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
 	// Calculate start date of week (Monday)
 	date := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC) // Jan 4 is always in week 1
 	dateYear, dateWeek := date.ISOWeek()
@@ -248,8 +292,10 @@ func (s *CalendarService) GetWeek(
 		date = date.AddDate(0, 0, -1)
 	}
 
-	startDate := lib.NormalizeDate(date)
-	endDate := startDate.AddDate(0, 0, 6) // Sunday
+	// Users whose week starts on another day see the week that starts on
+	// or just before that Monday
+	startDate := clock.WeekStart(date)
+	endDate := startDate.AddDate(0, 0, 6)
 
 	completions, err := s.GetCompletions(ctx, userID, startDate, endDate, habitIDs)
 	if err != nil {
@@ -260,11 +306,7 @@ func (s *CalendarService) GetWeek(
 	days := make([]calendar.DayData, 0)
 	for _, completion := range completions.Completions {
 		parsedDate, _ := time.Parse("2006-01-02", completion.Date)
-		weekday := int(parsedDate.Weekday())
-		if weekday == 0 {
-			weekday = 7 // Sunday becomes 7
-		}
-		dayOfWeek := weekday - 1 // Monday = 0, Sunday = 6
+		dayOfWeek := clock.WeekdayIndex(parsedDate)
 
 		habitIDStrings := make([]string, 0)
 		for _, habit := range completion.Habits {
@@ -276,6 +318,7 @@ func (s *CalendarService) GetWeek(
 			DayOfWeek:      dayOfWeek,
 			Completions:    habitIDStrings,
 			CompletionRate: completion.CompletionRate,
+			Statuses:       completion.Statuses,
 		})
 	}
 
@@ -294,11 +337,26 @@ func (s *CalendarService) GetYear(
 	habitIDs []uuid.UUID,
 ) (*calendar.YearResponse, error) {
 	startDate := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := time.Date(year, 12, 31, 23, 59, 59, 0, time.UTC)
+	endDate := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
 
-	completions, err := s.GetCompletions(ctx, userID, startDate, endDate, habitIDs)
-	if err != nil {
-		return nil, err
+	// A filtered heatmap needs per-habit logs; the full one reads the rollups
+	var completions *calendar.CompletionsResponse
+	if len(habitIDs) > 0 {
+		var err error
+		completions, err = s.GetCompletions(ctx, userID, startDate, endDate, habitIDs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		ctx, err := withUserClock(ctx, s.userRepo, userID)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+
+		completions, err = s.getRollupCompletions(ctx, userID, startDate, endDate)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
 	}
 
 	// Convert to heatmap format
@@ -333,4 +391,51 @@ func (s *CalendarService) GetYear(
 	}, nil
 }
 
+// getRollupCompletions builds per-day totals and period statistics from the
+// daily rollups. The days carry counts only, without the habits behind them.
+func (s *CalendarService) getRollupCompletions(
+	ctx context.Context,
+	userID uuid.UUID,
+	startDate, endDate time.Time,
+) (*calendar.CompletionsResponse, error) {
+	rollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
 
+	completionDays := make([]calendar.CompletionDay, 0, len(rollups))
+	totalCompletions := 0
+	daysWithCompletions := 0
+	expectedCompletions := 0
+	for _, ru := range rollups {
+		if ru.CompletedCount > 0 {
+			daysWithCompletions++
+			totalCompletions += ru.CompletedCount
+		}
+		expectedCompletions += ru.ScheduledCount
+
+		completionDays = append(completionDays, calendar.CompletionDay{
+			Date:            dateKey(ru.Day),
+			Habits:          []calendar.HabitInfo{},
+			CompletionRate:  ru.CompletionRate(),
+			TotalHabits:     ru.ScheduledCount,
+			CompletedHabits: ru.CompletedCount,
+			Statuses:        map[string]habitlog.Status{},
+		})
+	}
+
+	overallCompletionRate := 0.0
+	if expectedCompletions > 0 {
+		overallCompletionRate = (float64(totalCompletions) / float64(expectedCompletions)) * 100
+	}
+
+	return &calendar.CompletionsResponse{
+		Completions: completionDays,
+		Statistics: calendar.PeriodStats{
+			TotalCompletions:    totalCompletions,
+			DaysWithCompletions: daysWithCompletions,
+			CompletionRate:      overallCompletionRate,
+			TotalDays:           len(rollups),
+		},
+	}, nil
+}
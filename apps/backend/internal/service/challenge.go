@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// challengeSuccessThreshold is the share of scheduled periods that must be
+// completed for a challenge to count as a success
+const challengeSuccessThreshold = 0.8
+
+// GetChallengeProgress reports how far a time-boxed habit is through its
+// challenge and how likely it is to be finished successfully
+func (s *HabitService) GetChallengeProgress(
+	ctx context.Context,
+	habitID uuid.UUID,
+	userID uuid.UUID,
+) (*habit.ChallengeProgress, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	h, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if !h.IsChallenge() {
+		return nil, errs.NewBadRequestError("Habit is not a challenge")
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logs, err := s.habitLogService.habitLogRepo.GetByHabit(ctx, userID, h.ID, *h.StartDate, *h.EndDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	excluded := withExcusedDays(habitDayFilter(*h, pauses), logs)
+	progress := challengeProgress(*h, logs, excluded, lib.ClockFromContext(ctx))
+
+	return &progress, nil
+}
+
+// challengeProgress computes the progress of a challenge as of the clock's
+// today, counting weeks from the clock's week start
+func challengeProgress(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	excluded DayFilter,
+	clock lib.Clock,
+) habit.ChallengeProgress {
+	today := clock.Today()
+	start := lib.NormalizeDate(*h.StartDate)
+	end := lib.NormalizeDate(*h.EndDate)
+
+	completedDates := make([]time.Time, 0)
+	for _, log := range logs {
+		if log.Completed && h.InChallengeWindow(log.LogDate) {
+			completedDates = append(completedDates, log.LogDate)
+		}
+	}
+	completed := dateSet(completedDates)
+
+	progress := habit.ChallengeProgress{
+		HabitID:          h.ID.String(),
+		StartDate:        dateKey(start),
+		EndDate:          dateKey(end),
+		SuccessThreshold: challengeSuccessThreshold * 100,
+	}
+
+	// classify sorts a period into done, excused, still to come or missed
+	classify := func(done, isExcluded bool, lastDay time.Time) {
+		progress.TotalPeriods++
+		switch {
+		case done:
+			progress.PeriodsDone++
+		case isExcluded:
+			progress.PeriodsExcused++
+		case !lastDay.Before(today):
+			progress.PeriodsRemaining++
+		default:
+			progress.PeriodsMissed++
+		}
+	}
+
+	if h.Frequency == habit.Daily {
+		progress.Unit = "day"
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			classify(completed[dateKey(day)], excluded(day), day)
+		}
+	} else {
+		progress.Unit = "week"
+		counts := weeklyCounts(completedDates, clock)
+		for week := clock.WeekStart(start); !week.After(end); week = week.AddDate(0, 0, 7) {
+			met := isWeekMet(counts, week, h.WeeklyTarget(), excluded)
+			classify(met, isWeekExcluded(week, excluded), week.AddDate(0, 0, 6))
+		}
+	}
+
+	eligible := progress.TotalPeriods - progress.PeriodsExcused
+	required := int(math.Ceil(challengeSuccessThreshold * float64(eligible)))
+	progress.PeriodsNeeded = required - progress.PeriodsDone
+	if progress.PeriodsNeeded < 0 {
+		progress.PeriodsNeeded = 0
+	}
+
+	if !today.After(end) {
+		from := today
+		if from.Before(start) {
+			from = start
+		}
+		progress.DaysRemaining = int(end.Sub(from).Hours()/24) + 1
+	}
+
+	// Estimate the chance of completing each remaining period from the
+	// record so far, smoothed so a short history does not give 0% or 100%
+	decided := progress.PeriodsDone + progress.PeriodsMissed
+	p := float64(progress.PeriodsDone+1) / float64(decided+2)
+	progress.SuccessProbability = binomialAtLeast(progress.PeriodsRemaining, progress.PeriodsNeeded, p) * 100
+
+	switch {
+	case today.Before(start):
+		progress.Status = habit.ChallengeUpcoming
+	case !today.After(end):
+		progress.Status = habit.ChallengeActive
+	case progress.PeriodsNeeded == 0:
+		progress.Status = habit.ChallengeSucceeded
+	default:
+		progress.Status = habit.ChallengeFailed
+	}
+
+	return progress
+}
+
+// binomialAtLeast returns the probability of at least k successes in n
+// independent trials with success probability p
+func binomialAtLeast(n, k int, p float64) float64 {
+	if k <= 0 {
+		return 1
+	}
+	if k > n || p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return 1
+	}
+
+	lgN, _ := math.Lgamma(float64(n + 1))
+	total := 0.0
+	for i := k; i <= n; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgRest, _ := math.Lgamma(float64(n - i + 1))
+		total += math.Exp(lgN - lgI - lgRest + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p))
+	}
+
+	return math.Min(total, 1)
+}
+
+// habitDayFilter excludes the days a habit is paused and, for challenges,
+// every day outside the challenge window
+func habitDayFilter(h habit.Habit, pauses *PauseSchedule) DayFilter {
+	paused := pauses.ForHabit(h.ID)
+	if !h.IsChallenge() {
+		return paused
+	}
+
+	return func(date time.Time) bool {
+		return !h.InChallengeWindow(date) || paused(date)
+	}
+}
+
+// habitActiveDayFilter extends habitDayFilter with the days before the habit
+// was created or after it was archived, and the days excused by its logs
+func habitActiveDayFilter(h habit.Habit, pauses *PauseSchedule, logs []habitlog.HabitLog, clock lib.Clock) DayFilter {
+	excluded := habitDayFilter(h, pauses)
+	return withExcusedDays(func(date time.Time) bool {
+		return !isHabitActiveOn(clock, h, date) || excluded(date)
+	}, logs)
+}
+
+// storeEndedChallenges stores archived_at on the user's challenges that ended
+// before the clock's today, as archiveIfEnded reads it. Writes call it on
+// their transaction and the archive-challenges command for every user, so
+// habits listed without archiveEndedChallenges see them archived as well.
+func storeEndedChallenges(ctx context.Context, habitRepo *repository.HabitRepository, userID uuid.UUID) error {
+	clock := lib.ClockFromContext(ctx)
+	return habitRepo.ArchiveEndedChallenges(ctx, userID, clock.Today(), clock.Location().String(), clock.DayEndsAt())
+}
+
+// ArchiveEndedChallenges stores the archive time of the user's challenges
+// that ended before today, for challenges not archived by a write since
+func (s *HabitService) ArchiveEndedChallenges(ctx context.Context, userID uuid.UUID) error {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if err := storeEndedChallenges(ctx, s.habitRepo, userID); err != nil {
+		return s.wrapError(err)
+	}
+	return nil
+}
+
+// archiveEndedChallenges reads the challenges that ended before the clock's
+// today as archived, without storing it, so reads never write and still see
+// challenges that ended since storeEndedChallenges last ran
+func archiveEndedChallenges(habits []habit.Habit, clock lib.Clock) {
+	for i := range habits {
+		archiveIfEnded(&habits[i], clock)
+	}
+}
+
+// archiveIfEnded reads a challenge that ended before the clock's today as
+// archived as of the start of the day after its end date, in the clock's
+// time zone and with its day ending dayEndsAt hours after midnight
+func archiveIfEnded(h *habit.Habit, clock lib.Clock) {
+	if h.ArchivedAt != nil || h.EndDate == nil || !lib.NormalizeDate(*h.EndDate).Before(clock.Today()) {
+		return
+	}
+
+	next := lib.NormalizeDate(*h.EndDate).AddDate(0, 0, 1)
+	archivedAt := time.Date(next.Year(), next.Month(), next.Day(), clock.DayEndsAt(), 0, 0, 0, clock.Location())
+	h.ArchivedAt = &archivedAt
+}
+
+// resolveChallengeWindow turns the requested start date, end date and
+// duration into a challenge window. It returns nil when neither an end date
+// nor a duration is given.
+func resolveChallengeWindow(startDate, endDate *string, durationDays *int, today time.Time) (*habit.ChallengeWindow, error) {
+	if endDate == nil && durationDays == nil {
+		if startDate != nil {
+			return nil, errs.NewBadRequestError("A challenge needs an end date or a duration")
+		}
+		return nil, nil
+	}
+
+	start := today
+	if startDate != nil {
+		parsed, err := time.Parse("2006-01-02", *startDate)
+		if err != nil {
+			return nil, errs.NewBadRequestError("Invalid start_date format. Use YYYY-MM-DD")
+		}
+		start = parsed
+	}
+
+	var end time.Time
+	if endDate != nil {
+		parsed, err := time.Parse("2006-01-02", *endDate)
+		if err != nil {
+			return nil, errs.NewBadRequestError("Invalid end_date format. Use YYYY-MM-DD")
+		}
+		end = parsed
+	} else {
+		end = start.AddDate(0, 0, *durationDays-1)
+	}
+
+	if end.Before(start) {
+		return nil, errs.NewBadRequestError("end_date must not be before start_date")
+	}
+
+	return &habit.ChallengeWindow{
+		StartDate: lib.NormalizeDate(start),
+		EndDate:   lib.NormalizeDate(end),
+	}, nil
+}
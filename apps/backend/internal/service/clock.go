@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+type userClockKey struct{}
+
+// withUserClock moves the request clock into the user's time zone and day
+// boundaries, so that "today", every date derived from an instant and every
+// week follow the user's preferences instead of UTC and ISO weeks. Unknown
+// users keep the default clock. Calling it again
+// for the same user is a no-op, which lets services call each other freely.
+func withUserClock(ctx context.Context, userRepo *repository.UserRepository, userID uuid.UUID) (context.Context, error) {
+	if id, ok := ctx.Value(userClockKey{}).(uuid.UUID); ok && id == userID {
+		return ctx, nil
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	u, err := userRepo.GetByID(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return ctx, err
+	}
+	if err == nil {
+		clock = clock.In(u.Location()).WithDayBoundaries(u.FirstWeekday(), u.DayEndsAt)
+	}
+
+	ctx = lib.WithClock(ctx, clock)
+	return context.WithValue(ctx, userClockKey{}, userID), nil
+}
+
+// dateOrToday normalizes date, reading a zero date as today on the request
+// clock
+func dateOrToday(ctx context.Context, date time.Time) time.Time {
+	if date.IsZero() {
+		return lib.ClockFromContext(ctx).Today()
+	}
+	return lib.NormalizeDate(date)
+}
+
+// stampCompletion records on a completion payload when it was logged and the
+// user's time zone. A completion logged for an earlier day, without a time
+// given, has no known time.
+func stampCompletion(ctx context.Context, payload *habitlog.HabitLogPayload) {
+	if payload.ResolvedStatus() != habitlog.Completed {
+		payload.CompletedAt = nil
+		return
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	if payload.CompletedAt == nil {
+		if !lib.NormalizeDate(payload.LogDate).Equal(clock.Today()) {
+			return
+		}
+		now := clock.Now()
+		payload.CompletedAt = &now
+	}
+
+	tz := clock.Location().String()
+	payload.CompletedTimezone = &tz
+}
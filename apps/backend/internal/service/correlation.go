@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+const (
+	CorrelationSameDay = "same_day"
+	CorrelationNextDay = "next_day"
+)
+
+// Pairs with too little data, or where either habit was almost always (or
+// never) done, say nothing about each other and are left out
+const (
+	minCorrelationSamples     = 21  // Days both habits were scheduled
+	minCorrelationOutcomes    = 3   // Completions and misses of each habit within those days
+	minCorrelationCoefficient = 0.2 // Weaker relationships are not reported
+)
+
+// Day outcomes of a habit, in the order of the range's days
+const (
+	dayNotScheduled = iota
+	dayMissed
+	dayCompleted
+)
+
+// habitOutcomes returns the habit's outcome on each day from from to to. Days
+// the habit was inactive, paused, skipped or frozen are not scheduled.
+func habitOutcomes(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	excluded DayFilter,
+	clock lib.Clock,
+	from, to time.Time,
+) []int {
+	completed := make(map[string]bool)
+	for _, log := range logs {
+		if log.Completed {
+			completed[dateKey(log.LogDate)] = true
+		}
+	}
+
+	outcomes := make([]int, 0)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		switch {
+		case completed[dateKey(day)]:
+			outcomes = append(outcomes, dayCompleted)
+		case !isHabitActiveOn(clock, h, day) || excluded(day):
+			outcomes = append(outcomes, dayNotScheduled)
+		default:
+			outcomes = append(outcomes, dayMissed)
+		}
+	}
+	return outcomes
+}
+
+// correlate compares a's outcome on each day with b's outcome lag days later,
+// over the days both were scheduled. It reports false when the pair does not
+// meet the sample thresholds.
+func correlate(a, b []int, lag int) (analytics.CorrelationDataPoint, bool) {
+	// counts[x][y]: a's outcome x (0 missed, 1 completed) against b's outcome y
+	var counts [2][2]int
+	samples := 0
+	for i := 0; i+lag < len(b); i++ {
+		if a[i] == dayNotScheduled || b[i+lag] == dayNotScheduled {
+			continue
+		}
+		x, y := 0, 0
+		if a[i] == dayCompleted {
+			x = 1
+		}
+		if b[i+lag] == dayCompleted {
+			y = 1
+		}
+		counts[x][y]++
+		samples++
+	}
+
+	aDone, aMissed := counts[1][0]+counts[1][1], counts[0][0]+counts[0][1]
+	bDone, bMissed := counts[0][1]+counts[1][1], counts[0][0]+counts[1][0]
+	if samples < minCorrelationSamples ||
+		aDone < minCorrelationOutcomes || aMissed < minCorrelationOutcomes ||
+		bDone < minCorrelationOutcomes || bMissed < minCorrelationOutcomes {
+		return analytics.CorrelationDataPoint{}, false
+	}
+
+	numerator := float64(counts[1][1]*counts[0][0] - counts[1][0]*counts[0][1])
+	denominator := math.Sqrt(float64(aDone) * float64(aMissed) * float64(bDone) * float64(bMissed))
+
+	return analytics.CorrelationDataPoint{
+		Coefficient: numerator / denominator,
+		Samples:     samples,
+		RateWith:    (float64(counts[1][1]) / float64(aDone)) * 100,
+		RateWithout: (float64(counts[0][1]) / float64(aMissed)) * 100,
+	}, true
+}
+
+// GetCorrelations finds the strongest same-day and next-day relationships
+// between the user's habits over the range, up to limit of each sign. Today
+// is left out since its outcomes are not final yet.
+func (s *AnalyticsService) GetCorrelations(
+	ctx context.Context,
+	userID uuid.UUID,
+	q analytics.RangeQuery,
+	limit int,
+) (*analytics.CorrelationsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	// Filter out archived habits
+	activeHabits := make([]habit.Habit, 0)
+	for _, h := range habits {
+		if h.ArchivedAt == nil {
+			activeHabits = append(activeHabits, h)
+		}
+	}
+
+	startDate, endDate, err := resolveRange(q, activeHabits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.CorrelationsResponse{
+		Range:    newDateRange(startDate, endDate),
+		Positive: []analytics.CorrelationDataPoint{},
+		Negative: []analytics.CorrelationDataPoint{},
+	}
+
+	if yesterday := clock.Today().AddDate(0, 0, -1); endDate.After(yesterday) {
+		endDate = yesterday
+	}
+	if len(activeHabits) < 2 || endDate.Before(startDate) {
+		return response, nil
+	}
+
+	logs, err := s.habitLogRepo.GetByDateRange(ctx, userID, startDate, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	outcomes := make([][]int, len(activeHabits))
+	for i, h := range activeHabits {
+		excluded := withExcusedDays(habitDayFilter(h, pauses), logsByHabit[h.ID])
+		outcomes[i] = habitOutcomes(h, logsByHabit[h.ID], excluded, clock, startDate, endDate)
+	}
+
+	correlations := make([]analytics.CorrelationDataPoint, 0)
+	add := func(kind string, a, b int, lag int) {
+		point, ok := correlate(outcomes[a], outcomes[b], lag)
+		if !ok || math.Abs(point.Coefficient) < minCorrelationCoefficient {
+			return
+		}
+		point.Type = kind
+		point.HabitID = activeHabits[a].ID.String()
+		point.HabitName = activeHabits[a].Name
+		point.OtherHabitID = activeHabits[b].ID.String()
+		point.OtherHabitName = activeHabits[b].Name
+		correlations = append(correlations, point)
+	}
+
+	for a := range activeHabits {
+		for b := range activeHabits {
+			if a == b {
+				continue
+			}
+			// Same-day relationships are symmetric, so each pair is checked once
+			if a < b {
+				add(CorrelationSameDay, a, b, 0)
+			}
+			add(CorrelationNextDay, a, b, 1)
+		}
+	}
+
+	sort.Slice(correlations, func(i, j int) bool {
+		return math.Abs(correlations[i].Coefficient) > math.Abs(correlations[j].Coefficient)
+	})
+
+	for _, point := range correlations {
+		if point.Coefficient > 0 && (limit <= 0 || len(response.Positive) < limit) {
+			response.Positive = append(response.Positive, point)
+		}
+		if point.Coefficient < 0 && (limit <= 0 || len(response.Negative) < limit) {
+			response.Negative = append(response.Negative, point)
+		}
+	}
+
+	return response, nil
+}
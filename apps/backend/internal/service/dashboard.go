@@ -9,6 +9,7 @@ import (
 	"github.com/reche13/habitum/internal/lib"
 	"github.com/reche13/habitum/internal/model/dashboard"
 	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
 	"github.com/reche13/habitum/internal/repository"
 )
 
@@ -16,11 +17,21 @@ type DashboardService struct {
 	*BaseService
 	habitRepo    *repository.HabitRepository
 	habitLogRepo *repository.HabitLogRepository
+	routineRepo  *repository.RoutineRepository
+	pauseRepo    *repository.PauseRepository
+	userRepo     *repository.UserRepository
+	strengthRepo *repository.HabitStrengthRepository
+	achievementRepo *repository.AchievementRepository
 }
 
 func NewDashboardService(
 	habitRepo *repository.HabitRepository,
 	habitLogRepo *repository.HabitLogRepository,
+	routineRepo *repository.RoutineRepository,
+	pauseRepo *repository.PauseRepository,
+	userRepo *repository.UserRepository,
+	strengthRepo *repository.HabitStrengthRepository,
+	achievementRepo *repository.AchievementRepository,
 ) *DashboardService {
 	return &DashboardService{
 		BaseService: &BaseService{
@@ -28,26 +39,53 @@ func NewDashboardService(
 		},
 		habitRepo:    habitRepo,
 		habitLogRepo: habitLogRepo,
+		routineRepo:  routineRepo,
+		pauseRepo:    pauseRepo,
+		userRepo:     userRepo,
+		strengthRepo: strengthRepo,
+		achievementRepo: achievementRepo,
 	}
 }
 
 func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dashboard.DashboardResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	today := lib.ClockFromContext(ctx).Today()
+
 	// Get all active habits
 	allHabits, _, err := s.habitRepo.List(ctx, userID, nil)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveEndedChallenges(allHabits, lib.ClockFromContext(ctx))
 
-	// Filter out archived habits
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	// Filter out archived habits, habits paused today and challenges that
+	// have not started yet
 	activeHabits := make([]habit.Habit, 0)
 	for _, h := range allHabits {
-		if h.ArchivedAt == nil {
+		if h.ArchivedAt == nil && h.InChallengeWindow(today) && !pauses.IsPaused(h.ID, today) {
 			activeHabits = append(activeHabits, h)
 		}
 	}
 
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	achievements, err := s.recentAchievements(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(activeHabits) == 0 {
-		today := lib.NormalizeDate(time.Now().UTC())
 		return &dashboard.DashboardResponse{
 			Today: dashboard.TodayStats{
 				Date:          today.Format("2006-01-02"),
@@ -57,18 +95,20 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 			},
 			HabitsToComplete: []dashboard.HabitSummary{},
 			HabitsCompleted:  []dashboard.HabitSummary{},
+			UpNext:           []dashboard.HabitSummary{},
+			Routines:         []dashboard.RoutineSummary{},
 			ActiveStreaks:    []dashboard.StreakSummary{},
+			StreaksAtRisk:    []dashboard.HabitSummary{},
 			QuickStats: dashboard.QuickStats{
 				TodayRate:     0,
 				ThisWeek:      0,
 				LongestStreak: 0,
 				TotalHabits:   0,
 			},
-			Achievements: []dashboard.AchievementSummary{},
+			Achievements: achievements,
 		}, nil
 	}
 
-	today := lib.NormalizeDate(time.Now().UTC())
 	todayStr := today.Format("2006-01-02")
 
 	// Get today's logs
@@ -88,30 +128,36 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 	}
 
 	// Get this week's logs for quick stats
-	now := lib.NormalizeDate(time.Now().UTC())
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
-	}
-	weekStart := now.AddDate(0, 0, -(weekday - 1))
+	weekStart := lib.ClockFromContext(ctx).WeekStart(today)
 	weekEnd := weekStart.AddDate(0, 0, 6)
 	weekLogs, err := s.habitLogRepo.GetByDateRange(ctx, userID, weekStart, weekEnd)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
 
-	// Count completions this week
+	// Count completions this week, overall and per habit
 	completionsThisWeek := 0
+	weekCompletionsByHabit := make(map[uuid.UUID]int)
+	weekLogsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
 	for _, log := range weekLogs {
+		weekLogsByHabit[log.HabitID] = append(weekLogsByHabit[log.HabitID], log)
 		if log.Completed {
 			completionsThisWeek++
+			weekCompletionsByHabit[log.HabitID]++
 		}
 	}
 
+	predictions, err := predictToday(ctx, s.habitLogRepo, s.strengthRepo, userID, activeHabits, pauses)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	// Build habit summaries
 	habitsToComplete := make([]dashboard.HabitSummary, 0)
 	habitsCompleted := make([]dashboard.HabitSummary, 0)
+	upNext := make([]dashboard.HabitSummary, 0)
 	activeStreaks := make([]dashboard.StreakSummary, 0)
+	streaksAtRisk := make([]dashboard.HabitSummary, 0)
 
 	totalCompleted := 0
 	longestStreak := 0
@@ -125,12 +171,23 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 			totalCompleted++
 		}
 
-		// Get current streak (use stored value for now, could calculate if needed)
 		currentStreak := h.CurrentStreak
 		if h.LongestStreak > longestStreak {
 			longestStreak = h.LongestStreak
 		}
 
+		// A weekly habit that already met this week's target is done for today
+		weeklyTarget := 0
+		done := completedToday
+		if h.Frequency == habit.Weekly {
+			excluded := withExcusedDays(habitDayFilter(h, pauses), weekLogsByHabit[h.ID])
+			weeklyTarget = weekTarget(weekStart, h.WeeklyTarget(), excluded)
+			if !completedToday && weekCompletionsByHabit[h.ID] > 0 && weekCompletionsByHabit[h.ID] >= weeklyTarget {
+				done = true
+				totalCompleted++
+			}
+		}
+
 		habitSummary := dashboard.HabitSummary{
 			ID:             h.ID.String(),
 			Name:           h.Name,
@@ -143,12 +200,27 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 			CurrentStreak:  currentStreak,
 			CompletedToday: completedToday,
 			CompletedTodayAt: completedTodayAt,
+			CompletedThisWeek: weekCompletionsByHabit[h.ID],
+			WeeklyTarget:   weeklyTarget,
+		}
+		if prediction, ok := predictions[h.ID]; ok {
+			likelihood := prediction.Probability
+			habitSummary.Likelihood = &likelihood
+			habitSummary.StreakAtRisk = prediction.StreakAtRisk
 		}
 
-		if completedToday {
+		if done {
 			habitsCompleted = append(habitsCompleted, habitSummary)
 		} else {
 			habitsToComplete = append(habitsToComplete, habitSummary)
+			if habitSummary.StreakAtRisk {
+				streaksAtRisk = append(streaksAtRisk, habitSummary)
+			}
+
+			// Suggest habits whose anchor habit is already done today
+			if h.StackAfterID != nil && completedTodayMap[*h.StackAfterID] {
+				upNext = append(upNext, habitSummary)
+			}
 		}
 
 		// Add to active streaks if streak > 0
@@ -174,6 +246,17 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 		activeStreaks = activeStreaks[:5]
 	}
 
+	// Sort streaks at risk by likelihood (ascending)
+	sort.SliceStable(streaksAtRisk, func(i, j int) bool {
+		return *streaksAtRisk[i].Likelihood < *streaksAtRisk[j].Likelihood
+	})
+
+	// Build routine progress from the habits and logs loaded above
+	routines, err := s.buildRoutineSummaries(ctx, userID, activeHabits, completedTodayMap, today)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate today's completion rate
 	totalCount := len(activeHabits)
 	completionRate := 0.0
@@ -191,15 +274,106 @@ func (s *DashboardService) GetHome(ctx context.Context, userID uuid.UUID) (*dash
 		},
 		HabitsToComplete: habitsToComplete,
 		HabitsCompleted:  habitsCompleted,
+		UpNext:           upNext,
+		Routines:         routines,
 		ActiveStreaks:    activeStreaks,
+		StreaksAtRisk:    streaksAtRisk,
 		QuickStats: dashboard.QuickStats{
 			TodayRate:     completionRate,
 			ThisWeek:      completionsThisWeek,
 			LongestStreak: longestStreak,
 			TotalHabits:   totalCount,
 		},
-		Achievements: []dashboard.AchievementSummary{}, // Empty for now, will be populated when achievements are implemented
+		Achievements: achievements,
 	}, nil
 }
 
+// recentAchievements returns the user's latest unlocked achievements
+func (s *DashboardService) recentAchievements(ctx context.Context, userID uuid.UUID) ([]dashboard.AchievementSummary, error) {
+	unlocked, err := s.achievementRepo.ListRecentUnlocked(ctx, userID, 5)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	definitions := achievementDefinitions()
+	summaries := make([]dashboard.AchievementSummary, 0, len(unlocked))
+	for _, a := range unlocked {
+		d, ok := definitions[a.Key]
+		if !ok {
+			continue
+		}
+		progress, target := min(a.Progress, d.Target), d.Target
+		summaries = append(summaries, dashboard.AchievementSummary{
+			ID:          d.Key,
+			Name:        d.Name,
+			Description: d.Description,
+			Icon:        d.Icon,
+			UnlockedAt:  a.UnlockedAt,
+			Progress:    &progress,
+			Target:      &target,
+		})
+	}
+
+	return summaries, nil
+}
 
+// buildRoutineSummaries computes today's progress for each of the user's
+// routines, counting only active member habits
+func (s *DashboardService) buildRoutineSummaries(
+	ctx context.Context,
+	userID uuid.UUID,
+	activeHabits []habit.Habit,
+	completedTodayMap map[uuid.UUID]bool,
+	today time.Time,
+) ([]dashboard.RoutineSummary, error) {
+	routines, err := s.routineRepo.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	members, err := s.routineRepo.ListMembers(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	activeHabitMap := make(map[uuid.UUID]bool, len(activeHabits))
+	for _, h := range activeHabits {
+		activeHabitMap[h.ID] = true
+	}
+
+	habitIDsByRoutine := make(map[uuid.UUID][]uuid.UUID)
+	for _, m := range members {
+		if activeHabitMap[m.HabitID] {
+			habitIDsByRoutine[m.RoutineID] = append(habitIDsByRoutine[m.RoutineID], m.HabitID)
+		}
+	}
+
+	summaries := make([]dashboard.RoutineSummary, 0, len(routines))
+	for _, rt := range routines {
+		habitIDs := habitIDsByRoutine[rt.ID]
+
+		completedCount := 0
+		habitIDStrings := make([]string, len(habitIDs))
+		for i, id := range habitIDs {
+			habitIDStrings[i] = id.String()
+			if completedTodayMap[id] {
+				completedCount++
+			}
+		}
+
+		progress := routineProgress(completedCount, len(habitIDs), today)
+
+		summaries = append(summaries, dashboard.RoutineSummary{
+			ID:             rt.ID.String(),
+			Name:           rt.Name,
+			TimeOfDay:      string(rt.TimeOfDay),
+			HabitIDs:       habitIDStrings,
+			CompletedCount: progress.CompletedCount,
+			TotalCount:     progress.TotalCount,
+			CompletionRate: progress.CompletionRate,
+			Completed:      progress.Completed,
+		})
+	}
+
+	return summaries, nil
+}
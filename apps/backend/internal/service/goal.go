@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/goal"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+type GoalService struct {
+	*BaseService
+	repos *repository.Repositories
+}
+
+func NewGoalService(repos *repository.Repositories) *GoalService {
+	return &GoalService{
+		BaseService: &BaseService{
+			resourceName: "goal",
+		},
+		repos: repos,
+	}
+}
+
+func (s *GoalService) CreateGoal(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *goal.CreateGoalPayload,
+) (*goal.GoalResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	startDate, err := parseOptionalDate(payload.StartDate, "start_date")
+	if err != nil {
+		return nil, err
+	}
+	if startDate == nil {
+		// Goals start today in the user's time zone, not the database's
+		today := lib.ClockFromContext(ctx).Today()
+		startDate = &today
+	}
+
+	targetDate, err := parseOptionalDate(payload.TargetDate, "target_date")
+	if err != nil {
+		return nil, err
+	}
+
+	var goalID uuid.UUID
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		created, err := tx.Goal.Create(ctx, userID, payload.Name, payload.Description, startDate, targetDate)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		goalID = created.ID
+
+		if err := s.setHabits(ctx, tx, goalID, userID, payload.HabitIDs); err != nil {
+			return err
+		}
+
+		if err := tx.Goal.ReplaceMilestones(ctx, goalID, payload.Milestones); err != nil {
+			return s.wrapError(err)
+		}
+
+		// Milestones may already be reached by logs since the start date
+		return evaluateGoal(ctx, tx, userID, goalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetGoal(ctx, userID, goalID)
+}
+
+func (s *GoalService) GetGoals(ctx context.Context, userID uuid.UUID) ([]goal.GoalResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	goals, err := s.repos.Goal.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	data, err := loadGoalData(ctx, s.repos, userID, goals)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	responses := make([]goal.GoalResponse, len(goals))
+	for i := range goals {
+		responses[i] = *data.response(&goals[i], clock)
+	}
+
+	return responses, nil
+}
+
+func (s *GoalService) GetGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) (*goal.GoalResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	g, err := s.repos.Goal.GetByID(ctx, goalID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	res, err := buildGoalResponse(ctx, s.repos, userID, g)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return res, nil
+}
+
+func (s *GoalService) UpdateGoal(
+	ctx context.Context,
+	userID uuid.UUID,
+	goalID uuid.UUID,
+	payload *goal.UpdateGoalPayload,
+) (*goal.GoalResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	// Verify goal exists and belongs to user
+	_, err = s.repos.Goal.GetByID(ctx, goalID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	targetDate, err := parseOptionalDate(payload.TargetDate, "target_date")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if _, err := tx.Goal.Update(ctx, goalID, userID, payload.Name, payload.Description, targetDate); err != nil {
+			return s.wrapError(err)
+		}
+
+		if payload.HabitIDs != nil {
+			if err := s.setHabits(ctx, tx, goalID, userID, *payload.HabitIDs); err != nil {
+				return err
+			}
+		}
+
+		if payload.Milestones != nil {
+			if err := tx.Goal.ReplaceMilestones(ctx, goalID, *payload.Milestones); err != nil {
+				return s.wrapError(err)
+			}
+		}
+
+		return evaluateGoal(ctx, tx, userID, goalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetGoal(ctx, userID, goalID)
+}
+
+func (s *GoalService) DeleteGoal(ctx context.Context, userID uuid.UUID, goalID uuid.UUID) error {
+	// Verify goal exists and belongs to user
+	_, err := s.repos.Goal.GetByID(ctx, goalID, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if err := s.repos.Goal.Delete(ctx, goalID, userID); err != nil {
+		return s.wrapError(err)
+	}
+
+	return nil
+}
+
+func (s *GoalService) setHabits(
+	ctx context.Context,
+	tx *repository.Repositories,
+	goalID uuid.UUID,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+) error {
+	unique := make(map[uuid.UUID]bool)
+	for _, id := range habitIDs {
+		unique[id] = true
+	}
+
+	linked, err := tx.Goal.SetHabits(ctx, goalID, userID, habitIDs)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if linked != len(unique) {
+		return errs.NewBadRequestError("One or more habits do not exist")
+	}
+
+	return nil
+}
+
+// evaluateGoals records the milestones reached by the open goals linked to a
+// habit. It must run on transaction-bound repositories.
+func evaluateGoals(ctx context.Context, tx *repository.Repositories, userID uuid.UUID, habitID uuid.UUID) error {
+	goalIDs, err := tx.Goal.ListOpenGoalIDsByHabit(ctx, habitID)
+	if err != nil {
+		return err
+	}
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	open := make(map[uuid.UUID]bool, len(goalIDs))
+	for _, id := range goalIDs {
+		open[id] = true
+	}
+
+	all, err := tx.Goal.List(ctx, userID)
+	if err != nil {
+		return err
+	}
+	goals := make([]goal.Goal, 0, len(goalIDs))
+	for _, g := range all {
+		if open[g.ID] {
+			goals = append(goals, g)
+		}
+	}
+
+	data, err := loadGoalData(ctx, tx, userID, goals)
+	if err != nil {
+		return err
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	for i := range goals {
+		if err := recordGoalProgress(ctx, tx, &goals[i], data.response(&goals[i], clock)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evaluateGoal records the time at which milestones, and the goal itself,
+// were first reached. Reached milestones stay reached, but a goal whose
+// milestones were edited is open again until they are all reached.
+func evaluateGoal(ctx context.Context, tx *repository.Repositories, userID uuid.UUID, goalID uuid.UUID) error {
+	g, err := tx.Goal.GetByID(ctx, goalID, userID)
+	if err != nil {
+		return err
+	}
+
+	res, err := buildGoalResponse(ctx, tx, userID, g)
+	if err != nil {
+		return err
+	}
+
+	return recordGoalProgress(ctx, tx, g, res)
+}
+
+// recordGoalProgress stores the milestones and goal completion that res
+// shows but g does not have yet
+func recordGoalProgress(ctx context.Context, tx *repository.Repositories, g *goal.Goal, res *goal.GoalResponse) error {
+	allCompleted := len(res.Milestones) > 0
+	for _, m := range res.Milestones {
+		if !m.Completed {
+			allCompleted = false
+			continue
+		}
+		if m.CompletedAt == nil {
+			if err := tx.Goal.CompleteMilestone(ctx, uuid.MustParse(m.ID)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if allCompleted && g.CompletedAt == nil {
+		return tx.Goal.Complete(ctx, g.ID)
+	}
+	if !allCompleted && g.CompletedAt != nil {
+		return tx.Goal.Reopen(ctx, g.ID)
+	}
+
+	return nil
+}
+
+// goalData holds what goal responses are computed from, loaded for several
+// goals with one query per table
+type goalData struct {
+	habitIDsByGoal   map[uuid.UUID]map[uuid.UUID]bool
+	habits           []habit.Habit
+	milestonesByGoal map[uuid.UUID][]goal.Milestone
+	logsByHabit      map[uuid.UUID][]habitlog.HabitLog
+	pauses           *PauseSchedule
+}
+
+// loadGoalData loads the habits, milestones and logs of the given goals. Logs
+// are loaded from the earliest start date and filtered per goal.
+func loadGoalData(
+	ctx context.Context,
+	repos *repository.Repositories,
+	userID uuid.UUID,
+	goals []goal.Goal,
+) (*goalData, error) {
+	data := &goalData{
+		habitIDsByGoal:   make(map[uuid.UUID]map[uuid.UUID]bool, len(goals)),
+		habits:           []habit.Habit{},
+		milestonesByGoal: make(map[uuid.UUID][]goal.Milestone, len(goals)),
+		logsByHabit:      make(map[uuid.UUID][]habitlog.HabitLog),
+	}
+	if len(goals) == 0 {
+		return data, nil
+	}
+
+	goalIDs := make([]uuid.UUID, len(goals))
+	from := goals[0].StartDate
+	for i, g := range goals {
+		goalIDs[i] = g.ID
+		data.habitIDsByGoal[g.ID] = make(map[uuid.UUID]bool)
+		if g.StartDate.Before(from) {
+			from = g.StartDate
+		}
+	}
+
+	links, err := repos.Goal.ListLinks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	habitIDs := make([]uuid.UUID, 0)
+	seen := make(map[uuid.UUID]bool)
+	for _, l := range links {
+		linked, ok := data.habitIDsByGoal[l.GoalID]
+		if !ok {
+			continue
+		}
+		linked[l.HabitID] = true
+		if !seen[l.HabitID] {
+			seen[l.HabitID] = true
+			habitIDs = append(habitIDs, l.HabitID)
+		}
+	}
+
+	data.habits, err = repos.Habit.GetByIDs(ctx, userID, habitIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones, err := repos.Goal.ListMilestones(ctx, goalIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range milestones {
+		data.milestonesByGoal[m.GoalID] = append(data.milestonesByGoal[m.GoalID], m)
+	}
+
+	data.pauses, err = loadPauseSchedule(ctx, repos.Pause, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := repos.HabitLog.GetByHabits(ctx, userID, habitIDs, from, lib.ClockFromContext(ctx).Today())
+	if err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		data.logsByHabit[log.HabitID] = append(data.logsByHabit[log.HabitID], log)
+	}
+
+	return data, nil
+}
+
+// buildGoalResponse computes milestone progress from the logs of the goal's
+// habits since the goal's start date
+func buildGoalResponse(
+	ctx context.Context,
+	repos *repository.Repositories,
+	userID uuid.UUID,
+	g *goal.Goal,
+) (*goal.GoalResponse, error) {
+	data, err := loadGoalData(ctx, repos, userID, []goal.Goal{*g})
+	if err != nil {
+		return nil, err
+	}
+
+	return data.response(g, lib.ClockFromContext(ctx)), nil
+}
+
+// response computes the goal's milestone progress from the logs of its
+// habits since its start date
+func (d *goalData) response(g *goal.Goal, clock lib.Clock) *goal.GoalResponse {
+	habits := make([]habit.Habit, 0)
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, h := range d.habits {
+		if !d.habitIDsByGoal[g.ID][h.ID] {
+			continue
+		}
+		habits = append(habits, h)
+
+		logs := make([]habitlog.HabitLog, 0)
+		for _, log := range d.logsByHabit[h.ID] {
+			if !log.LogDate.Before(g.StartDate) {
+				logs = append(logs, log)
+			}
+		}
+		logsByHabit[h.ID] = logs
+	}
+
+	totals := goalTotals(habits, logsByHabit, d.pauses, clock)
+	milestones := d.milestonesByGoal[g.ID]
+
+	res := &goal.GoalResponse{
+		Goal:       *g,
+		Habits:     make([]goal.GoalHabit, len(habits)),
+		Milestones: make([]goal.MilestoneProgress, len(milestones)),
+	}
+
+	for i, h := range habits {
+		res.Habits[i] = goal.GoalHabit{
+			ID:    h.ID.String(),
+			Name:  h.Name,
+			Icon:  h.Icon,
+			Color: h.Color,
+		}
+	}
+
+	progressSum := 0.0
+	for i, m := range milestones {
+		current := totals[m.Kind]
+		progress := math.Min(current/m.Target, 1) * 100
+		completed := m.CompletedAt != nil || current >= m.Target
+		if completed {
+			progress = 100
+		}
+
+		res.Milestones[i] = goal.MilestoneProgress{
+			ID:          m.ID.String(),
+			Name:        m.Name,
+			Kind:        m.Kind,
+			Target:      m.Target,
+			Unit:        m.Unit,
+			Current:     current,
+			Progress:    progress,
+			Completed:   completed,
+			CompletedAt: m.CompletedAt,
+		}
+		progressSum += progress
+	}
+
+	if len(milestones) > 0 {
+		res.Progress = progressSum / float64(len(milestones))
+	}
+
+	return res
+}
+
+// goalTotals computes the current value of every milestone kind: total
+// completions, the best streak of any habit and the sum of logged values
+func goalTotals(
+	habits []habit.Habit,
+	logsByHabit map[uuid.UUID][]habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+) map[goal.MilestoneKind]float64 {
+	totals := map[goal.MilestoneKind]float64{
+		goal.TotalCompletions: 0,
+		goal.Streak:           0,
+		goal.CumulativeValue:  0,
+	}
+
+	for _, h := range habits {
+		logs := logsByHabit[h.ID]
+
+		completedDates := make([]time.Time, 0)
+		for _, log := range logs {
+			if !log.Completed {
+				continue
+			}
+			completedDates = append(completedDates, log.LogDate)
+			totals[goal.TotalCompletions]++
+			if log.Value != nil {
+				totals[goal.CumulativeValue] += *log.Value
+			}
+		}
+
+		excluded := withExcusedDays(habitDayFilter(h, pauses), logs)
+		streak := findLongestDailyStreak(completedDates, excluded)
+		if h.Frequency == habit.Weekly {
+			streak = findLongestWeeklyStreak(completedDates, excluded, clock, h.WeeklyTarget())
+		}
+		totals[goal.Streak] = math.Max(totals[goal.Streak], float64(streak))
+	}
+
+	return totals
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date named field when it is set
+func parseOptionalDate(value *string, field string) (*time.Time, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse("2006-01-02", *value)
+	if err != nil {
+		return nil, errs.NewBadRequestError("Invalid " + field + " format. Use YYYY-MM-DD")
+	}
+
+	return &parsed, nil
+}
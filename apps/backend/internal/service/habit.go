@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
 	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
 	"github.com/reche13/habitum/internal/repository"
 )
 
@@ -12,11 +15,15 @@ type HabitService struct {
 	*BaseService
 	habitRepo      *repository.HabitRepository
 	habitLogService *HabitLogService
+	pauseRepo      *repository.PauseRepository
+	userRepo       *repository.UserRepository
 }
 
 func NewHabitService(
 	habitRepo *repository.HabitRepository,
 	habitLogService *HabitLogService,
+	pauseRepo *repository.PauseRepository,
+	userRepo *repository.UserRepository,
 ) *HabitService {
 	return &HabitService{
 		BaseService: &BaseService{
@@ -24,6 +31,8 @@ func NewHabitService(
 		},
 		habitRepo:       habitRepo,
 		habitLogService: habitLogService,
+		pauseRepo:       pauseRepo,
+		userRepo:        userRepo,
 	}
 }
 
@@ -32,131 +41,172 @@ func (s *HabitService) CreateHabit(
 	userID uuid.UUID,
 	payload *habit.CreateHabitPayload,
 ) (*habit.HabitResponse, error) {
-	createdHabit, err := s.habitRepo.Create(ctx, userID, payload)
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
 
-	// Enrich with computed fields (will be zeros for new habit)
-	enriched, err := s.enrichHabitWithStats(ctx, userID, *createdHabit)
+	window, err := resolveChallengeWindow(payload.StartDate, payload.EndDate, payload.DurationDays, lib.ClockFromContext(ctx).Today())
 	if err != nil {
-		// If enrichment fails, return habit without computed fields
-		return &habit.HabitResponse{
-			Habit:            *createdHabit,
-			CompletionRate:   0,
-			CompletedToday:   false,
-			CompletedThisWeek: 0,
-			CompletionHistory: []string{},
-		}, nil
+		return nil, err
+	}
+
+	if payload.StackAfterID != nil {
+		if *payload.StackAfterID == uuid.Nil {
+			payload.StackAfterID = nil
+		} else if err := s.validateStackAfter(ctx, userID, uuid.Nil, *payload.StackAfterID); err != nil {
+			return nil, err
+		}
 	}
 
-	return &enriched, nil
+	var createdHabit *habit.Habit
+	err = s.habitLogService.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		createdHabit, err = tx.Habit.Create(ctx, userID, payload, window)
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		// The new habit is scheduled from today on
+		if err := dropDailyRollups(ctx, tx.DailyRollup, userID, lib.ClockFromContext(ctx).Today()); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Enrich with computed fields (will be zeros for new habit)
+	return s.enrichHabit(ctx, userID, *createdHabit, nil), nil
 }
 
 func (s *HabitService) GetHabits(ctx context.Context, userID uuid.UUID, filters *habit.ListFilters) ([]habit.HabitResponse, int, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, 0, s.wrapError(err)
+	}
+
 	habits, total, err := s.habitRepo.List(ctx, userID, filters)
 	if err != nil {
 		return nil, 0, s.wrapError(err)
 	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, 0, s.wrapError(err)
+	}
 
 	// Enrich habits with computed fields
-	enrichedHabits := make([]habit.HabitResponse, len(habits))
-	for i := range habits {
-		enriched, err := s.enrichHabitWithStats(ctx, userID, habits[i])
-		if err != nil {
-			// If enrichment fails, return habit without computed fields
-			enrichedHabits[i] = habit.HabitResponse{
-				Habit:            habits[i],
-				CompletionRate:   0,
-				CompletedToday:   false,
-				CompletedThisWeek: 0,
-				CompletionHistory: []string{},
-			}
-			continue
-		}
-		enrichedHabits[i] = enriched
+	enrichedHabits, err := s.enrichHabits(ctx, userID, habits, pauses)
+	if err != nil {
+		// If enrichment fails, return habits without computed fields
+		enrichedHabits = bareHabitResponses(habits)
 	}
 
 	return enrichedHabits, total, nil
 }
 
 func (s *HabitService) GetHabit(ctx context.Context, habitID uuid.UUID, userID uuid.UUID) (*habit.HabitResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	h, err := s.habitRepo.GetByID(ctx, habitID, userID)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
+	archiveIfEnded(h, lib.ClockFromContext(ctx))
 
-	// Enrich with computed fields
-	enriched, err := s.enrichHabitWithStats(ctx, userID, *h)
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
 	if err != nil {
-		// If enrichment fails, return habit without computed fields
-		return &habit.HabitResponse{
-			Habit:            *h,
-			CompletionRate:   0,
-			CompletedToday:   false,
-			CompletedThisWeek: 0,
-			CompletionHistory: []string{},
-		}, nil
+		return nil, s.wrapError(err)
 	}
 
-	return &enriched, nil
+	// Enrich with computed fields
+	return s.enrichHabit(ctx, userID, *h, pauses), nil
 }
 
-// enrichHabitWithStats adds computed fields to a habit and returns HabitResponse
-func (s *HabitService) enrichHabitWithStats(ctx context.Context, userID uuid.UUID, h habit.Habit) (habit.HabitResponse, error) {
-	// Calculate current streak
-	currentStreak, err := CalculateCurrentStreak(ctx, s.habitLogService.habitLogRepo, userID, h.ID, h.Frequency)
-	if err != nil {
-		currentStreak = h.CurrentStreak // Fallback to stored value
+// enrichHabits adds computed fields to a page of habits. The logs of every
+// habit are loaded in one query and the stats derived in memory, so the
+// number of queries does not grow with the number of habits. Days on which a
+// habit is paused or outside its challenge window are left out of rates.
+func (s *HabitService) enrichHabits(ctx context.Context, userID uuid.UUID, habits []habit.Habit, pauses *PauseSchedule) ([]habit.HabitResponse, error) {
+	responses := make([]habit.HabitResponse, len(habits))
+	if len(habits) == 0 {
+		return responses, nil
 	}
 
-	// Calculate longest streak
-	longestStreak, err := CalculateLongestStreak(ctx, s.habitLogService.habitLogRepo, userID, h.ID, h.Frequency)
-	if err != nil {
-		longestStreak = h.LongestStreak // Fallback to stored value
+	if err := refreshCurrentStreaks(ctx, s.habitLogService.habitLogRepo, userID, habits, pauses); err != nil {
+		return nil, err
 	}
 
-	// Update stored streaks if they differ
-	if currentStreak != h.CurrentStreak || longestStreak != h.LongestStreak {
-		// Update in database
-		s.habitRepo.UpdateStreaks(ctx, h.ID, userID, currentStreak, longestStreak)
-		h.CurrentStreak = currentStreak
-		h.LongestStreak = longestStreak
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
 	}
 
-	// Calculate completion rate
-	completionRate, err := CalculateCompletionRate(ctx, s.habitLogService.habitLogRepo, userID, h.ID, h.CreatedAt, h.Frequency)
+	clock := lib.ClockFromContext(ctx)
+	logs, err := s.habitLogService.habitLogRepo.GetByHabits(ctx, userID, habitIDs, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), clock.Today())
 	if err != nil {
-		completionRate = 0
+		return nil, err
 	}
 
-	// Check if completed today
-	completedToday, completedTodayAt, err := GetTodayStatus(ctx, s.habitLogService.habitLogRepo, userID, h.ID)
-	if err != nil {
-		completedToday = false
-		completedTodayAt = nil
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog, len(habits))
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
 	}
 
-	// Get completed this week count
-	completedThisWeek, err := GetCompletedThisWeek(ctx, s.habitLogService.habitLogRepo, userID, h.ID)
+	strengths, err := loadHabitStrengths(ctx, s.habitLogService.repos.HabitStrength, s.habitLogService.habitLogRepo, userID, habits, pauses, clock.Today(), clock.Today())
 	if err != nil {
-		completedThisWeek = 0
+		return nil, err
 	}
 
-	// Get completion history (last year, limited to 365 dates)
-	completionHistory, err := GetCompletionHistoryDates(ctx, s.habitLogService.habitLogRepo, userID, h.ID, 365)
+	for i, h := range habits {
+		// Completion history is limited to the last 365 completed dates
+		stats := computeHabitStats(h, logsByHabit[h.ID], habitDayFilter(h, pauses), clock, 365)
+		h.CurrentStreak = stats.CurrentStreak
+		h.LongestStreak = stats.LongestStreak
+		responses[i] = habit.HabitResponse{
+			Habit:             h,
+			CompletionRate:    stats.CompletionRate,
+			CompletedToday:    stats.CompletedToday,
+			CompletedTodayAt:  stats.CompletedTodayAt,
+			CompletedThisWeek: stats.CompletedThisWeek,
+			WeeklyTarget:      stats.WeeklyTarget,
+			Strength:          strengths[h.ID][0],
+			CompletionHistory: stats.CompletionHistory,
+		}
+	}
+
+	return responses, nil
+}
+
+// enrichHabit adds computed fields to a single habit. If enrichment fails the
+// habit is returned without computed fields.
+func (s *HabitService) enrichHabit(ctx context.Context, userID uuid.UUID, h habit.Habit, pauses *PauseSchedule) *habit.HabitResponse {
+	habits := []habit.Habit{h}
+	responses, err := s.enrichHabits(ctx, userID, habits, pauses)
 	if err != nil {
-		completionHistory = []string{}
+		responses = bareHabitResponses(habits)
 	}
+	return &responses[0]
+}
 
-	return habit.HabitResponse{
-		Habit:             h,
-		CompletionRate:    completionRate,
-		CompletedToday:    completedToday,
-		CompletedTodayAt:  completedTodayAt,
-		CompletedThisWeek: completedThisWeek,
-		CompletionHistory: completionHistory,
-	}, nil
+// bareHabitResponses wraps habits in responses without computed fields
+func bareHabitResponses(habits []habit.Habit) []habit.HabitResponse {
+	responses := make([]habit.HabitResponse, len(habits))
+	for i, h := range habits {
+		responses[i] = habit.HabitResponse{
+			Habit:             h,
+			CompletionRate:    0,
+			CompletedToday:    false,
+			CompletedThisWeek: 0,
+			CompletionHistory: []string{},
+		}
+	}
+	return responses
 }
 
 func (s *HabitService) UpdateHabit(
@@ -165,43 +215,107 @@ func (s *HabitService) UpdateHabit(
 	userID uuid.UUID,
 	payload *habit.UpdateHabitPayload,
 ) (*habit.HabitResponse, error) {
-	// Verify habit exists and belongs to user
-	_, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
 
-	updatedHabit, err := s.habitRepo.Update(ctx, habitID, userID, payload)
+	// Verify habit exists and belongs to user
+	existing, err := s.habitRepo.GetByID(ctx, habitID, userID)
 	if err != nil {
 		return nil, s.wrapError(err)
 	}
 
-	// Enrich with computed fields
-	enriched, err := s.enrichHabitWithStats(ctx, userID, *updatedHabit)
+	// Changing one end of a challenge keeps the other one
+	var window *habit.ChallengeWindow
+	if payload.StartDate != nil || payload.EndDate != nil {
+		startDate, endDate := payload.StartDate, payload.EndDate
+		if startDate == nil && existing.StartDate != nil {
+			start := existing.StartDate.Format("2006-01-02")
+			startDate = &start
+		}
+		if endDate == nil && existing.EndDate != nil {
+			end := existing.EndDate.Format("2006-01-02")
+			endDate = &end
+		}
+
+		window, err = resolveChallengeWindow(startDate, endDate, nil, lib.ClockFromContext(ctx).Today())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if payload.StackAfterID != nil && *payload.StackAfterID != uuid.Nil {
+		if err := s.validateStackAfter(ctx, userID, habitID, *payload.StackAfterID); err != nil {
+			return nil, err
+		}
+	}
+
+	var updatedHabit *habit.Habit
+	err = s.habitLogService.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		updatedHabit, err = tx.Habit.Update(ctx, habitID, userID, payload, window)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		if err := storeEndedChallenges(ctx, tx.Habit, userID); err != nil {
+			return s.wrapError(err)
+		}
+		if payload.Frequency == nil && payload.TimesPerWeek == nil && window == nil {
+			return nil
+		}
+
+		// Streaks are counted per day or week, against the times per week
+		// target and only inside a challenge window
+		if err := recomputeStreaks(ctx, tx, userID, updatedHabit); err != nil {
+			return s.wrapError(err)
+		}
+		if err := tx.HabitStrength.DeleteFrom(ctx, habitID, time.Time{}); err != nil {
+			return s.wrapError(err)
+		}
+
+		// A challenge is only scheduled inside its window, and a weekly habit
+		// is scheduled by its times per week target
+		from := lib.ClockFromContext(ctx).Date(existing.CreatedAt)
+		if err := dropDailyRollups(ctx, tx.DailyRollup, userID, from); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		// If enrichment fails, return habit without computed fields
-		return &habit.HabitResponse{
-			Habit:            *updatedHabit,
-			CompletionRate:   0,
-			CompletedToday:   false,
-			CompletedThisWeek: 0,
-			CompletionHistory: []string{},
-		}, nil
+		return nil, err
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
 
-	return &enriched, nil
+	// Enrich with computed fields
+	return s.enrichHabit(ctx, userID, *updatedHabit, pauses), nil
 }
 
 func (s *HabitService) DeleteHabit(ctx context.Context, habitID uuid.UUID, userID uuid.UUID) error {
-	// Verify habit exists and belongs to user
-	_, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
 	if err != nil {
 		return s.wrapError(err)
 	}
 
-	if err := s.habitRepo.Delete(ctx, habitID, userID); err != nil {
+	// Verify habit exists and belongs to user
+	existing, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	if err != nil {
 		return s.wrapError(err)
 	}
 
-	return nil
+	return s.habitLogService.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if err := tx.Habit.Delete(ctx, habitID, userID); err != nil {
+			return s.wrapError(err)
+		}
+
+		// The habit's logs went with it
+		from := lib.ClockFromContext(ctx).Date(existing.CreatedAt)
+		if err := dropDailyRollups(ctx, tx.DailyRollup, userID, from); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
 }
\ No newline at end of file
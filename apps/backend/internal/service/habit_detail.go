@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+// GetHabitStats returns the statistics a habit's detail screen needs, derived
+// from the habit's full log history
+func (s *HabitService) GetHabitStats(
+	ctx context.Context,
+	habitID uuid.UUID,
+	userID uuid.UUID,
+) (*habit.HabitStatsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	h, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveIfEnded(h, clock)
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logs, err := s.habitLogService.habitLogRepo.GetByHabit(ctx, userID, h.ID, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), clock.Today())
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	stats := habitDetailStats(*h, logs, pauses, clock)
+	return &stats, nil
+}
+
+// habitDetailStats computes a habit's detail statistics as of the clock's
+// today from its full log history
+func habitDetailStats(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+) habit.HabitStatsResponse {
+	today := clock.Today()
+	created := clock.Date(h.CreatedAt)
+	excluded := habitActiveDayFilter(h, pauses, logs, clock)
+
+	completedDates := make([]time.Time, 0)
+	for _, log := range logs {
+		if log.Completed && !log.LogDate.After(today) {
+			completedDates = append(completedDates, lib.NormalizeDate(log.LogDate))
+		}
+	}
+
+	stats := habit.HabitStatsResponse{
+		HabitID:          h.ID.String(),
+		Unit:             "day",
+		TotalCompletions: len(completedDates),
+		CompletionRate:   completionRate(logs, h.CreatedAt, h.Frequency, h.WeeklyTarget(), habitDayFilter(h, pauses), clock),
+		StreakHistory:    []habit.StreakSpan{},
+		Monthly:          []habit.MonthlyCompletion{},
+	}
+	if h.Frequency == habit.Weekly {
+		stats.Unit = "week"
+	}
+
+	if len(completedDates) > 0 {
+		first, last := earliestDate(completedDates), latestDate(completedDates)
+		firstKey, lastKey := dateKey(first), dateKey(last)
+		stats.FirstCompletion, stats.LastCompletion = &firstKey, &lastKey
+
+		// Logs are unique per day, so the gaps add up to the whole span
+		if len(completedDates) > 1 {
+			avgGap := last.Sub(first).Hours() / 24 / float64(len(completedDates)-1)
+			stats.AvgGapDays = &avgGap
+		}
+	}
+
+	runs := streakRuns(h, completedDates, excluded, clock, today)
+	for i := len(runs) - 1; i >= 0; i-- {
+		span := newStreakSpan(runs[i], h)
+		stats.StreakHistory = append(stats.StreakHistory, span)
+		if span.Active {
+			stats.CurrentStreak = span
+		}
+		if span.Length > stats.LongestStreak.Length {
+			stats.LongestStreak = span
+		}
+	}
+
+	// One point per month since the habit was created
+	completionsByMonth := make(map[string]int)
+	for _, date := range completedDates {
+		completionsByMonth[date.Format("2006-01")]++
+	}
+	dayFilter := habitDayFilter(h, pauses)
+	for month := bucketStart(created, GranularityMonth, clock); !month.After(today); month = month.AddDate(0, 1, 0) {
+		from, to := month, month.AddDate(0, 1, -1)
+		if from.Before(created) {
+			from = created
+		}
+		if to.After(today) {
+			to = today
+		}
+		stats.Monthly = append(stats.Monthly, habit.MonthlyCompletion{
+			Month:          month.Format("2006-01"),
+			Completions:    completionsByMonth[month.Format("2006-01")],
+			CompletionRate: completionRateBetween(logs, h.Frequency, h.WeeklyTarget(), dayFilter, clock, from, to),
+		})
+	}
+
+	stats.DayOfWeek = habitDayOfWeek(h, completedDates, excluded, clock)
+
+	return stats
+}
+
+// newStreakSpan describes a streak run. Only the last run of a habit that is
+// not archived can still be alive.
+func newStreakSpan(run streakRun, h habit.Habit) habit.StreakSpan {
+	start, end := dateKey(run.start), dateKey(run.end)
+	return habit.StreakSpan{
+		Length:    run.length,
+		StartDate: &start,
+		EndDate:   &end,
+		Active:    run.brokenOn == nil && h.ArchivedAt == nil,
+	}
+}
+
+// habitDayOfWeek spreads a habit's completions over the days of the week. For
+// daily habits it also counts the days that were scheduled, leaving out
+// excluded days and today until it is completed.
+func habitDayOfWeek(
+	h habit.Habit,
+	completedDates []time.Time,
+	excluded DayFilter,
+	clock lib.Clock,
+) []habit.DayOfWeekCompletion {
+	completionsByDay := make(map[int]int)
+	for _, date := range completedDates {
+		completionsByDay[clock.WeekdayIndex(date)]++
+	}
+
+	scheduledByDay := make(map[int]int)
+	if h.Frequency == habit.Daily {
+		today := clock.Today()
+		completed := dateSet(completedDates)
+		first := clock.Date(h.CreatedAt)
+		if len(completedDates) > 0 && earliestDate(completedDates).Before(first) {
+			first = earliestDate(completedDates)
+		}
+		for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+			if completed[dateKey(day)] || (!excluded(day) && day.Before(today)) {
+				scheduledByDay[clock.WeekdayIndex(day)]++
+			}
+		}
+	}
+
+	weekdays := clock.Weekdays()
+	points := make([]habit.DayOfWeekCompletion, 7)
+	for dayIndex := 0; dayIndex < 7; dayIndex++ {
+		point := habit.DayOfWeekCompletion{
+			Day:         weekdays[dayIndex].String(),
+			DayIndex:    dayIndex,
+			Completions: completionsByDay[dayIndex],
+			Scheduled:   scheduledByDay[dayIndex],
+		}
+		if point.Scheduled > 0 {
+			point.CompletionRate = (float64(point.Completions) / float64(point.Scheduled)) * 100
+		}
+		if len(completedDates) > 0 {
+			point.Share = (float64(point.Completions) / float64(len(completedDates))) * 100
+		}
+		points[dayIndex] = point
+	}
+
+	return points
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+)
+
+// GetNextStackedHabit returns the first habit stacked on habitID that is still
+// to be done on date, or nil when there is none
+func (s *HabitService) GetNextStackedHabit(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	date time.Time,
+) (*habit.Habit, error) {
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	day := lib.NormalizeDate(date)
+	logs, err := s.habitLogService.habitLogRepo.GetByDate(ctx, userID, day)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	done := make(map[uuid.UUID]bool)
+	for _, log := range logs {
+		if log.Completed || log.Status.IsExcused() {
+			done[log.HabitID] = true
+		}
+	}
+
+	for _, h := range stackedOn(habits, habitID, day) {
+		if !done[h.ID] {
+			return &h, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// validateStackAfter checks that habitID (uuid.Nil for a new habit) can be
+// stacked on anchorID without stacking a habit on itself or forming a loop
+func (s *HabitService) validateStackAfter(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	anchorID uuid.UUID,
+) error {
+	if anchorID == habitID {
+		return errs.NewBadRequestError("A habit cannot be stacked on itself")
+	}
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	anchors := make(map[uuid.UUID]*uuid.UUID, len(habits))
+	for _, h := range habits {
+		anchors[h.ID] = h.StackAfterID
+	}
+
+	if _, ok := anchors[anchorID]; !ok {
+		return errs.NewBadRequestError("The habit to stack on does not exist")
+	}
+
+	// Walk up from the anchor; reaching habitID would close a loop
+	seen := make(map[uuid.UUID]bool)
+	for id := &anchorID; id != nil && !seen[*id]; id = anchors[*id] {
+		if *id == habitID {
+			return errs.NewBadRequestError("Stacking on that habit would create a loop")
+		}
+		seen[*id] = true
+	}
+
+	return nil
+}
+
+// stackedOn returns the active habits stacked directly on anchorID on date,
+// oldest first
+func stackedOn(habits []habit.Habit, anchorID uuid.UUID, date time.Time) []habit.Habit {
+	followers := make([]habit.Habit, 0)
+	for _, h := range habits {
+		if h.StackAfterID != nil && *h.StackAfterID == anchorID && h.ArchivedAt == nil && h.InChallengeWindow(date) {
+			followers = append(followers, h)
+		}
+	}
+
+	sort.Slice(followers, func(i, j int) bool {
+		return followers[i].CreatedAt.Before(followers[j].CreatedAt)
+	})
+
+	return followers
+}
+
+// habitChains returns every stacking chain as the ordered list of its habits,
+// from a habit that is not stacked on anything down to a habit nothing is
+// stacked on. Archived habits end a chain.
+func habitChains(habits []habit.Habit) [][]habit.Habit {
+	byID := make(map[uuid.UUID]habit.Habit, len(habits))
+	followers := make(map[uuid.UUID][]habit.Habit)
+	for _, h := range habits {
+		if h.ArchivedAt != nil {
+			continue
+		}
+		byID[h.ID] = h
+	}
+	for _, h := range byID {
+		if h.StackAfterID != nil {
+			if _, ok := byID[*h.StackAfterID]; ok {
+				followers[*h.StackAfterID] = append(followers[*h.StackAfterID], h)
+			}
+		}
+	}
+	for id := range followers {
+		sort.Slice(followers[id], func(i, j int) bool {
+			return followers[id][i].CreatedAt.Before(followers[id][j].CreatedAt)
+		})
+	}
+
+	roots := make([]habit.Habit, 0)
+	for _, h := range byID {
+		stacked := false
+		if h.StackAfterID != nil {
+			_, stacked = byID[*h.StackAfterID]
+		}
+		if !stacked && len(followers[h.ID]) > 0 {
+			roots = append(roots, h)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].CreatedAt.Before(roots[j].CreatedAt)
+	})
+
+	chains := make([][]habit.Habit, 0)
+	var walk func(path []habit.Habit)
+	walk = func(path []habit.Habit) {
+		last := path[len(path)-1]
+		next := followers[last.ID]
+		if len(next) == 0 {
+			chains = append(chains, append([]habit.Habit(nil), path...))
+			return
+		}
+		for _, f := range next {
+			walk(append(path, f))
+		}
+	}
+	for _, root := range roots {
+		walk([]habit.Habit{root})
+	}
+
+	return chains
+}
+
+// chainStats counts, for each day from start to today, whether a chain was
+// started, completed in full or broken at one of its links. Days on which any
+// habit of the chain is excluded are left out, and today only counts once the
+// chain is complete because it is still in progress.
+func chainStats(
+	chain []habit.Habit,
+	completed map[uuid.UUID]map[string]bool,
+	excluded map[uuid.UUID]DayFilter,
+	start, end time.Time,
+	clock lib.Clock,
+) analytics.ChainDataPoint {
+	today := clock.Today()
+	point := analytics.ChainDataPoint{
+		HabitIDs:   make([]string, len(chain)),
+		HabitNames: make([]string, len(chain)),
+		Links:      make([]analytics.ChainLinkDataPoint, len(chain)-1),
+	}
+	for i, h := range chain {
+		point.HabitIDs[i] = h.ID.String()
+		point.HabitNames[i] = h.Name
+		if i > 0 {
+			point.Links[i-1] = analytics.ChainLinkDataPoint{
+				FromHabitID:   chain[i-1].ID.String(),
+				FromHabitName: chain[i-1].Name,
+				ToHabitID:     h.ID.String(),
+				ToHabitName:   h.Name,
+			}
+		}
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := dateKey(day)
+		if !completed[chain[0].ID][key] {
+			continue
+		}
+
+		skip := false
+		for _, h := range chain {
+			if !isHabitActiveOn(clock, h, day) || excluded[h.ID](day) {
+				skip = true
+				break
+			}
+		}
+		if skip {
+			continue
+		}
+
+		// Find the first link where the chain stopped, if any
+		brokenAt := -1
+		for i := 1; i < len(chain); i++ {
+			if !completed[chain[i].ID][key] {
+				brokenAt = i - 1
+				break
+			}
+		}
+		if brokenAt >= 0 && day.Equal(today) {
+			continue
+		}
+
+		point.DaysStarted++
+		if brokenAt < 0 {
+			point.DaysCompleted++
+			for i := range point.Links {
+				point.Links[i].Held++
+			}
+			continue
+		}
+		for i := 0; i < brokenAt; i++ {
+			point.Links[i].Held++
+		}
+		point.Links[brokenAt].Broken++
+	}
+
+	if point.DaysStarted > 0 {
+		point.CompletionRate = (float64(point.DaysCompleted) / float64(point.DaysStarted)) * 100
+	}
+	for i, link := range point.Links {
+		if reached := link.Held + link.Broken; reached > 0 {
+			point.Links[i].BreakRate = (float64(link.Broken) / float64(reached)) * 100
+		}
+	}
+
+	return point
+}
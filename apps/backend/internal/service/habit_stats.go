@@ -2,13 +2,13 @@ package service
 
 import (
 	"context"
-	"fmt"
 	"sort"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/reche13/habitum/internal/lib"
 	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
 	"github.com/reche13/habitum/internal/repository"
 )
 
@@ -20,9 +20,39 @@ type HabitStats struct {
 	CompletedToday     bool
 	CompletedTodayAt   *time.Time
 	CompletedThisWeek  int
+	WeeklyTarget       int
 	CompletionHistory  []string
 }
 
+// DayFilter reports whether a date should be left out of streak and rate
+// calculations, for example because the habit was paused on that day.
+// Excluded days neither extend nor break a streak.
+type DayFilter func(date time.Time) bool
+
+// noExcludedDays is a DayFilter that keeps every day
+func noExcludedDays(time.Time) bool {
+	return false
+}
+
+// withExcusedDays extends a DayFilter with the days the habit was explicitly
+// skipped or covered by a streak freeze, so an excused day is treated the
+// same way as a paused one
+func withExcusedDays(excluded DayFilter, logs []habitlog.HabitLog) DayFilter {
+	excused := make(map[string]bool)
+	for _, log := range logs {
+		if log.Status.IsExcused() {
+			excused[dateKey(log.LogDate)] = true
+		}
+	}
+	if len(excused) == 0 {
+		return excluded
+	}
+
+	return func(date time.Time) bool {
+		return excused[dateKey(date)] || excluded(date)
+	}
+}
+
 // CalculateCurrentStreak calculates the current consecutive streak for a habit
 func CalculateCurrentStreak(
 	ctx context.Context,
@@ -30,15 +60,18 @@ func CalculateCurrentStreak(
 	userID uuid.UUID,
 	habitID uuid.UUID,
 	frequency habit.Frequency,
+	timesPerWeek int,
+	excluded DayFilter,
 ) (int, error) {
 	// Get all completed logs for this habit
 	startDate := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := lib.NormalizeDate(time.Now().UTC())
+	endDate := lib.ClockFromContext(ctx).Today()
 
 	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, startDate, endDate)
 	if err != nil {
 		return 0, err
 	}
+	excluded = withExcusedDays(excluded, logs)
 
 	// Extract completed dates
 	completedDates := make([]time.Time, 0)
@@ -52,107 +85,72 @@ func CalculateCurrentStreak(
 		return 0, nil
 	}
 
-	// Sort dates descending (most recent first)
-	sort.Slice(completedDates, func(i, j int) bool {
-		return completedDates[i].After(completedDates[j])
-	})
-
 	// Calculate streak based on frequency
 	if frequency == habit.Daily {
-		return calculateDailyStreak(completedDates), nil
+		return calculateDailyStreak(completedDates, excluded, endDate), nil
 	} else {
-		return calculateWeeklyStreak(completedDates), nil
+		return calculateWeeklyStreak(completedDates, excluded, lib.ClockFromContext(ctx), timesPerWeek, endDate), nil
 	}
 }
 
-// calculateDailyStreak calculates streak for daily habits
-func calculateDailyStreak(completedDates []time.Time) int {
+// calculateDailyStreak calculates streak for daily habits as of today. A
+// day that is not completed yet does not break the streak.
+func calculateDailyStreak(completedDates []time.Time, excluded DayFilter, today time.Time) int {
 	if len(completedDates) == 0 {
 		return 0
 	}
 
-	today := lib.NormalizeDate(time.Now().UTC())
+	completed := dateSet(completedDates)
+	earliest := earliestDate(completedDates)
 	streak := 0
 
-	// Check if today is completed
-	expectedDate := today
-	if !isDateInSlice(completedDates, expectedDate) {
-		// If today is not completed, start from yesterday
-		expectedDate = today.AddDate(0, 0, -1)
+	// Today is still in progress, so if it is not completed yet start from yesterday
+	day := today
+	if !completed[dateKey(day)] {
+		day = day.AddDate(0, 0, -1)
 	}
 
-	// Count consecutive days backwards
-	for i := 0; i < len(completedDates); i++ {
-		if completedDates[i].Equal(expectedDate) {
+	// Count consecutive days backwards, stepping over excluded days
+	for !day.Before(earliest) {
+		if completed[dateKey(day)] {
 			streak++
-			expectedDate = expectedDate.AddDate(0, 0, -1)
-		} else if completedDates[i].Before(expectedDate) {
+		} else if !excluded(day) {
 			// Gap found, streak broken
 			break
 		}
+		day = day.AddDate(0, 0, -1)
 	}
 
 	return streak
 }
 
-// calculateWeeklyStreak calculates streak for weekly habits
-func calculateWeeklyStreak(completedDates []time.Time) int {
+// calculateWeeklyStreak calculates streak for weekly habits as of the week
+// containing today, with weeks starting on the clock's week start. A week
+// extends the streak once it meets the times per week target.
+func calculateWeeklyStreak(completedDates []time.Time, excluded DayFilter, clock lib.Clock, timesPerWeek int, today time.Time) int {
 	if len(completedDates) == 0 {
 		return 0
 	}
 
-	// Group by week (ISO week)
-	weekMap := make(map[string]bool)
-	for _, date := range completedDates {
-		year, week := date.ISOWeek()
-		weekKey := formatWeekKey(year, week)
-		weekMap[weekKey] = true
-	}
-
-	// Get all weeks and sort
-	weeks := make([]string, 0, len(weekMap))
-	for week := range weekMap {
-		weeks = append(weeks, week)
-	}
-	sort.Sort(sort.Reverse(sort.StringSlice(weeks)))
+	counts := weeklyCounts(completedDates, clock)
+	earliest := clock.WeekStart(earliestDate(completedDates))
+	streak := 0
 
-	if len(weeks) == 0 {
-		return 0
+	// The current week is still in progress, so if it has not met the target
+	// yet start from the previous week
+	week := clock.WeekStart(today)
+	if !isWeekMet(counts, week, timesPerWeek, excluded) {
+		week = week.AddDate(0, 0, -7)
 	}
 
-	// Calculate consecutive weeks
-	streak := 0
-	currentYear, currentWeek := time.Now().UTC().ISOWeek()
-	currentWeekKey := formatWeekKey(currentYear, currentWeek)
-
-	// Check if current week is completed
-	expectedWeek := currentWeekKey
-	if !contains(weeks, expectedWeek) {
-		// If current week is not completed, go to previous week
-		prevWeek := time.Now().UTC().AddDate(0, 0, -7)
-		prevYear, prevWeekNum := prevWeek.ISOWeek()
-		expectedWeek = formatWeekKey(prevYear, prevWeekNum)
-	}
-
-	// Count consecutive weeks backwards
-	streak = 0
-	if len(weeks) > 0 {
-		// Start from current/previous week and count backwards
-		checkDate := time.Now().UTC()
-		if !contains(weeks, currentWeekKey) {
-			checkDate = checkDate.AddDate(0, 0, -7)
-		}
-		
-		for i := 0; i < 1000; i++ { // Max 1000 weeks (~19 years)
-			year, week := checkDate.ISOWeek()
-			weekKey := formatWeekKey(year, week)
-			if contains(weeks, weekKey) {
-				streak++
-				checkDate = checkDate.AddDate(0, 0, -7)
-			} else {
-				break
-			}
+	// Count consecutive weeks backwards, stepping over fully excluded weeks
+	for !week.Before(earliest) {
+		if isWeekMet(counts, week, timesPerWeek, excluded) {
+			streak++
+		} else if !isWeekExcluded(week, excluded) {
+			break
 		}
+		week = week.AddDate(0, 0, -7)
 	}
 
 	return streak
@@ -165,15 +163,18 @@ func CalculateLongestStreak(
 	userID uuid.UUID,
 	habitID uuid.UUID,
 	frequency habit.Frequency,
+	timesPerWeek int,
+	excluded DayFilter,
 ) (int, error) {
 	// Get all completed logs
 	startDate := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := lib.NormalizeDate(time.Now().UTC())
+	endDate := lib.ClockFromContext(ctx).Today()
 
 	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, startDate, endDate)
 	if err != nil {
 		return 0, err
 	}
+	excluded = withExcusedDays(excluded, logs)
 
 	// Extract completed dates
 	completedDates := make([]time.Time, 0)
@@ -187,120 +188,71 @@ func CalculateLongestStreak(
 		return 0, nil
 	}
 
-	// Sort dates ascending
-	sort.Slice(completedDates, func(i, j int) bool {
-		return completedDates[i].Before(completedDates[j])
-	})
-
 	if frequency == habit.Daily {
-		return findLongestDailyStreak(completedDates), nil
+		return findLongestDailyStreak(completedDates, excluded), nil
 	} else {
-		return findLongestWeeklyStreak(completedDates), nil
+		return findLongestWeeklyStreak(completedDates, excluded, lib.ClockFromContext(ctx), timesPerWeek), nil
 	}
 }
 
 // findLongestDailyStreak finds the longest consecutive daily streak
-func findLongestDailyStreak(completedDates []time.Time) int {
+func findLongestDailyStreak(completedDates []time.Time, excluded DayFilter) int {
 	if len(completedDates) == 0 {
 		return 0
 	}
 
-	longest := 1
-	current := 1
+	completed := dateSet(completedDates)
+	last := latestDate(completedDates)
+
+	longest := 0
+	current := 0
 
-	for i := 1; i < len(completedDates); i++ {
-		daysDiff := int(completedDates[i].Sub(completedDates[i-1]).Hours() / 24)
-		if daysDiff == 1 {
+	for day := earliestDate(completedDates); !day.After(last); day = day.AddDate(0, 0, 1) {
+		if completed[dateKey(day)] {
 			// Consecutive day
 			current++
 			if current > longest {
 				longest = current
 			}
-		} else {
+		} else if !excluded(day) {
 			// Gap found, reset
-			current = 1
+			current = 0
 		}
 	}
 
 	return longest
 }
 
-// findLongestWeeklyStreak finds the longest consecutive weekly streak
-func findLongestWeeklyStreak(completedDates []time.Time) int {
+// findLongestWeeklyStreak finds the longest run of weeks meeting the times
+// per week target, with weeks starting on the clock's week start
+func findLongestWeeklyStreak(completedDates []time.Time, excluded DayFilter, clock lib.Clock, timesPerWeek int) int {
 	if len(completedDates) == 0 {
 		return 0
 	}
 
-	// Group by week
-	weekMap := make(map[string]bool)
-	for _, date := range completedDates {
-		year, week := date.ISOWeek()
-		weekKey := formatWeekKey(year, week)
-		weekMap[weekKey] = true
-	}
-
-	// Get all weeks and sort
-	weeks := make([]string, 0, len(weekMap))
-	for week := range weekMap {
-		weeks = append(weeks, week)
-	}
-	sort.Strings(weeks)
-
-	if len(weeks) == 0 {
-		return 0
-	}
+	counts := weeklyCounts(completedDates, clock)
+	last := clock.WeekStart(latestDate(completedDates))
 
-	longest := 1
-	current := 1
+	longest := 0
+	current := 0
 
-	// For weekly streaks, use a simpler approach: convert weeks to dates and check consecutive
-	// Get dates for each week (use first day of week)
-	weekDates := make([]time.Time, 0, len(weeks))
-	for _, weekStr := range weeks {
-		year, weekNum := parseWeekKey(weekStr)
-		if year == 0 {
-			continue
-		}
-		// Find first day of ISO week for that year/week
-		date := time.Date(year, 1, 4, 0, 0, 0, 0, time.UTC) // Jan 4 is always in week 1
-		dateYear, dateWeek := date.ISOWeek()
-		for dateYear != year || dateWeek != weekNum {
-			if dateWeek < weekNum {
-				date = date.AddDate(0, 0, 7)
-			} else {
-				date = date.AddDate(0, 0, -7)
-			}
-			dateYear, dateWeek = date.ISOWeek()
-		}
-		// Get Monday of that week
-		for date.Weekday() != time.Monday {
-			date = date.AddDate(0, 0, -1)
-		}
-		weekDates = append(weekDates, date)
-	}
-	
-	// Sort by date
-	sort.Slice(weekDates, func(i, j int) bool {
-		return weekDates[i].Before(weekDates[j])
-	})
-
-	// Check consecutive weeks
-	for i := 1; i < len(weekDates); i++ {
-		daysDiff := int(weekDates[i].Sub(weekDates[i-1]).Hours() / (24 * 7))
-		if daysDiff == 1 {
+	for week := clock.WeekStart(earliestDate(completedDates)); !week.After(last); week = week.AddDate(0, 0, 7) {
+		if isWeekMet(counts, week, timesPerWeek, excluded) {
 			current++
 			if current > longest {
 				longest = current
 			}
-		} else {
-			current = 1
+		} else if !isWeekExcluded(week, excluded) {
+			current = 0
 		}
 	}
 
 	return longest
 }
 
-// CalculateCompletionRate calculates the completion rate for a habit
+// CalculateCompletionRate calculates the completion rate for a habit.
+// Excluded days are left out of the denominator unless the habit was
+// completed on them anyway.
 func CalculateCompletionRate(
 	ctx context.Context,
 	habitLogRepo *repository.HabitLogRepository,
@@ -308,168 +260,287 @@ func CalculateCompletionRate(
 	habitID uuid.UUID,
 	habitCreatedAt time.Time,
 	frequency habit.Frequency,
+	timesPerWeek int,
+	excluded DayFilter,
 ) (float64, error) {
-	now := lib.NormalizeDate(time.Now().UTC())
-	createdAt := lib.NormalizeDate(habitCreatedAt)
-
-	// Get all completed logs
-	startDate := createdAt
-	endDate := now
+	clock := lib.ClockFromContext(ctx)
 
-	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, startDate, endDate)
+	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, clock.Date(habitCreatedAt), clock.Today())
 	if err != nil {
 		return 0, err
 	}
 
+	return completionRate(logs, habitCreatedAt, frequency, timesPerWeek, excluded, clock), nil
+}
+
+// completionRate calculates the completion rate of a habit from its logs
+// since creation
+func completionRate(
+	logs []habitlog.HabitLog,
+	habitCreatedAt time.Time,
+	frequency habit.Frequency,
+	timesPerWeek int,
+	excluded DayFilter,
+	clock lib.Clock,
+) float64 {
+	return completionRateBetween(logs, frequency, timesPerWeek, excluded, clock, clock.Date(habitCreatedAt), clock.Today())
+}
+
+// completionRateBetween calculates the completion rate of a habit from its
+// logs between startDate and endDate. For weekly habits it is the share of
+// weeks that met the times per week target; the last week only counts once
+// it has.
+func completionRateBetween(
+	logs []habitlog.HabitLog,
+	frequency habit.Frequency,
+	timesPerWeek int,
+	excluded DayFilter,
+	clock lib.Clock,
+	startDate, endDate time.Time,
+) float64 {
+	excluded = withExcusedDays(excluded, logs)
+
 	// Count completed logs
 	completedCount := 0
+	completedDates := make([]time.Time, 0)
 	for _, log := range logs {
-		if log.Completed {
+		if log.Completed && !log.LogDate.Before(startDate) && !log.LogDate.After(endDate) {
 			completedCount++
+			completedDates = append(completedDates, log.LogDate)
 		}
 	}
+	completed := dateSet(completedDates)
 
 	if frequency == habit.Daily {
-		// For daily habits: completed days / total days since creation
-		totalDays := int(endDate.Sub(startDate).Hours()/24) + 1
+		// For daily habits: completed days / scheduled days since creation
+		totalDays := 0
+		for day := startDate; !day.After(endDate); day = day.AddDate(0, 0, 1) {
+			if completed[dateKey(day)] || !excluded(day) {
+				totalDays++
+			}
+		}
 		if totalDays == 0 {
-			return 0, nil
+			return 0
 		}
-		return (float64(completedCount) / float64(totalDays)) * 100, nil
+		return (float64(completedCount) / float64(totalDays)) * 100
 	} else {
-		// For weekly habits: completed weeks / total weeks since creation
-		// Calculate weeks between dates
-		weeksDiff := int(endDate.Sub(startDate).Hours()/(24*7)) + 1
-		if weeksDiff == 0 {
-			return 0, nil
+		// For weekly habits: weeks meeting the target / scheduled weeks,
+		// leaving the days before the start out of the first week
+		counts := weeklyCounts(completedDates, clock)
+		unscheduled := func(day time.Time) bool {
+			return day.Before(startDate) || excluded(day)
 		}
-		return (float64(completedCount) / float64(weeksDiff)) * 100, nil
+		lastWeek := clock.WeekStart(endDate)
+
+		metWeeks, totalWeeks := 0, 0
+		for week := clock.WeekStart(startDate); !week.After(endDate); week = week.AddDate(0, 0, 7) {
+			switch {
+			case isWeekMet(counts, week, timesPerWeek, unscheduled):
+				metWeeks++
+				totalWeeks++
+			case week.Equal(lastWeek) || isWeekExcluded(week, unscheduled):
+				// Still in progress, or nothing was scheduled
+			default:
+				totalWeeks++
+			}
+		}
+		if totalWeeks == 0 {
+			return 0
+		}
+		return (float64(metWeeks) / float64(totalWeeks)) * 100
 	}
 }
 
-// GetTodayStatus checks if habit is completed today
-func GetTodayStatus(
-	ctx context.Context,
-	habitLogRepo *repository.HabitLogRepository,
-	userID uuid.UUID,
-	habitID uuid.UUID,
-) (bool, *time.Time, error) {
-	today := lib.NormalizeDate(time.Now().UTC())
-	logs, err := habitLogRepo.GetByDate(ctx, userID, today)
-	if err != nil {
-		return false, nil, err
+// computeHabitStats derives a habit's statistics from its full log history in
+// memory, so stats for a whole page of habits can be built from one query.
+// Streaks are taken from the habit row.
+func computeHabitStats(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	excluded DayFilter,
+	clock lib.Clock,
+	historyLimit int,
+) HabitStats {
+	today := clock.Today()
+	weekStart := clock.WeekStart(today)
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	stats := HabitStats{
+		CurrentStreak:     h.CurrentStreak,
+		LongestStreak:     h.LongestStreak,
+		CompletionRate:    completionRate(logs, h.CreatedAt, h.Frequency, h.WeeklyTarget(), excluded, clock),
+		CompletionHistory: make([]string, 0),
+	}
+	if h.Frequency == habit.Weekly {
+		stats.WeeklyTarget = weekTarget(weekStart, h.WeeklyTarget(), withExcusedDays(excluded, logs))
 	}
 
 	for _, log := range logs {
-		if log.HabitID == habitID && log.Completed {
-			return true, &log.CreatedAt, nil
+		if !log.Completed || log.LogDate.After(today) {
+			continue
 		}
+		if log.LogDate.Equal(today) {
+			createdAt := log.CreatedAt
+			stats.CompletedToday = true
+			stats.CompletedTodayAt = &createdAt
+		}
+		if !log.LogDate.Before(weekStart) && !log.LogDate.After(weekEnd) {
+			stats.CompletedThisWeek++
+		}
+		stats.CompletionHistory = append(stats.CompletionHistory, dateKey(log.LogDate))
 	}
 
-	return false, nil, nil
-}
-
-// GetCompletedThisWeek counts completions this week
-func GetCompletedThisWeek(
-	ctx context.Context,
-	habitLogRepo *repository.HabitLogRepository,
-	userID uuid.UUID,
-	habitID uuid.UUID,
-) (int, error) {
-	now := lib.NormalizeDate(time.Now().UTC())
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
+	// Most recent first, limited
+	sort.Sort(sort.Reverse(sort.StringSlice(stats.CompletionHistory)))
+	if historyLimit > 0 && len(stats.CompletionHistory) > historyLimit {
+		stats.CompletionHistory = stats.CompletionHistory[:historyLimit]
 	}
 
-	weekStart := now.AddDate(0, 0, -(weekday - 1))
-	weekEnd := weekStart.AddDate(0, 0, 6)
+	return stats
+}
 
-	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, weekStart, weekEnd)
-	if err != nil {
-		return 0, err
-	}
+// streakRun is a run of consecutive successful periods of a habit: days for
+// daily habits, weeks meeting the target for weekly ones
+type streakRun struct {
+	start    time.Time  // First day, or week start
+	end      time.Time  // Last day, or week start
+	length   int        // In periods
+	brokenOn *time.Time // First missed day or week start; nil while the run is alive
+}
 
-	count := 0
-	for _, log := range logs {
-		if log.Completed {
-			count++
+// streakRuns splits a habit's completions up to through into streaks, oldest
+// first. Excluded days neither extend nor break a streak, and a day or week
+// that is not over yet only extends one.
+func streakRuns(
+	h habit.Habit,
+	completedDates []time.Time,
+	excluded DayFilter,
+	clock lib.Clock,
+	through time.Time,
+) []streakRun {
+	if len(completedDates) == 0 {
+		return nil
+	}
+	today := clock.Today()
+
+	// One unit per day, or per week for weekly habits
+	first, step := earliestDate(completedDates), 1
+	completed := dateSet(completedDates)
+	isMet := func(unit time.Time) bool { return completed[dateKey(unit)] }
+	isExcluded := excluded
+	if h.Frequency == habit.Weekly {
+		first, step = clock.WeekStart(first), 7
+		counts := weeklyCounts(completedDates, clock)
+		isMet = func(unit time.Time) bool { return isWeekMet(counts, unit, h.WeeklyTarget(), excluded) }
+		isExcluded = func(unit time.Time) bool { return isWeekExcluded(unit, excluded) }
+	}
+
+	runs := make([]streakRun, 0)
+	alive := false
+	for unit := first; !unit.After(through); unit = unit.AddDate(0, 0, step) {
+		switch {
+		case isMet(unit):
+			if !alive {
+				runs = append(runs, streakRun{start: unit})
+				alive = true
+			}
+			run := &runs[len(runs)-1]
+			run.end = unit
+			run.length++
+		case isExcluded(unit) || !unit.AddDate(0, 0, step-1).Before(today):
+			// Neither extends nor breaks the streak, or is not over yet
+		case alive:
+			brokenOn := unit
+			runs[len(runs)-1].brokenOn = &brokenOn
+			alive = false
 		}
 	}
 
-	return count, nil
+	return runs
 }
 
-// GetCompletionHistoryDates gets array of completion dates
-func GetCompletionHistoryDates(
-	ctx context.Context,
-	habitLogRepo *repository.HabitLogRepository,
-	userID uuid.UUID,
-	habitID uuid.UUID,
-	limit int,
-) ([]string, error) {
-	startDate := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-	endDate := lib.NormalizeDate(time.Now().UTC())
+// Helper functions
 
-	logs, err := habitLogRepo.GetByHabit(ctx, userID, habitID, startDate, endDate)
-	if err != nil {
-		return nil, err
-	}
+func dateKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
 
-	dates := make([]string, 0)
-	for _, log := range logs {
-		if log.Completed {
-			dates = append(dates, log.LogDate.Format("2006-01-02"))
-		}
+func dateSet(dates []time.Time) map[string]bool {
+	set := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		set[dateKey(d)] = true
 	}
+	return set
+}
 
-	// Sort descending (most recent first) and limit
-	sort.Sort(sort.Reverse(sort.StringSlice(dates)))
-	if limit > 0 && len(dates) > limit {
-		dates = dates[:limit]
+func earliestDate(dates []time.Time) time.Time {
+	earliest := dates[0]
+	for _, d := range dates[1:] {
+		if d.Before(earliest) {
+			earliest = d
+		}
 	}
-
-	return dates, nil
+	return lib.NormalizeDate(earliest)
 }
 
-// Helper functions
-
-func isDateInSlice(dates []time.Time, target time.Time) bool {
-	for _, d := range dates {
-		if d.Equal(target) {
-			return true
+func latestDate(dates []time.Time) time.Time {
+	latest := dates[0]
+	for _, d := range dates[1:] {
+		if d.After(latest) {
+			latest = d
 		}
 	}
-	return false
+	return lib.NormalizeDate(latest)
 }
 
-func formatWeekKey(year, week int) string {
-	// Format as "YYYY-WW" for sorting (zero-padded week)
-	return fmt.Sprintf("%04d-W%02d", year, week)
+// isHabitActiveOn reports whether the habit existed, was not yet archived and,
+// for challenges, was inside its challenge window on date. Creation and
+// archive times are read in the clock's time zone.
+func isHabitActiveOn(clock lib.Clock, h habit.Habit, date time.Time) bool {
+	if date.Before(clock.Date(h.CreatedAt)) || !h.InChallengeWindow(date) {
+		return false
+	}
+	return h.ArchivedAt == nil || date.Before(clock.Date(*h.ArchivedAt))
 }
 
-func parseWeekKey(key string) (int, int) {
-	// Parse "YYYY-WW" format
-	var year, week int
-	_, err := fmt.Sscanf(key, "%04d-W%02d", &year, &week)
-	if err != nil {
-		return 0, 0
+// isWeekExcluded reports whether every day of the week starting at weekStart is excluded
+func isWeekExcluded(weekStart time.Time, excluded DayFilter) bool {
+	for i := 0; i < 7; i++ {
+		if !excluded(weekStart.AddDate(0, 0, i)) {
+			return false
+		}
 	}
-	return year, week
+	return true
 }
 
-func getLastWeekOfYear(year int) int {
-	// Get last week of year
-	lastDay := time.Date(year, 12, 31, 0, 0, 0, 0, time.UTC)
-	_, week := lastDay.ISOWeek()
-	return week
+// weeklyCounts returns the number of completions in each week, keyed by the
+// week's first day
+func weeklyCounts(completedDates []time.Time, clock lib.Clock) map[string]int {
+	counts := make(map[string]int)
+	for _, date := range completedDates {
+		counts[dateKey(clock.WeekStart(date))]++
+	}
+	return counts
 }
 
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
+// weekTarget returns the completions the week starting at weekStart needs to
+// succeed. Excluded days lower the times per week target to the days left.
+func weekTarget(weekStart time.Time, timesPerWeek int, excluded DayFilter) int {
+	available := 0
+	for i := 0; i < 7; i++ {
+		if !excluded(weekStart.AddDate(0, 0, i)) {
+			available++
 		}
 	}
-	return false
+	if available < timesPerWeek {
+		return available
+	}
+	return timesPerWeek
+}
+
+// isWeekMet reports whether the week starting at weekStart has at least one
+// completion and meets its target
+func isWeekMet(counts map[string]int, weekStart time.Time, timesPerWeek int, excluded DayFilter) bool {
+	count := counts[dateKey(weekStart)]
+	return count > 0 && count >= weekTarget(weekStart, timesPerWeek, excluded)
 }
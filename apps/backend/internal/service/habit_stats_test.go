@@ -0,0 +1,408 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+// May 2026 starts on a Friday: the 4th, 11th, 18th and 25th are Mondays and
+// the 3rd, 10th, 17th and 24th Sundays.
+
+func day(value string) time.Time {
+	d, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func days(values ...string) []time.Time {
+	dates := make([]time.Time, len(values))
+	for i, v := range values {
+		dates[i] = day(v)
+	}
+	return dates
+}
+
+// dayRange returns every day from start to end, inclusive
+func dayRange(start, end string) []string {
+	values := make([]string, 0)
+	for d := day(start); !d.After(day(end)); d = d.AddDate(0, 0, 1) {
+		values = append(values, dateKey(d))
+	}
+	return values
+}
+
+// excludeDays returns a DayFilter excluding the given days
+func excludeDays(values ...string) DayFilter {
+	excluded := dateSet(days(values...))
+	return func(date time.Time) bool {
+		return excluded[dateKey(date)]
+	}
+}
+
+// completedLogs returns a completed log for each of the given days
+func completedLogs(values ...string) []habitlog.HabitLog {
+	logs := make([]habitlog.HabitLog, len(values))
+	for i, d := range days(values...) {
+		logs[i] = habitlog.HabitLog{LogDate: d, Status: habitlog.Completed, Completed: true}
+	}
+	return logs
+}
+
+// weekClock returns a UTC clock at noon on today with weeks starting on weekStart
+func weekClock(today string, weekStart time.Weekday) lib.Clock {
+	return lib.NewClock(day(today).Add(12*time.Hour), time.UTC).WithDayBoundaries(weekStart, 0)
+}
+
+func TestWeekTarget(t *testing.T) {
+	tests := []struct {
+		name         string
+		timesPerWeek int
+		excluded     DayFilter
+		want         int
+	}{
+		{"nothing excluded", 3, noExcludedDays, 3},
+		{"enough days left", 3, excludeDays("2026-05-04", "2026-05-05"), 3},
+		{"paused days lower the target", 3, excludeDays(dayRange("2026-05-04", "2026-05-08")...), 2},
+		{"every day but one excused", 7, excludeDays(dayRange("2026-05-04", "2026-05-09")...), 1},
+		{"fully excluded week", 3, excludeDays(dayRange("2026-05-04", "2026-05-10")...), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weekTarget(day("2026-05-04"), tt.timesPerWeek, tt.excluded); got != tt.want {
+				t.Errorf("weekTarget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsWeekMet(t *testing.T) {
+	clock := weekClock("2026-05-20", time.Monday)
+
+	tests := []struct {
+		name      string
+		completed []string
+		excluded  DayFilter
+		want      bool
+	}{
+		{"below the target", []string{"2026-05-04", "2026-05-06"}, noExcludedDays, false},
+		{"at the target", []string{"2026-05-04", "2026-05-06", "2026-05-08"}, noExcludedDays, true},
+		{"above the target", []string{"2026-05-04", "2026-05-05", "2026-05-06", "2026-05-08"}, noExcludedDays, true},
+		{"completions in another week", []string{"2026-05-11", "2026-05-12", "2026-05-13"}, noExcludedDays, false},
+		{"paused days lower the target", []string{"2026-05-09", "2026-05-10"}, excludeDays(dayRange("2026-05-04", "2026-05-08")...), true},
+		{"excused days lower the target", []string{"2026-05-10"}, excludeDays(dayRange("2026-05-04", "2026-05-09")...), true},
+		{"fully excluded week", nil, excludeDays(dayRange("2026-05-04", "2026-05-10")...), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counts := weeklyCounts(days(tt.completed...), clock)
+			if got := isWeekMet(counts, day("2026-05-04"), 3, tt.excluded); got != tt.want {
+				t.Errorf("isWeekMet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateWeeklyStreak(t *testing.T) {
+	tests := []struct {
+		name      string
+		today     string
+		weekStart time.Weekday
+		completed []string
+		excluded  DayFilter
+		want      int
+	}{
+		{
+			name:      "no completions",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			excluded:  noExcludedDays,
+			want:      0,
+		},
+		{
+			name:      "current week in progress does not break the streak",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-29", "2026-05-04", "2026-05-05", "2026-05-11", "2026-05-14", "2026-05-18"},
+			excluded:  noExcludedDays,
+			want:      3,
+		},
+		{
+			name:      "current week that met the target counts",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-29", "2026-05-04", "2026-05-05", "2026-05-11", "2026-05-14", "2026-05-18", "2026-05-19"},
+			excluded:  noExcludedDays,
+			want:      4,
+		},
+		{
+			name:      "a week below the target breaks the streak",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-29", "2026-05-04", "2026-05-11", "2026-05-14"},
+			excluded:  noExcludedDays,
+			want:      1,
+		},
+		{
+			name:      "a fully excluded week is stepped over",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-29", "2026-05-11", "2026-05-14"},
+			excluded:  excludeDays(dayRange("2026-05-04", "2026-05-10")...),
+			want:      2,
+		},
+		{
+			name:      "paused days lower a week's target",
+			today:     "2026-05-20",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-29", "2026-05-04", "2026-05-05", "2026-05-17"},
+			excluded:  excludeDays(dayRange("2026-05-11", "2026-05-16")...),
+			want:      3,
+		},
+		{
+			name:      "Sunday week start groups Sunday with the days after it",
+			today:     "2026-05-13",
+			weekStart: time.Sunday,
+			completed: []string{"2026-05-10", "2026-05-11"},
+			excluded:  noExcludedDays,
+			want:      1,
+		},
+		{
+			name:      "Monday week start splits the same days",
+			today:     "2026-05-13",
+			weekStart: time.Monday,
+			completed: []string{"2026-05-10", "2026-05-11"},
+			excluded:  noExcludedDays,
+			want:      0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := weekClock(tt.today, tt.weekStart)
+			got := calculateWeeklyStreak(days(tt.completed...), tt.excluded, clock, 2, clock.Today())
+			if got != tt.want {
+				t.Errorf("calculateWeeklyStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindLongestWeeklyStreak(t *testing.T) {
+	tests := []struct {
+		name      string
+		weekStart time.Weekday
+		completed []string
+		excluded  DayFilter
+		want      int
+	}{
+		{
+			name:      "no completions",
+			weekStart: time.Monday,
+			excluded:  noExcludedDays,
+			want:      0,
+		},
+		{
+			name:      "longest of two runs",
+			weekStart: time.Monday,
+			completed: []string{
+				"2026-04-06", "2026-04-07", "2026-04-13", "2026-04-14",
+				"2026-04-20",
+				"2026-04-27", "2026-04-28", "2026-05-04", "2026-05-05", "2026-05-11", "2026-05-12",
+			},
+			excluded: noExcludedDays,
+			want:     3,
+		},
+		{
+			name:      "above the target counts once per week",
+			weekStart: time.Monday,
+			completed: []string{"2026-05-04", "2026-05-05", "2026-05-06", "2026-05-07"},
+			excluded:  noExcludedDays,
+			want:      1,
+		},
+		{
+			name:      "a fully excluded week is stepped over",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-28", "2026-05-11", "2026-05-12"},
+			excluded:  excludeDays(dayRange("2026-05-04", "2026-05-10")...),
+			want:      2,
+		},
+		{
+			name:      "a partly paused week with no completions breaks the run",
+			weekStart: time.Monday,
+			completed: []string{"2026-04-27", "2026-04-28", "2026-05-11", "2026-05-12"},
+			excluded:  excludeDays(dayRange("2026-05-04", "2026-05-09")...),
+			want:      1,
+		},
+		{
+			name:      "Sunday week start",
+			weekStart: time.Sunday,
+			completed: []string{"2026-05-03", "2026-05-04", "2026-05-10", "2026-05-11"},
+			excluded:  noExcludedDays,
+			want:      2,
+		},
+		{
+			name:      "Monday week start",
+			weekStart: time.Monday,
+			completed: []string{"2026-05-03", "2026-05-04", "2026-05-10", "2026-05-11"},
+			excluded:  noExcludedDays,
+			want:      1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := weekClock("2026-05-20", tt.weekStart)
+			if got := findLongestWeeklyStreak(days(tt.completed...), tt.excluded, clock, 2); got != tt.want {
+				t.Errorf("findLongestWeeklyStreak() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompletionRateBetween(t *testing.T) {
+	skipped := habitlog.HabitLog{LogDate: day("2026-05-06"), Status: habitlog.Skipped}
+
+	tests := []struct {
+		name         string
+		frequency    habit.Frequency
+		timesPerWeek int
+		weekStart    time.Weekday
+		logs         []habitlog.HabitLog
+		excluded     DayFilter
+		start, end   string
+		want         float64
+	}{
+		{
+			name:      "daily",
+			frequency: habit.Daily,
+			logs:      completedLogs("2026-05-04", "2026-05-05", "2026-05-06"),
+			excluded:  noExcludedDays,
+			start:     "2026-05-04",
+			end:       "2026-05-10",
+			want:      300.0 / 7,
+		},
+		{
+			name:      "daily, paused days leave the denominator",
+			frequency: habit.Daily,
+			logs:      completedLogs("2026-05-04", "2026-05-05", "2026-05-06"),
+			excluded:  excludeDays("2026-05-09", "2026-05-10"),
+			start:     "2026-05-04",
+			end:       "2026-05-10",
+			want:      60,
+		},
+		{
+			name:      "daily, a completion on a paused day still counts",
+			frequency: habit.Daily,
+			logs:      completedLogs("2026-05-04", "2026-05-05", "2026-05-06"),
+			excluded:  excludeDays(dayRange("2026-05-06", "2026-05-10")...),
+			start:     "2026-05-04",
+			end:       "2026-05-10",
+			want:      100,
+		},
+		{
+			name:      "daily, a skipped day is excused",
+			frequency: habit.Daily,
+			logs:      append(completedLogs("2026-05-04", "2026-05-05"), skipped),
+			excluded:  noExcludedDays,
+			start:     "2026-05-04",
+			end:       "2026-05-06",
+			want:      100,
+		},
+		{
+			name:         "weekly, current week still in progress",
+			frequency:    habit.Weekly,
+			timesPerWeek: 2,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-04", "2026-05-06", "2026-05-12", "2026-05-18"),
+			excluded:     noExcludedDays,
+			start:        "2026-05-04",
+			end:          "2026-05-20",
+			want:         50,
+		},
+		{
+			name:         "weekly, current week that met the target counts",
+			frequency:    habit.Weekly,
+			timesPerWeek: 2,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-04", "2026-05-06", "2026-05-12", "2026-05-18", "2026-05-19"),
+			excluded:     noExcludedDays,
+			start:        "2026-05-04",
+			end:          "2026-05-20",
+			want:         200.0 / 3,
+		},
+		{
+			name:         "weekly, a fully excluded week is left out",
+			frequency:    habit.Weekly,
+			timesPerWeek: 2,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-04", "2026-05-06"),
+			excluded:     excludeDays(dayRange("2026-05-11", "2026-05-17")...),
+			start:        "2026-05-04",
+			end:          "2026-05-20",
+			want:         100,
+		},
+		{
+			name:         "weekly, paused days lower a week's target",
+			frequency:    habit.Weekly,
+			timesPerWeek: 3,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-04", "2026-05-05", "2026-05-06", "2026-05-16"),
+			excluded:     excludeDays(dayRange("2026-05-11", "2026-05-15")...),
+			start:        "2026-05-04",
+			end:          "2026-05-20",
+			want:         50,
+		},
+		{
+			name:         "weekly, days before the start lower the first week's target",
+			frequency:    habit.Weekly,
+			timesPerWeek: 3,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-09", "2026-05-10"),
+			excluded:     noExcludedDays,
+			start:        "2026-05-09",
+			end:          "2026-05-17",
+			want:         100,
+		},
+		{
+			name:         "weekly, Sunday week start",
+			frequency:    habit.Weekly,
+			timesPerWeek: 2,
+			weekStart:    time.Sunday,
+			logs:         completedLogs("2026-05-03", "2026-05-04", "2026-05-10", "2026-05-11"),
+			excluded:     noExcludedDays,
+			start:        "2026-05-03",
+			end:          "2026-05-20",
+			want:         100,
+		},
+		{
+			name:         "weekly, Monday week start",
+			frequency:    habit.Weekly,
+			timesPerWeek: 2,
+			weekStart:    time.Monday,
+			logs:         completedLogs("2026-05-03", "2026-05-04", "2026-05-10", "2026-05-11"),
+			excluded:     noExcludedDays,
+			start:        "2026-05-03",
+			end:          "2026-05-20",
+			want:         200.0 / 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := weekClock(tt.end, tt.weekStart)
+			got := completionRateBetween(tt.logs, tt.frequency, tt.timesPerWeek, tt.excluded, clock, day(tt.start), day(tt.end))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("completionRateBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habittemplate"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/sqlerr"
+)
+
+//go:embed habit_templates.json
+var builtinTemplatesJSON []byte
+
+type HabitTemplateService struct {
+	*BaseService
+	templateRepo *repository.HabitTemplateRepository
+	habitRepo    *repository.HabitRepository
+	habitService *HabitService
+	builtins     []habittemplate.BuiltinTemplate
+}
+
+func NewHabitTemplateService(
+	templateRepo *repository.HabitTemplateRepository,
+	habitRepo *repository.HabitRepository,
+	habitService *HabitService,
+) *HabitTemplateService {
+	return &HabitTemplateService{
+		BaseService: &BaseService{
+			resourceName: "habit template",
+		},
+		templateRepo: templateRepo,
+		habitRepo:    habitRepo,
+		habitService: habitService,
+		builtins:     mustLoadBuiltinTemplates(),
+	}
+}
+
+// mustLoadBuiltinTemplates parses the embedded catalogue. The catalogue ships
+// with the binary, so a malformed file is a programming error.
+func mustLoadBuiltinTemplates() []habittemplate.BuiltinTemplate {
+	var templates []habittemplate.BuiltinTemplate
+	if err := json.Unmarshal(builtinTemplatesJSON, &templates); err != nil {
+		panic(fmt.Sprintf("invalid habit template catalogue: %v", err))
+	}
+	return templates
+}
+
+// GetTemplates returns the built-in catalogue together with the user's
+// private templates, grouped by category
+func (s *HabitTemplateService) GetTemplates(ctx context.Context, userID uuid.UUID) (*habittemplate.TemplatesResponse, error) {
+	userTemplates, err := s.templateRepo.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	byCategory := make(map[habit.Category][]habittemplate.TemplateResponse)
+
+	// User templates first so they are easy to find
+	for _, t := range userTemplates {
+		byCategory[t.Category] = append(byCategory[t.Category], habittemplate.TemplateResponse{
+			ID:           t.ID.String(),
+			Name:         t.Name,
+			Description:  t.Description,
+			Icon:         t.Icon,
+			Color:        t.Color,
+			Category:     t.Category,
+			Frequency:    t.Frequency,
+			TimesPerWeek: t.TimesPerWeek,
+			BuiltIn:      false,
+		})
+	}
+
+	for _, t := range s.builtins {
+		byCategory[t.Category] = append(byCategory[t.Category], habittemplate.TemplateResponse{
+			ID:           t.ID,
+			Name:         t.Name,
+			Description:  t.Description,
+			Icon:         t.Icon,
+			Color:        t.Color,
+			Category:     t.Category,
+			Frequency:    t.Frequency,
+			TimesPerWeek: t.TimesPerWeek,
+			BuiltIn:      true,
+		})
+	}
+
+	groups := make([]habittemplate.CategoryTemplates, 0)
+	for _, category := range habit.Categories() {
+		templates, ok := byCategory[category]
+		if !ok {
+			continue
+		}
+		groups = append(groups, habittemplate.CategoryTemplates{
+			Category:  category,
+			Label:     categoryLabel(category),
+			Templates: templates,
+		})
+	}
+
+	return &habittemplate.TemplatesResponse{
+		Data: groups,
+	}, nil
+}
+
+// CreateHabitFromTemplate creates a new habit from a built-in template (by slug)
+// or from one of the user's private templates (by UUID)
+func (s *HabitTemplateService) CreateHabitFromTemplate(
+	ctx context.Context,
+	userID uuid.UUID,
+	templateID string,
+	overrides *habittemplate.CreateFromTemplatePayload,
+) (*habit.HabitResponse, error) {
+	payload, err := s.resolveTemplate(ctx, userID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if overrides != nil {
+		if overrides.Name != nil {
+			payload.Name = *overrides.Name
+		}
+		if overrides.Icon != nil {
+			payload.Icon = overrides.Icon
+		}
+		if overrides.Color != nil {
+			payload.Color = overrides.Color
+		}
+	}
+
+	return s.habitService.CreateHabit(ctx, userID, payload)
+}
+
+// SaveHabitAsTemplate stores a user's habit as a private template
+func (s *HabitTemplateService) SaveHabitAsTemplate(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	payload *habittemplate.SaveAsTemplatePayload,
+) (*habittemplate.TemplateResponse, error) {
+	h, err := s.habitRepo.GetByID(ctx, habitID, userID)
+	if err != nil {
+		return nil, sqlerr.WrapError(err, "habit")
+	}
+
+	name := h.Name
+	if payload != nil && payload.Name != nil {
+		name = *payload.Name
+	}
+
+	t, err := s.templateRepo.CreateFromHabit(ctx, userID, h, name)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return &habittemplate.TemplateResponse{
+		ID:           t.ID.String(),
+		Name:         t.Name,
+		Description:  t.Description,
+		Icon:         t.Icon,
+		Color:        t.Color,
+		Category:     t.Category,
+		Frequency:    t.Frequency,
+		TimesPerWeek: t.TimesPerWeek,
+		BuiltIn:      false,
+	}, nil
+}
+
+// DeleteTemplate removes one of the user's private templates. Built-in
+// templates are addressed by slug and cannot be deleted.
+func (s *HabitTemplateService) DeleteTemplate(ctx context.Context, userID uuid.UUID, templateID string) error {
+	id, err := uuid.Parse(templateID)
+	if err != nil {
+		for _, t := range s.builtins {
+			if t.ID == templateID {
+				return errs.NewBadRequestError("Built-in templates cannot be deleted")
+			}
+		}
+		return errs.NewBadRequestError("Invalid template ID format")
+	}
+
+	// Verify template exists and belongs to user
+	_, err = s.templateRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if err := s.templateRepo.Delete(ctx, id, userID); err != nil {
+		return s.wrapError(err)
+	}
+
+	return nil
+}
+
+func (s *HabitTemplateService) resolveTemplate(
+	ctx context.Context,
+	userID uuid.UUID,
+	templateID string,
+) (*habit.CreateHabitPayload, error) {
+	// Private templates are addressed by UUID, built-in ones by slug
+	if id, err := uuid.Parse(templateID); err == nil {
+		t, err := s.templateRepo.GetByID(ctx, id, userID)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+		return &habit.CreateHabitPayload{
+			Name:         t.Name,
+			Description:  t.Description,
+			Icon:         t.Icon,
+			Color:        t.Color,
+			Category:     t.Category,
+			Frequency:    t.Frequency,
+			TimesPerWeek: t.TimesPerWeek,
+		}, nil
+	}
+
+	for _, t := range s.builtins {
+		if t.ID == templateID {
+			return &habit.CreateHabitPayload{
+				Name:         t.Name,
+				Description:  t.Description,
+				Icon:         t.Icon,
+				Color:        t.Color,
+				Category:     t.Category,
+				Frequency:    t.Frequency,
+				TimesPerWeek: t.TimesPerWeek,
+			}, nil
+		}
+	}
+
+	return nil, errs.NewNotFoundError("habit template not found")
+}
+
+// categoryLabel capitalizes a category for display
+func categoryLabel(category habit.Category) string {
+	label := string(category)
+	if len(label) > 0 {
+		label = strings.ToUpper(string(label[0])) + strings.ToLower(label[1:])
+	}
+	return label
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// countingDB is a DBTX that answers every query with no rows and counts the
+// statements sent to it
+type countingDB struct {
+	queries int
+}
+
+func (db *countingDB) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	db.queries++
+	return pgconn.NewCommandTag(""), nil
+}
+
+func (db *countingDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	db.queries++
+	return &emptyRows{}, nil
+}
+
+func (db *countingDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	db.queries++
+	return emptyRow{}
+}
+
+func (db *countingDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errors.New("countingDB does not support transactions")
+}
+
+type emptyRows struct {
+	closed bool
+}
+
+func (r *emptyRows) Close()                                       { r.closed = true }
+func (r *emptyRows) Err() error                                   { return nil }
+func (r *emptyRows) CommandTag() pgconn.CommandTag                { return pgconn.NewCommandTag("") }
+func (r *emptyRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *emptyRows) Next() bool                                   { r.closed = true; return false }
+func (r *emptyRows) Scan(dest ...any) error                       { return pgx.ErrNoRows }
+func (r *emptyRows) Values() ([]any, error)                       { return nil, pgx.ErrNoRows }
+func (r *emptyRows) RawValues() [][]byte                          { return nil }
+func (r *emptyRows) Conn() *pgx.Conn                              { return nil }
+
+type emptyRow struct{}
+
+func (emptyRow) Scan(dest ...any) error { return pgx.ErrNoRows }
+
+// testHabits returns n habits created a month before today whose stored
+// streak was broken since their last completion, so enriching them has to
+// look at their logs
+func testHabits(n int, today time.Time) []habit.Habit {
+	habits := make([]habit.Habit, n)
+	for i := range habits {
+		lastCompletedOn := today.AddDate(0, 0, -10)
+		h := habit.Habit{
+			Name:            fmt.Sprintf("Habit %d", i),
+			Frequency:       habit.Daily,
+			CurrentStreak:   5,
+			LongestStreak:   5,
+			LastCompletedOn: &lastCompletedOn,
+		}
+		if i%2 == 1 {
+			timesPerWeek := 3
+			h.Frequency = habit.Weekly
+			h.TimesPerWeek = &timesPerWeek
+		}
+		h.ID = uuid.New()
+		h.CreatedAt = today.AddDate(0, -1, 0)
+		habits[i] = h
+	}
+	return habits
+}
+
+func TestEnrichHabitsQueryCount(t *testing.T) {
+	now := time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC)
+	clock := lib.NewClock(now, time.UTC)
+	ctx := lib.WithClock(context.Background(), clock)
+	userID := uuid.New()
+
+	queriesFor := func(n int) int {
+		t.Helper()
+		db := &countingDB{}
+		repos := repository.NewRepositories(db)
+		s := NewHabitService(repos.Habit, NewHabitLogService(repos, XPRules{}), repos.Pause, repos.User)
+
+		responses, err := s.enrichHabits(ctx, userID, testHabits(n, clock.Today()), NewPauseSchedule(nil))
+		if err != nil {
+			t.Fatalf("enrichHabits(%d habits): %v", n, err)
+		}
+		if len(responses) != n {
+			t.Fatalf("enrichHabits(%d habits) returned %d responses", n, len(responses))
+		}
+		return db.queries
+	}
+
+	base := queriesFor(1)
+	if base == 0 {
+		t.Fatal("enrichHabits sent no queries; the counting DB is not in use")
+	}
+
+	for _, n := range []int{2, 10, 100} {
+		if got := queriesFor(n); got != base {
+			t.Errorf("enrichHabits(%d habits) sent %d queries, want %d as for a single habit", n, got, base)
+		}
+	}
+}
+
+func BenchmarkEnrichHabits(b *testing.B) {
+	now := time.Date(2026, 5, 13, 12, 0, 0, 0, time.UTC)
+	clock := lib.NewClock(now, time.UTC)
+	ctx := lib.WithClock(context.Background(), clock)
+	userID := uuid.New()
+
+	for _, n := range []int{1, 10, 100} {
+		b.Run(fmt.Sprintf("%d habits", n), func(b *testing.B) {
+			db := &countingDB{}
+			repos := repository.NewRepositories(db)
+			s := NewHabitService(repos.Habit, NewHabitLogService(repos, XPRules{}), repos.Pause, repos.User)
+			habits := testHabits(n, clock.Today())
+
+			for i := 0; i < b.N; i++ {
+				if _, err := s.enrichHabits(ctx, userID, habits, NewPauseSchedule(nil)); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(db.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -6,23 +6,30 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
 	"github.com/reche13/habitum/internal/model/habitlog"
 	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/sqlerr"
 )
 
 type HabitLogService struct {
 	*BaseService
+	repos        *repository.Repositories
 	habitLogRepo *repository.HabitLogRepository
+	xp           XPRules
 }
 
 func NewHabitLogService(
-	habitLogRepo *repository.HabitLogRepository,
+	repos *repository.Repositories,
+	xp XPRules,
 ) *HabitLogService {
 	return &HabitLogService{
 		BaseService: &BaseService{
 			resourceName: "habitlog",
 		},
-		habitLogRepo: habitLogRepo,
+		repos:        repos,
+		habitLogRepo: repos.HabitLog,
+		xp:           xp,
 	}
 }
 
@@ -31,10 +38,14 @@ func (s *HabitLogService) SetCompletion(
 	userID uuid.UUID,
 	payload *habitlog.HabitLogPayload,
 ) (*habitlog.HabitLog, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
 
-	payload.LogDate = lib.NormalizeDate(payload.LogDate)
+	payload.LogDate = dateOrToday(ctx, payload.LogDate)
 
-	return s.habitLogRepo.Create(ctx, userID, payload)
+	return s.writeLog(ctx, userID, payload)
 }
 
 
@@ -88,8 +99,13 @@ func (s *HabitLogService) GetToday(
 	ctx context.Context,
 	userID uuid.UUID,
 ) ([]habitlog.HabitLog, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 
-	today := lib.NormalizeDate(time.Now().UTC())
+	today := lib.ClockFromContext(ctx).Today()
 
 	return s.habitLogRepo.GetByDate(ctx, userID, today)
 }
@@ -99,15 +115,14 @@ func (s *HabitLogService) GetThisWeek(
 	ctx context.Context,
 	userID uuid.UUID,
 ) ([]habitlog.HabitLog, error) {
-
-	now := lib.NormalizeDate(time.Now().UTC())
-
-	weekday := int(now.Weekday())
-	if weekday == 0 {
-		weekday = 7
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
 	}
 
-	start := now.AddDate(0, 0, -(weekday - 1))
+
+	clock := lib.ClockFromContext(ctx)
+	start := clock.WeekStart(clock.Today())
 	end := start.AddDate(0, 0, 6)
 
 	return s.habitLogRepo.GetByDateRange(ctx, userID, start, end)
@@ -146,8 +161,13 @@ func (s *HabitLogService) GetHabitHistory(
 	userID uuid.UUID,
 	habitID uuid.UUID,
 ) ([]habitlog.HabitLog, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
 	start := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-	end := lib.NormalizeDate(time.Now().UTC())
+	end := lib.ClockFromContext(ctx).Today()
 
 	return s.habitLogRepo.GetByHabit(ctx, userID, habitID, start, end)
 }
@@ -185,11 +205,70 @@ func (s *HabitLogService) MarkComplete(
 	habitID uuid.UUID,
 	payload *habitlog.HabitLogPayload,
 ) (*habitlog.HabitLog, error) {
-	payload.LogDate = lib.NormalizeDate(payload.LogDate)
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	payload.LogDate = dateOrToday(ctx, payload.LogDate)
 	payload.HabitID = habitID
 	payload.Completed = true
+	payload.Status = habitlog.Completed
+
+	return s.writeLog(ctx, userID, payload)
+}
+
+// SetStatus records the given status for a habit on a day, replacing any
+// status logged earlier for that day
+func (s *HabitLogService) SetStatus(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	logDate time.Time,
+	status habitlog.Status,
+	value *float64,
+	note *string,
+) (*habitlog.HabitLog, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	payload := &habitlog.HabitLogPayload{
+		HabitID:   habitID,
+		LogDate:   dateOrToday(ctx, logDate),
+		Completed: status == habitlog.Completed,
+		Status:    status,
+		Value:     value,
+		Note:      note,
+	}
+
+	return s.writeLog(ctx, userID, payload)
+}
+
+// writeLog stores a log and updates everything derived from it in the same
+// transaction
+func (s *HabitLogService) writeLog(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *habitlog.HabitLogPayload,
+) (*habitlog.HabitLog, error) {
+	stampCompletion(ctx, payload)
+
+	var log *habitlog.HabitLog
+	err := s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		var err error
+		log, err = tx.HabitLog.Create(ctx, userID, payload)
+		if err != nil {
+			return err
+		}
 
-	log, err := s.habitLogRepo.Create(ctx, userID, payload)
+		h, err := tx.Habit.GetByID(ctx, payload.HabitID, userID)
+		if err != nil {
+			return sqlerr.WrapError(err, "habit")
+		}
+		return afterLogWrite(ctx, tx, s.xp, userID, h, log)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -197,14 +276,111 @@ func (s *HabitLogService) MarkComplete(
 	return log, nil
 }
 
+// afterLogWrite brings the state derived from a habit's logs in line with a
+// log that was just written. It must run on transaction-bound repositories.
+func afterLogWrite(
+	ctx context.Context,
+	tx *repository.Repositories,
+	xp XPRules,
+	userID uuid.UUID,
+	h *habit.Habit,
+	log *habitlog.HabitLog,
+) error {
+	if err := storeEndedChallenges(ctx, tx.Habit, userID); err != nil {
+		return err
+	}
+
+	if log.Completed {
+		if err := applyStreakFreezes(ctx, tx, userID, h, log.LogDate); err != nil {
+			return err
+		}
+	}
+
+	if err := updateStreaks(ctx, tx, userID, h, log); err != nil {
+		return err
+	}
+
+	if err := refreshDailyRollup(ctx, tx, userID, log.LogDate); err != nil {
+		return err
+	}
+
+	if err := invalidateStrength(ctx, tx.HabitStrength, h, log.LogDate); err != nil {
+		return err
+	}
+	if err := storeHabitStrengths(ctx, tx, userID, h); err != nil {
+		return err
+	}
+
+	if err := evaluateGoals(ctx, tx, userID, h.ID); err != nil {
+		return err
+	}
+
+	if err := awardCompletion(ctx, tx, xp, userID, h, log); err != nil {
+		return err
+	}
+
+	unlocked, err := evaluateAchievements(ctx, tx, userID, log.LogDate)
+	if err != nil {
+		return err
+	}
+
+	return awardAchievements(ctx, tx, xp, userID, unlocked)
+}
+
+// afterLogDelete brings the state derived from a habit's logs in line with a
+// log that was just deleted. It must run on transaction-bound repositories.
+func afterLogDelete(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+	logDate time.Time,
+) error {
+	if err := storeEndedChallenges(ctx, tx.Habit, userID); err != nil {
+		return err
+	}
+
+	if err := recomputeStreaks(ctx, tx, userID, h); err != nil {
+		return err
+	}
+
+	if err := refreshDailyRollup(ctx, tx, userID, logDate); err != nil {
+		return err
+	}
+
+	if err := invalidateStrength(ctx, tx.HabitStrength, h, logDate); err != nil {
+		return err
+	}
+	if err := storeHabitStrengths(ctx, tx, userID, h); err != nil {
+		return err
+	}
+
+	return reverseCompletion(ctx, tx, userID, h, logDate)
+}
+
 func (s *HabitLogService) UnmarkComplete(
 	ctx context.Context,
 	userID uuid.UUID,
 	habitID uuid.UUID,
 	logDate time.Time,
 ) error {
-	normalizedDate := lib.NormalizeDate(logDate)
-	return s.habitLogRepo.DeleteByHabitAndDate(ctx, userID, habitID, normalizedDate)
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	return s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		logDate = dateOrToday(ctx, logDate)
+		if err := tx.HabitLog.DeleteByHabitAndDate(ctx, userID, habitID, logDate); err != nil {
+			return s.wrapError(err)
+		}
+
+		h, err := tx.Habit.GetByID(ctx, habitID, userID)
+		if err != nil {
+			return sqlerr.WrapError(err, "habit")
+		}
+		return afterLogDelete(ctx, tx, userID, h, logDate)
+	})
 }
 
 func (s *HabitLogService) GetCompletions(
@@ -215,8 +391,16 @@ func (s *HabitLogService) GetCompletions(
 	endDate time.Time,
 	limit int,
 ) ([]habitlog.HabitLog, int, error) {
-	normalizedStart := lib.NormalizeDate(startDate)
-	normalizedEnd := lib.NormalizeDate(endDate)
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, 0, s.wrapError(err)
+	}
+
+	normalizedEnd := dateOrToday(ctx, endDate)
+	normalizedStart := normalizedEnd.AddDate(0, 0, -365)
+	if !startDate.IsZero() {
+		normalizedStart = lib.NormalizeDate(startDate)
+	}
 
 	return s.habitLogRepo.GetByHabitWithLimit(ctx, userID, habitID, normalizedStart, normalizedEnd, limit)
 }
@@ -227,35 +411,42 @@ func (s *HabitLogService) GetCompletionHistory(
 	habitID uuid.UUID,
 	year *int,
 	allTime bool,
-) ([]time.Time, int, int, error) {
+) ([]time.Time, []time.Time, int, int, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, nil, 0, 0, s.wrapError(err)
+	}
+
 	var startDate, endDate time.Time
 
 	if allTime {
 		// Get all time history
 		startDate = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
-		endDate = lib.NormalizeDate(time.Now().UTC())
+		endDate = lib.ClockFromContext(ctx).Today()
 	} else if year != nil {
 		// Get specific year
 		startDate = time.Date(*year, 1, 1, 0, 0, 0, 0, time.UTC)
 		endDate = time.Date(*year, 12, 31, 23, 59, 59, 0, time.UTC)
 	} else {
 		// Default to current year
-		now := time.Now().UTC()
-		currentYear := now.Year()
+		currentYear := lib.ClockFromContext(ctx).Today().Year()
 		startDate = time.Date(currentYear, 1, 1, 0, 0, 0, 0, time.UTC)
 		endDate = time.Date(currentYear, 12, 31, 23, 59, 59, 0, time.UTC)
 	}
 
 	logs, err := s.habitLogRepo.GetByHabit(ctx, userID, habitID, startDate, endDate)
 	if err != nil {
-		return nil, 0, 0, err
+		return nil, nil, 0, 0, err
 	}
 
-	// Extract only completed logs and get dates
+	// Extract completed logs and days covered by a streak freeze
 	dates := make([]time.Time, 0)
+	frozenDates := make([]time.Time, 0)
 	for _, log := range logs {
 		if log.Completed {
 			dates = append(dates, log.LogDate)
+		} else if log.Status == habitlog.Frozen {
+			frozenDates = append(frozenDates, log.LogDate)
 		}
 	}
 
@@ -263,5 +454,5 @@ func (s *HabitLogService) GetCompletionHistory(
 	totalDays := int(endDate.Sub(startDate).Hours()/24) + 1
 	completedDays := len(dates)
 
-	return dates, totalDays, completedDays, nil
+	return dates, frozenDates, totalDays, completedDays, nil
 }
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/reche13/habitum/internal/model/analytics"
+)
+
+// insightMessages is the English catalog of insight texts. Keys are
+// "insights.<rule>.title" and "insights.<rule>.message", with ".<variant>"
+// appended to the message key for rules that have variants. Arguments are
+// written as {name}.
+var insightMessages = map[string]string{
+	"insights.great_streak.title":   "Great Streak!",
+	"insights.great_streak.message": "{habit} has a {days}-day streak. Keep it up!",
+
+	"insights.inactive_habit.title":   "Get Back on Track",
+	"insights.inactive_habit.message": "{habit} has been inactive. Try to complete it today!",
+
+	"insights.best_day.title":   "Best Day",
+	"insights.best_day.message": "{day} is your most productive day with {rate}% completion rate!",
+
+	"insights.high_consistency.title":   "Excellent Consistency",
+	"insights.high_consistency.message": "You're maintaining an {rate}%+ average completion rate across all habits!",
+
+	"insights.low_consistency.title":   "Room for Improvement",
+	"insights.low_consistency.message": "Your average completion rate is below {rate}%. Focus on consistency!",
+
+	"insights.paired_habits.title":            "Habits That Go Together",
+	"insights.paired_habits.message.same_day": "On days you complete {habit}, you complete {otherHabit} {rateWith}% of the time, versus {rateWithout}% otherwise. Pair them up!",
+	"insights.paired_habits.message.next_day": "The day after you complete {habit}, you complete {otherHabit} {rateWith}% of the time, versus {rateWithout}% otherwise. Pair them up!",
+
+	"insights.competing_habits.title":            "Competing Habits",
+	"insights.competing_habits.message.same_day": "On days you complete {habit}, you complete {otherHabit} {rateWith}% of the time, versus {rateWithout}% otherwise. Try giving them separate times.",
+	"insights.competing_habits.message.next_day": "The day after you complete {habit}, you complete {otherHabit} {rateWith}% of the time, versus {rateWithout}% otherwise. Try giving them separate times.",
+
+	"insights.usual_time.title":   "Your Rhythm",
+	"insights.usual_time.message": "You usually complete {habit} around {time}.",
+}
+
+// renderInsightMessage fills the arguments into the English text of key,
+// falling back to the key itself when the catalog has no text for it
+func renderInsightMessage(key string, args map[string]string) string {
+	text, ok := insightMessages[key]
+	if !ok {
+		return key
+	}
+
+	pairs := make([]string, 0, 2*len(args))
+	for name, value := range args {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// newInsight builds the insight a rule's candidate describes
+func newInsight(id string, rule insightRule, c insightCandidate) analytics.Insight {
+	titleKey := "insights." + rule.ID + ".title"
+	messageKey := "insights." + rule.ID + ".message"
+	if c.Variant != "" {
+		messageKey += "." + c.Variant
+	}
+
+	args := c.Args
+	if args == nil {
+		args = map[string]string{}
+	}
+
+	return analytics.Insight{
+		ID:          id,
+		RuleID:      rule.ID,
+		Type:        rule.Type,
+		Title:       renderInsightMessage(titleKey, args),
+		Description: renderInsightMessage(messageKey, args),
+		Priority:    rule.Priority,
+		TitleKey:    titleKey,
+		MessageKey:  messageKey,
+		Args:        args,
+	}
+}
@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/insight"
+)
+
+// Insights come from a registry of rules. Each rule looks at the user's data
+// and yields candidates; a candidate becomes an insight with a stable ID
+// (the rule's ID, followed by the candidate's subject when it has one) that
+// the user can dismiss or snooze. Text is given as message keys with
+// arguments, along with the English rendering.
+
+// insightInput is what insight rules look at. Habits are the user's active
+// ones, with current streaks refreshed.
+type insightInput struct {
+	ctx    context.Context
+	s      *AnalyticsService
+	userID uuid.UUID
+	habits []habit.Habit
+	pauses *PauseSchedule
+	clock  lib.Clock
+}
+
+// insightCandidate is one occurrence of a rule's insight
+type insightCandidate struct {
+	Subject string            // Distinguishes occurrences of the rule, e.g. a habit ID
+	Variant string            // Picks one of the rule's messages, when it has several
+	Args    map[string]string // Message arguments
+}
+
+// insightParams holds a rule's thresholds
+type insightParams map[string]float64
+
+type insightRule struct {
+	ID       string
+	Type     string // "positive", "suggestion", "achievement"
+	Priority string // "high", "medium", "low"
+	// How long a dismissed insight of this rule stays hidden
+	Cooldown time.Duration
+	Params   insightParams
+	Generate func(in *insightInput, params insightParams) ([]insightCandidate, error)
+}
+
+// insightRules is the registry of insight rules, in the order their insights
+// of the same priority are listed
+var insightRules = []insightRule{
+	{
+		ID:       "great_streak",
+		Type:     "positive",
+		Priority: "high",
+		Cooldown: 7 * 24 * time.Hour,
+		Params:   insightParams{"minStreak": 7},
+		Generate: greatStreakInsight,
+	},
+	{
+		ID:       "inactive_habit",
+		Type:     "suggestion",
+		Priority: "medium",
+		Cooldown: 3 * 24 * time.Hour,
+		Params:   insightParams{"windowDays": 30, "maxCompletionRate": 50},
+		Generate: inactiveHabitInsights,
+	},
+	{
+		ID:       "best_day",
+		Type:     "positive",
+		Priority: "low",
+		Cooldown: 14 * 24 * time.Hour,
+		Params:   insightParams{"minCompletionRate": 70},
+		Generate: bestDayInsight,
+	},
+	{
+		ID:       "high_consistency",
+		Type:     "achievement",
+		Priority: "high",
+		Cooldown: 14 * 24 * time.Hour,
+		Params:   insightParams{"minCompletionRate": 80},
+		Generate: highConsistencyInsight,
+	},
+	{
+		ID:       "low_consistency",
+		Type:     "suggestion",
+		Priority: "medium",
+		Cooldown: 7 * 24 * time.Hour,
+		Params:   insightParams{"maxCompletionRate": 50},
+		Generate: lowConsistencyInsight,
+	},
+	{
+		ID:       "paired_habits",
+		Type:     "suggestion",
+		Priority: "low",
+		Cooldown: 30 * 24 * time.Hour,
+		Generate: pairedHabitsInsight,
+	},
+	{
+		ID:       "competing_habits",
+		Type:     "suggestion",
+		Priority: "low",
+		Cooldown: 30 * 24 * time.Hour,
+		Generate: competingHabitsInsight,
+	},
+	{
+		ID:       "usual_time",
+		Type:     "positive",
+		Priority: "low",
+		Cooldown: 30 * 24 * time.Hour,
+		Generate: usualTimeInsight,
+	},
+}
+
+// findInsightRule returns the rule an insight ID belongs to
+func findInsightRule(insightID string) (*insightRule, bool) {
+	ruleID, _, _ := strings.Cut(insightID, ":")
+	for i := range insightRules {
+		if insightRules[i].ID == ruleID {
+			return &insightRules[i], true
+		}
+	}
+	return nil, false
+}
+
+var insightPriorityOrder = map[string]int{"high": 0, "medium": 1, "low": 2}
+
+func (s *AnalyticsService) GetInsights(ctx context.Context, userID uuid.UUID) (*analytics.InsightsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	insights := make([]analytics.Insight, 0)
+
+	// Get all active habits
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	// Filter out archived habits
+	activeHabits := make([]habit.Habit, 0)
+	for _, h := range habits {
+		if h.ArchivedAt == nil {
+			activeHabits = append(activeHabits, h)
+		}
+	}
+
+	if len(activeHabits) == 0 {
+		return &analytics.InsightsResponse{Data: insights}, nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	dismissals, err := s.insightRepo.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	dismissed := make(map[string]insight.Dismissal, len(dismissals))
+	for _, d := range dismissals {
+		dismissed[d.InsightID] = d
+	}
+
+	in := &insightInput{
+		ctx:    ctx,
+		s:      s,
+		userID: userID,
+		habits: activeHabits,
+		pauses: pauses,
+		clock:  clock,
+	}
+	now := clock.Now()
+
+	for _, rule := range insightRules {
+		candidates, err := rule.Generate(in, rule.Params)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+
+		for _, c := range candidates {
+			id := rule.ID
+			if c.Subject != "" {
+				id += ":" + c.Subject
+			}
+			if d, ok := dismissed[id]; ok && d.HiddenAt(now, rule.Cooldown) {
+				continue
+			}
+			insights = append(insights, newInsight(id, rule, c))
+		}
+	}
+
+	sort.SliceStable(insights, func(i, j int) bool {
+		return insightPriorityOrder[insights[i].Priority] < insightPriorityOrder[insights[j].Priority]
+	})
+
+	return &analytics.InsightsResponse{
+		Data: insights,
+	}, nil
+}
+
+// DismissInsight hides an insight for its rule's cooldown
+func (s *AnalyticsService) DismissInsight(ctx context.Context, userID uuid.UUID, insightID string) (*insight.Dismissal, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if _, ok := findInsightRule(insightID); !ok {
+		return nil, errs.NewNotFoundError("insight not found")
+	}
+
+	d, err := s.insightRepo.Upsert(ctx, userID, insightID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	return d, nil
+}
+
+// SnoozeInsight hides an insight until the start of the given day, or for the
+// given number of days
+func (s *AnalyticsService) SnoozeInsight(
+	ctx context.Context,
+	userID uuid.UUID,
+	insightID string,
+	payload *insight.SnoozePayload,
+) (*insight.Dismissal, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	if _, ok := findInsightRule(insightID); !ok {
+		return nil, errs.NewNotFoundError("insight not found")
+	}
+
+	if payload.Until == "" && payload.Days == 0 {
+		return nil, errs.NewBadRequestError("Either until or days is required")
+	}
+
+	until := clock.Now().AddDate(0, 0, payload.Days)
+	if payload.Until != "" {
+		date, err := time.Parse("2006-01-02", payload.Until)
+		if err != nil {
+			return nil, errs.NewBadRequestError("Invalid until date. Use YYYY-MM-DD")
+		}
+		until = time.Date(date.Year(), date.Month(), date.Day(), clock.DayEndsAt(), 0, 0, 0, clock.Location())
+		if !until.After(clock.Now()) {
+			return nil, errs.NewBadRequestError("until must be in the future")
+		}
+	}
+
+	d, err := s.insightRepo.Upsert(ctx, userID, insightID, &until)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	return d, nil
+}
+
+func greatStreakInsight(in *insightInput, params insightParams) ([]insightCandidate, error) {
+	var best *habit.Habit
+	for i, h := range in.habits {
+		if best == nil || h.CurrentStreak > best.CurrentStreak {
+			best = &in.habits[i]
+		}
+	}
+	if best == nil || float64(best.CurrentStreak) < params["minStreak"] {
+		return nil, nil
+	}
+
+	return []insightCandidate{{
+		Subject: best.ID.String(),
+		Args: map[string]string{
+			"habit": best.Name,
+			"days":  fmt.Sprintf("%d", best.CurrentStreak),
+		},
+	}}, nil
+}
+
+func inactiveHabitInsights(in *insightInput, params insightParams) ([]insightCandidate, error) {
+	now := in.clock.Today()
+	windowStart := now.AddDate(0, 0, -int(params["windowDays"]))
+
+	candidates := make([]insightCandidate, 0)
+	for _, h := range in.habits {
+		// A paused habit, or a challenge that has not started, is not inactive
+		excluded := habitDayFilter(h, in.pauses)
+		if excluded(now) || h.CurrentStreak > 0 {
+			continue
+		}
+
+		// Check recent completion rate
+		logs, err := in.s.habitLogRepo.GetByHabit(in.ctx, in.userID, h.ID, windowStart, now)
+		if err != nil {
+			return nil, err
+		}
+
+		recentCompletions := 0
+		for _, log := range logs {
+			if log.Completed {
+				recentCompletions++
+			}
+		}
+
+		// Calculate expected completions (rough estimate), leaving out excluded days
+		scheduledDays := 0
+		for day := windowStart.AddDate(0, 0, 1); !day.After(now); day = day.AddDate(0, 0, 1) {
+			if !excluded(day) {
+				scheduledDays++
+			}
+		}
+
+		expectedCompletions := scheduledDays // for daily habits
+		if h.Frequency == habit.Weekly {
+			expectedCompletions = scheduledDays * h.WeeklyTarget() / 7 // roughly the target each week
+		}
+
+		completionRate := 0.0
+		if expectedCompletions > 0 {
+			completionRate = (float64(recentCompletions) / float64(expectedCompletions)) * 100
+		}
+
+		if completionRate < params["maxCompletionRate"] {
+			candidates = append(candidates, insightCandidate{
+				Subject: h.ID.String(),
+				Args:    map[string]string{"habit": h.Name},
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
+func bestDayInsight(in *insightInput, params insightParams) ([]insightCandidate, error) {
+	dayOfWeekData, err := in.s.GetDayOfWeekAnalysis(in.ctx, in.userID, analytics.RangeQuery{Period: "30d"})
+	if err != nil || len(dayOfWeekData.Data) == 0 {
+		return nil, err
+	}
+
+	bestDay := dayOfWeekData.Data[0]
+	for _, day := range dayOfWeekData.Data {
+		if day.CompletionRate > bestDay.CompletionRate {
+			bestDay = day
+		}
+	}
+	if bestDay.CompletionRate <= params["minCompletionRate"] {
+		return nil, nil
+	}
+
+	return []insightCandidate{{
+		Args: map[string]string{
+			"day":  bestDay.Day,
+			"rate": fmt.Sprintf("%.0f", bestDay.CompletionRate),
+		},
+	}}, nil
+}
+
+func highConsistencyInsight(in *insightInput, params insightParams) ([]insightCandidate, error) {
+	metrics, err := in.s.GetMetrics(in.ctx, in.userID, analytics.RangeQuery{})
+	if err != nil || metrics.AvgCompletionRate < params["minCompletionRate"] {
+		return nil, err
+	}
+
+	return []insightCandidate{{
+		Args: map[string]string{"rate": fmt.Sprintf("%.0f", params["minCompletionRate"])},
+	}}, nil
+}
+
+func lowConsistencyInsight(in *insightInput, params insightParams) ([]insightCandidate, error) {
+	metrics, err := in.s.GetMetrics(in.ctx, in.userID, analytics.RangeQuery{})
+	if err != nil || metrics.AvgCompletionRate >= params["maxCompletionRate"] {
+		return nil, err
+	}
+
+	return []insightCandidate{{
+		Args: map[string]string{"rate": fmt.Sprintf("%.0f", params["maxCompletionRate"])},
+	}}, nil
+}
+
+// correlationCandidate describes the strongest correlation of the given sign
+func correlationCandidate(in *insightInput, positive bool) ([]insightCandidate, error) {
+	correlations, err := in.s.GetCorrelations(in.ctx, in.userID, analytics.RangeQuery{Period: "90d"}, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	points := correlations.Negative
+	if positive {
+		points = correlations.Positive
+	}
+	if len(points) == 0 {
+		return nil, nil
+	}
+
+	c := points[0]
+	return []insightCandidate{{
+		Subject: c.Type + ":" + c.HabitID + ":" + c.OtherHabitID,
+		Variant: c.Type,
+		Args: map[string]string{
+			"habit":       c.HabitName,
+			"otherHabit":  c.OtherHabitName,
+			"rateWith":    fmt.Sprintf("%.0f", c.RateWith),
+			"rateWithout": fmt.Sprintf("%.0f", c.RateWithout),
+		},
+	}}, nil
+}
+
+func pairedHabitsInsight(in *insightInput, _ insightParams) ([]insightCandidate, error) {
+	return correlationCandidate(in, true)
+}
+
+func competingHabitsInsight(in *insightInput, _ insightParams) ([]insightCandidate, error) {
+	return correlationCandidate(in, false)
+}
+
+// usualTimeInsight describes the usual completion time of the habit logged
+// with a time most often
+func usualTimeInsight(in *insightInput, _ insightParams) ([]insightCandidate, error) {
+	timeOfDay, err := in.s.GetTimeOfDayAnalysis(in.ctx, in.userID, analytics.RangeQuery{Period: "30d"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	activeHabitIDs := make(map[string]bool, len(in.habits))
+	for _, h := range in.habits {
+		activeHabitIDs[h.ID.String()] = true
+	}
+
+	var usual *analytics.HabitTimeOfDay
+	for i, h := range timeOfDay.Habits {
+		if h.TypicalTime != nil && activeHabitIDs[h.HabitID] && (usual == nil || h.Completions > usual.Completions) {
+			usual = &timeOfDay.Habits[i]
+		}
+	}
+	if usual == nil {
+		return nil, nil
+	}
+
+	return []insightCandidate{{
+		Subject: usual.HabitID,
+		Args: map[string]string{
+			"habit": usual.Name,
+			"time":  *usual.TypicalTime,
+		},
+	}}, nil
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/pause"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/sqlerr"
+)
+
+type PauseService struct {
+	*BaseService
+	repos *repository.Repositories
+}
+
+func NewPauseService(repos *repository.Repositories) *PauseService {
+	return &PauseService{
+		BaseService: &BaseService{
+			resourceName: "pause",
+		},
+		repos: repos,
+	}
+}
+
+// CreatePause pauses a single habit, or every habit when no habit ID is given
+func (s *PauseService) CreatePause(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *pause.CreatePausePayload,
+) (*pause.Pause, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	startDate, endDate, err := parsePauseRange(payload.StartDate, payload.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.HabitID != nil {
+		// Verify habit exists and belongs to user
+		if _, err := s.repos.Habit.GetByID(ctx, *payload.HabitID, userID); err != nil {
+			return nil, sqlerr.WrapError(err, "habit")
+		}
+	}
+
+	var p *pause.Pause
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		p, err = tx.Pause.Create(ctx, userID, payload.HabitID, startDate, endDate, payload.Reason)
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		// Paused habits are no longer scheduled
+		if err := s.invalidateFrom(ctx, tx, userID, p.HabitID, startDate); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *PauseService) GetPauses(ctx context.Context, userID uuid.UUID) ([]pause.Pause, error) {
+	pauses, err := s.repos.Pause.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return pauses, nil
+}
+
+// UpdatePause changes the end date (for example to resume early) or the reason
+func (s *PauseService) UpdatePause(
+	ctx context.Context,
+	userID uuid.UUID,
+	pauseID uuid.UUID,
+	payload *pause.UpdatePausePayload,
+) (*pause.Pause, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	existing, err := s.repos.Pause.GetByID(ctx, pauseID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	var endDate *time.Time
+	if payload.EndDate != nil {
+		parsed, err := time.Parse("2006-01-02", *payload.EndDate)
+		if err != nil {
+			return nil, errs.NewBadRequestError("Invalid end_date format. Use YYYY-MM-DD")
+		}
+		if parsed.Before(existing.StartDate) {
+			return nil, errs.NewBadRequestError("end_date must not be before start_date")
+		}
+		endDate = &parsed
+	}
+
+	var p *pause.Pause
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		p, err = tx.Pause.Update(ctx, pauseID, userID, endDate, payload.Reason)
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		if err := s.invalidateFrom(ctx, tx, userID, existing.HabitID, existing.StartDate); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *PauseService) DeletePause(ctx context.Context, userID uuid.UUID, pauseID uuid.UUID) error {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	// Verify pause exists and belongs to user
+	existing, err := s.repos.Pause.GetByID(ctx, pauseID, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	return s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if err := tx.Pause.Delete(ctx, pauseID, userID); err != nil {
+			return s.wrapError(err)
+		}
+
+		if err := s.invalidateFrom(ctx, tx, userID, existing.HabitID, existing.StartDate); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
+}
+
+// invalidateFrom drops the rollups and strength scores a pause starting on
+// startDate changes, and recomputes the stored streaks of the paused habit,
+// or of every habit for an account-wide pause. Weekly scores depend on the
+// whole week, which starts at most six days earlier.
+func (s *PauseService) invalidateFrom(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	habitID *uuid.UUID,
+	startDate time.Time,
+) error {
+	if err := dropDailyRollups(ctx, tx.DailyRollup, userID, startDate); err != nil {
+		return err
+	}
+	if err := tx.HabitStrength.DeleteFromForUser(ctx, userID, startDate.AddDate(0, 0, -6)); err != nil {
+		return err
+	}
+
+	if habitID == nil {
+		return recomputeUserStreaks(ctx, tx, userID)
+	}
+
+	h, err := tx.Habit.GetByID(ctx, *habitID, userID)
+	if err != nil {
+		return err
+	}
+	return recomputeStreaks(ctx, tx, userID, h)
+}
+
+func parsePauseRange(start string, end *string) (time.Time, *time.Time, error) {
+	startDate, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return time.Time{}, nil, errs.NewBadRequestError("Invalid start_date format. Use YYYY-MM-DD")
+	}
+
+	if end == nil {
+		return startDate, nil, nil
+	}
+
+	endDate, err := time.Parse("2006-01-02", *end)
+	if err != nil {
+		return time.Time{}, nil, errs.NewBadRequestError("Invalid end_date format. Use YYYY-MM-DD")
+	}
+
+	if endDate.Before(startDate) {
+		return time.Time{}, nil, errs.NewBadRequestError("end_date must not be before start_date")
+	}
+
+	return startDate, &endDate, nil
+}
+
+// PauseSchedule answers whether a habit is paused on a given date, taking
+// both per-habit and account-wide pauses into account
+type PauseSchedule struct {
+	accountWide []pause.Pause
+	byHabit     map[uuid.UUID][]pause.Pause
+}
+
+func NewPauseSchedule(pauses []pause.Pause) *PauseSchedule {
+	schedule := &PauseSchedule{
+		byHabit: make(map[uuid.UUID][]pause.Pause),
+	}
+
+	for _, p := range pauses {
+		if p.HabitID == nil {
+			schedule.accountWide = append(schedule.accountWide, p)
+		} else {
+			schedule.byHabit[*p.HabitID] = append(schedule.byHabit[*p.HabitID], p)
+		}
+	}
+
+	return schedule
+}
+
+// loadPauseSchedule loads all of the user's pauses
+func loadPauseSchedule(ctx context.Context, pauseRepo *repository.PauseRepository, userID uuid.UUID) (*PauseSchedule, error) {
+	pauses, err := pauseRepo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPauseSchedule(pauses), nil
+}
+
+// IsPaused reports whether the habit is paused on date. A nil schedule has no pauses.
+func (s *PauseSchedule) IsPaused(habitID uuid.UUID, date time.Time) bool {
+	if s == nil {
+		return false
+	}
+
+	date = lib.NormalizeDate(date)
+
+	for i := range s.accountWide {
+		if s.accountWide[i].Covers(date) {
+			return true
+		}
+	}
+
+	pauses := s.byHabit[habitID]
+	for i := range pauses {
+		if pauses[i].Covers(date) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ForHabit returns a filter that excludes the habit's paused days
+func (s *PauseSchedule) ForHabit(habitID uuid.UUID) DayFilter {
+	return func(date time.Time) bool {
+		return s.IsPaused(habitID, date)
+	}
+}
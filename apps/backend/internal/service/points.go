@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/config"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/achievement"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/points"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// XPRules decides how much XP, and as many points, a completion or an
+// achievement earns, and how much XP each level needs
+type XPRules struct {
+	CompletionXP        int
+	EasyMultiplier      float64
+	MediumMultiplier    float64
+	HardMultiplier      float64
+	StreakBonusPerDay   float64
+	MaxStreakMultiplier float64
+	AchievementXP       int
+	LevelXP             int
+}
+
+// NewXPRules fills the rules from the config, using the defaults for
+// anything left unset
+func NewXPRules(cfg config.XPConfig) XPRules {
+	rules := XPRules{
+		CompletionXP:        10,
+		EasyMultiplier:      1,
+		MediumMultiplier:    1.5,
+		HardMultiplier:      2,
+		StreakBonusPerDay:   0.02,
+		MaxStreakMultiplier: 2,
+		AchievementXP:       50,
+		LevelXP:             100,
+	}
+
+	if cfg.CompletionXP > 0 {
+		rules.CompletionXP = cfg.CompletionXP
+	}
+	if cfg.EasyMultiplier > 0 {
+		rules.EasyMultiplier = cfg.EasyMultiplier
+	}
+	if cfg.MediumMultiplier > 0 {
+		rules.MediumMultiplier = cfg.MediumMultiplier
+	}
+	if cfg.HardMultiplier > 0 {
+		rules.HardMultiplier = cfg.HardMultiplier
+	}
+	if cfg.StreakBonusPerDay > 0 {
+		rules.StreakBonusPerDay = cfg.StreakBonusPerDay
+	}
+	if cfg.MaxStreakMultiplier > 0 {
+		rules.MaxStreakMultiplier = cfg.MaxStreakMultiplier
+	}
+	if cfg.AchievementXP > 0 {
+		rules.AchievementXP = cfg.AchievementXP
+	}
+	if cfg.LevelXP > 0 {
+		rules.LevelXP = cfg.LevelXP
+	}
+
+	return rules
+}
+
+func (r XPRules) difficultyMultiplier(d habit.Difficulty) float64 {
+	switch d {
+	case habit.Easy:
+		return r.EasyMultiplier
+	case habit.Hard:
+		return r.HardMultiplier
+	default:
+		return r.MediumMultiplier
+	}
+}
+
+// completionXP is the XP a completion earns on a habit of the given
+// difficulty that is streak days into its current streak
+func (r XPRules) completionXP(d habit.Difficulty, streak int) int {
+	streakMultiplier := 1.0
+	if streak > 1 {
+		streakMultiplier = min(1+float64(streak-1)*r.StreakBonusPerDay, r.MaxStreakMultiplier)
+	}
+
+	return int(math.Round(float64(r.CompletionXP) * r.difficultyMultiplier(d) * streakMultiplier))
+}
+
+// levelStart is the total XP at which a level starts. Level 1 starts at 0 and
+// every level needs LevelXP more than the one before.
+func (r XPRules) levelStart(level int) int {
+	return r.LevelXP * level * (level - 1) / 2
+}
+
+// level returns the level reached with the given total XP
+func (r XPRules) level(xp int) int {
+	level := 1
+	for r.levelStart(level+1) <= xp {
+		level++
+	}
+	return level
+}
+
+// awardCompletion keeps the ledger in line with a log that was just written:
+// a completed log earns its XP once, and a log that is no longer completed
+// gives back what it earned. It must run on transaction-bound repositories,
+// after streaks were updated.
+func awardCompletion(
+	ctx context.Context,
+	tx *repository.Repositories,
+	xp XPRules,
+	userID uuid.UUID,
+	h *habit.Habit,
+	log *habitlog.HabitLog,
+) error {
+	if !log.Completed {
+		return reverseCompletion(ctx, tx, userID, h, log.LogDate)
+	}
+
+	earned, err := tx.Points.CompletionBalance(ctx, h.ID, log.LogDate)
+	if err != nil {
+		return err
+	}
+	if earned.XP > 0 {
+		return nil
+	}
+
+	// Backdated completions do not extend the current streak
+	streak := 0
+	if h.LastCompletedOn != nil && lib.NormalizeDate(*h.LastCompletedOn).Equal(lib.NormalizeDate(log.LogDate)) {
+		streak = h.CurrentStreak
+	}
+
+	amount := xp.completionXP(h.Difficulty, streak)
+	logDate := log.LogDate
+	_, err = tx.Points.AddEntry(ctx, &points.LedgerEntry{
+		UserID:      userID,
+		Source:      points.SourceCompletion,
+		Points:      amount,
+		XP:          amount,
+		Description: "Completed " + h.Name,
+		HabitID:     &h.ID,
+		LogDate:     &logDate,
+	})
+	return err
+}
+
+// reverseCompletion gives back what a habit's log for a day earned, if it
+// still counts. It must run on transaction-bound repositories.
+func reverseCompletion(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+	logDate time.Time,
+) error {
+	earned, err := tx.Points.CompletionBalance(ctx, h.ID, logDate)
+	if err != nil {
+		return err
+	}
+	if earned.Points == 0 && earned.XP == 0 {
+		return nil
+	}
+
+	_, err = tx.Points.AddEntry(ctx, &points.LedgerEntry{
+		UserID:      userID,
+		Source:      points.SourceCompletion,
+		Points:      -earned.Points,
+		XP:          -earned.XP,
+		Description: "Undid " + h.Name,
+		HabitID:     &h.ID,
+		LogDate:     &logDate,
+	})
+	return err
+}
+
+// awardAchievements credits the XP for newly unlocked achievements. It must
+// run on transaction-bound repositories.
+func awardAchievements(
+	ctx context.Context,
+	tx *repository.Repositories,
+	xp XPRules,
+	userID uuid.UUID,
+	unlocked []achievement.Achievement,
+) error {
+	definitions := achievementDefinitions()
+	for _, a := range unlocked {
+		key := a.Key
+		_, err := tx.Points.AddEntry(ctx, &points.LedgerEntry{
+			UserID:         userID,
+			Source:         points.SourceAchievement,
+			Points:         xp.AchievementXP,
+			XP:             xp.AchievementXP,
+			Description:    "Unlocked " + definitions[key].Name,
+			AchievementKey: &key,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type PointsService struct {
+	*BaseService
+	repos *repository.Repositories
+	xp    XPRules
+}
+
+func NewPointsService(repos *repository.Repositories, xp XPRules) *PointsService {
+	return &PointsService{
+		BaseService: &BaseService{
+			resourceName: "points",
+		},
+		repos: repos,
+		xp:    xp,
+	}
+}
+
+// GetSummary returns the user's spendable points, XP and level
+func (s *PointsService) GetSummary(ctx context.Context, userID uuid.UUID) (*points.SummaryResponse, error) {
+	balance, err := s.repos.Points.Balance(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return s.summary(balance), nil
+}
+
+func (s *PointsService) summary(balance *points.Balance) *points.SummaryResponse {
+	level := s.xp.level(balance.XP)
+	levelXP := s.xp.levelStart(level)
+	nextLevelXP := s.xp.levelStart(level + 1)
+
+	return &points.SummaryResponse{
+		Points:        balance.Points,
+		XP:            balance.XP,
+		Level:         level,
+		LevelXP:       levelXP,
+		NextLevelXP:   nextLevelXP,
+		LevelProgress: (float64(balance.XP-levelXP) / float64(nextLevelXP-levelXP)) * 100,
+	}
+}
+
+// GetLedger returns a page of the user's ledger, newest first, and the total
+// number of entries
+func (s *PointsService) GetLedger(
+	ctx context.Context,
+	userID uuid.UUID,
+	page int,
+	limit int,
+) ([]points.LedgerEntry, int, error) {
+	entries, total, err := s.repos.Points.ListEntries(ctx, userID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, s.wrapError(err)
+	}
+
+	return entries, total, nil
+}
+
+type RewardService struct {
+	*BaseService
+	repos *repository.Repositories
+}
+
+func NewRewardService(repos *repository.Repositories) *RewardService {
+	return &RewardService{
+		BaseService: &BaseService{
+			resourceName: "reward",
+		},
+		repos: repos,
+	}
+}
+
+func (s *RewardService) CreateReward(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *points.CreateRewardPayload,
+) (*points.Reward, error) {
+	rw, err := s.repos.Reward.Create(ctx, userID, payload)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return rw, nil
+}
+
+func (s *RewardService) GetRewards(ctx context.Context, userID uuid.UUID) ([]points.Reward, error) {
+	rewards, err := s.repos.Reward.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return rewards, nil
+}
+
+func (s *RewardService) GetReward(ctx context.Context, userID uuid.UUID, rewardID uuid.UUID) (*points.Reward, error) {
+	rw, err := s.repos.Reward.GetByID(ctx, rewardID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return rw, nil
+}
+
+func (s *RewardService) UpdateReward(
+	ctx context.Context,
+	userID uuid.UUID,
+	rewardID uuid.UUID,
+	payload *points.UpdateRewardPayload,
+) (*points.Reward, error) {
+	rw, err := s.repos.Reward.Update(ctx, rewardID, userID, payload)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return rw, nil
+}
+
+func (s *RewardService) DeleteReward(ctx context.Context, userID uuid.UUID, rewardID uuid.UUID) error {
+	// Verify reward exists and belongs to user
+	_, err := s.repos.Reward.GetByID(ctx, rewardID, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	// Ledger entries keep their description after the reward is gone
+	if err := s.repos.Reward.Delete(ctx, rewardID, userID); err != nil {
+		return s.wrapError(err)
+	}
+
+	return nil
+}
+
+// RedeemReward spends the reward's cost from the user's points
+func (s *RewardService) RedeemReward(ctx context.Context, userID uuid.UUID, rewardID uuid.UUID) (*points.RedeemResponse, error) {
+	var response *points.RedeemResponse
+	err := s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if err := tx.Points.LockUser(ctx, userID); err != nil {
+			return s.wrapError(err)
+		}
+
+		rw, err := tx.Reward.GetByID(ctx, rewardID, userID)
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		balance, err := tx.Points.Balance(ctx, userID)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		if balance.Points < rw.Cost {
+			return errs.NewBadRequestError(fmt.Sprintf("Not enough points: %s costs %d and you have %d", rw.Name, rw.Cost, balance.Points))
+		}
+
+		entry, err := tx.Points.AddEntry(ctx, &points.LedgerEntry{
+			UserID:      userID,
+			Source:      points.SourceReward,
+			Points:      -rw.Cost,
+			Description: "Redeemed " + rw.Name,
+			RewardID:    &rw.ID,
+		})
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		response = &points.RedeemResponse{
+			Reward:  *rw,
+			Entry:   *entry,
+			Balance: balance.Points - rw.Cost,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
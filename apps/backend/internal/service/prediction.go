@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// A habit's chance of being completed today blends how often it was done on
+// the same day of the week lately, how often it was done over the last two
+// weeks, and its strength score
+const (
+	predictionWeekdayWeight  = 0.4
+	predictionRecentWeight   = 0.35
+	predictionStrengthWeight = 0.25
+
+	predictionWeekdaySamples = 8  // Same weekdays looked back on
+	predictionRecentDays     = 14 // Days looked back on for the recent rate
+
+	// A running streak is at risk when its habit is less likely than this to be completed
+	streakRiskThreshold = 0.5
+)
+
+// predictionFeatures holds what a habit's prediction is based on
+type predictionFeatures struct {
+	weekdayRate float64 // 0-1
+	recentRate  float64 // 0-1
+	strength    float64 // 0-100
+}
+
+// predictCompletion returns the chance (0-1) that a habit with the given
+// features is completed today
+func predictCompletion(f predictionFeatures) float64 {
+	p := predictionWeekdayWeight*f.weekdayRate +
+		predictionRecentWeight*f.recentRate +
+		predictionStrengthWeight*(f.strength/100)
+	return min(max(p, 0), 1)
+}
+
+// smoothedRate returns done out of scheduled, pulled towards one half so a
+// couple of days of history do not predict certainty
+func smoothedRate(done, scheduled int) float64 {
+	return (float64(done) + 1) / (float64(scheduled) + 2)
+}
+
+// isStreakAtRisk reports whether the habit's running streak may break today:
+// the habit is unlikely to be completed or, for a weekly habit, every day left
+// in the week is needed for the remaining completions
+func isStreakAtRisk(h habit.Habit, probability float64, remaining, daysLeft int) bool {
+	if h.CurrentStreak == 0 {
+		return false
+	}
+	return probability < streakRiskThreshold || (h.Frequency == habit.Weekly && remaining >= daysLeft)
+}
+
+// habitPredictionFeatures computes the habit's features from its logs of the
+// days before today. excluded should include excused days.
+func habitPredictionFeatures(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	excluded DayFilter,
+	clock lib.Clock,
+	today time.Time,
+	strength float64,
+) predictionFeatures {
+	completed := make(map[string]bool)
+	for _, log := range logs {
+		if log.Completed {
+			completed[dateKey(log.LogDate)] = true
+		}
+	}
+
+	scheduled := func(day time.Time) bool {
+		return isHabitActiveOn(clock, h, day) && !excluded(day)
+	}
+
+	weekdayDone, weekdayScheduled := 0, 0
+	for i := 1; i <= predictionWeekdaySamples; i++ {
+		day := today.AddDate(0, 0, -7*i)
+		if completed[dateKey(day)] {
+			weekdayDone++
+			weekdayScheduled++
+		} else if scheduled(day) {
+			weekdayScheduled++
+		}
+	}
+
+	recentDone, recentScheduled := 0, 0
+	for i := 1; i <= predictionRecentDays; i++ {
+		day := today.AddDate(0, 0, -i)
+		if completed[dateKey(day)] {
+			recentDone++
+			recentScheduled++
+		} else if scheduled(day) {
+			recentScheduled++
+		}
+	}
+
+	return predictionFeatures{
+		weekdayRate: smoothedRate(weekdayDone, weekdayScheduled),
+		recentRate:  smoothedRate(recentDone, recentScheduled),
+		strength:    strength,
+	}
+}
+
+// predictToday predicts today's outcome for each of the habits scheduled
+// today, keyed by habit. Weekly habits that met this week's target are not
+// scheduled. Streaks must already be refreshed.
+func predictToday(
+	ctx context.Context,
+	habitLogRepo *repository.HabitLogRepository,
+	strengthRepo *repository.HabitStrengthRepository,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	pauses *PauseSchedule,
+) (map[uuid.UUID]analytics.PredictionDataPoint, error) {
+	predictions := make(map[uuid.UUID]analytics.PredictionDataPoint, len(habits))
+	if len(habits) == 0 {
+		return predictions, nil
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	today := clock.Today()
+	weekStart := clock.WeekStart(today)
+	yesterday := today.AddDate(0, 0, -1)
+
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+
+	from := today.AddDate(0, 0, -7*predictionWeekdaySamples)
+	if weekStart.Before(from) {
+		from = weekStart
+	}
+	logs, err := habitLogRepo.GetByHabits(ctx, userID, habitIDs, from, today)
+	if err != nil {
+		return nil, err
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	// Yesterday's score, since today's only moves once today is completed
+	strengths, err := loadHabitStrengths(ctx, strengthRepo, habitLogRepo, userID, habits, pauses, yesterday, yesterday)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range habits {
+		habitLogs := logsByHabit[h.ID]
+		excluded := withExcusedDays(habitDayFilter(h, pauses), habitLogs)
+
+		completedToday := false
+		completedThisWeek := 0
+		for _, log := range habitLogs {
+			if !log.Completed || log.LogDate.Before(weekStart) {
+				continue
+			}
+			completedThisWeek++
+			if log.LogDate.Equal(today) {
+				completedToday = true
+			}
+		}
+
+		if !completedToday && (!isHabitActiveOn(clock, h, today) || excluded(today)) {
+			continue
+		}
+
+		// Completions still needed this week, and days left to make them
+		remaining, daysLeft := 0, 7-clock.WeekdayIndex(today)
+		if h.Frequency == habit.Weekly {
+			target := weekTarget(weekStart, h.WeeklyTarget(), excluded)
+			if !completedToday && completedThisWeek >= max(target, 1) {
+				continue
+			}
+			remaining = target - completedThisWeek
+		}
+
+		features := habitPredictionFeatures(h, habitLogs, excluded, clock, today, strengths[h.ID][0])
+		point := analytics.PredictionDataPoint{
+			HabitID:        h.ID.String(),
+			Name:           h.Name,
+			Probability:    predictCompletion(features),
+			CompletedToday: completedToday,
+			CurrentStreak:  h.CurrentStreak,
+			WeekdayRate:    features.weekdayRate,
+			RecentRate:     features.recentRate,
+			Strength:       features.strength,
+		}
+
+		if completedToday {
+			point.Probability = 1
+		} else {
+			point.StreakAtRisk = isStreakAtRisk(h, point.Probability, remaining, daysLeft)
+		}
+
+		predictions[h.ID] = point
+	}
+
+	return predictions, nil
+}
+
+// GetPredictions estimates how likely each habit scheduled today is to be
+// completed, and flags the streaks at risk
+func (s *AnalyticsService) GetPredictions(ctx context.Context, userID uuid.UUID) (*analytics.PredictionsResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	today := lib.ClockFromContext(ctx).Today()
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	// Filter out archived habits
+	activeHabits := make([]habit.Habit, 0)
+	for _, h := range habits {
+		if h.ArchivedAt == nil {
+			activeHabits = append(activeHabits, h)
+		}
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if err := refreshCurrentStreaks(ctx, s.habitLogRepo, userID, activeHabits, pauses); err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	predictions, err := predictToday(ctx, s.habitLogRepo, s.strengthRepo, userID, activeHabits, pauses)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	response := &analytics.PredictionsResponse{
+		Date: dateKey(today),
+		Data: make([]analytics.PredictionDataPoint, 0, len(predictions)),
+	}
+	for _, h := range activeHabits {
+		point, ok := predictions[h.ID]
+		if !ok {
+			continue
+		}
+		response.Data = append(response.Data, point)
+		if point.StreakAtRisk {
+			response.AtRiskCount++
+		}
+	}
+
+	sort.SliceStable(response.Data, func(i, j int) bool {
+		return response.Data[i].Probability < response.Data[j].Probability
+	})
+
+	return response, nil
+}
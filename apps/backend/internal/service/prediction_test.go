@@ -0,0 +1,171 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+// completedExcept returns a completed log for every day from start to end
+// that does not fall on weekday
+func completedExcept(start, end string, weekday time.Weekday) []habitlog.HabitLog {
+	values := make([]string, 0)
+	for _, value := range dayRange(start, end) {
+		if day(value).Weekday() != weekday {
+			values = append(values, value)
+		}
+	}
+	return completedLogs(values...)
+}
+
+func TestPredictCompletion(t *testing.T) {
+	tests := []struct {
+		name            string
+		today           string
+		created         string
+		logs            []habitlog.HabitLog
+		excluded        DayFilter
+		strength        float64
+		streak          int
+		wantWeekdayRate float64
+		wantRecentRate  float64
+		wantProbability float64
+		wantAtRisk      bool
+	}{
+		{
+			name:            "no history",
+			today:           "2026-05-20",
+			created:         "2026-05-20",
+			excluded:        noExcludedDays,
+			wantWeekdayRate: 0.5,
+			wantRecentRate:  0.5,
+			wantProbability: 0.375,
+		},
+		{
+			name:            "perfect history",
+			today:           "2026-05-20",
+			created:         "2026-03-01",
+			logs:            completedLogs(dayRange("2026-03-01", "2026-05-19")...),
+			excluded:        noExcludedDays,
+			strength:        90,
+			streak:          80,
+			wantWeekdayRate: 9.0 / 10,
+			wantRecentRate:  15.0 / 16,
+			wantProbability: 0.913125,
+		},
+		{
+			name:            "weekday skew, on the weekday that is always missed",
+			today:           "2026-05-20",
+			created:         "2026-03-01",
+			logs:            completedExcept("2026-03-01", "2026-05-19", time.Wednesday),
+			excluded:        noExcludedDays,
+			strength:        60,
+			streak:          6,
+			wantWeekdayRate: 1.0 / 10,
+			wantRecentRate:  13.0 / 16,
+			wantProbability: 0.474375,
+			wantAtRisk:      true,
+		},
+		{
+			name:            "weekday skew, on another weekday",
+			today:           "2026-05-21",
+			created:         "2026-03-01",
+			logs:            completedExcept("2026-03-01", "2026-05-19", time.Wednesday),
+			excluded:        noExcludedDays,
+			strength:        60,
+			streak:          6,
+			wantWeekdayRate: 9.0 / 10,
+			wantRecentRate:  13.0 / 16,
+			wantProbability: 0.794375,
+		},
+		{
+			name:            "streak that lapses today",
+			today:           "2026-05-20",
+			created:         "2026-03-01",
+			logs:            completedLogs(dayRange("2026-05-15", "2026-05-19")...),
+			excluded:        noExcludedDays,
+			strength:        30,
+			streak:          5,
+			wantWeekdayRate: 1.0 / 10,
+			wantRecentRate:  6.0 / 16,
+			wantProbability: 0.24625,
+			wantAtRisk:      true,
+		},
+		{
+			name:            "paused days are not held against the habit",
+			today:           "2026-05-20",
+			created:         "2026-03-01",
+			logs:            completedLogs(dayRange("2026-05-15", "2026-05-19")...),
+			excluded:        excludeDays(dayRange("2026-03-01", "2026-05-14")...),
+			strength:        30,
+			streak:          5,
+			wantWeekdayRate: 0.5,
+			wantRecentRate:  6.0 / 7,
+			wantProbability: 0.575,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := weekClock(tt.today, time.Monday)
+
+			var h habit.Habit
+			h.Frequency = habit.Daily
+			h.CurrentStreak = tt.streak
+			h.CreatedAt = day(tt.created)
+
+			features := habitPredictionFeatures(h, tt.logs, tt.excluded, clock, clock.Today(), tt.strength)
+			if math.Abs(features.weekdayRate-tt.wantWeekdayRate) > 1e-9 {
+				t.Errorf("weekdayRate = %v, want %v", features.weekdayRate, tt.wantWeekdayRate)
+			}
+			if math.Abs(features.recentRate-tt.wantRecentRate) > 1e-9 {
+				t.Errorf("recentRate = %v, want %v", features.recentRate, tt.wantRecentRate)
+			}
+
+			probability := predictCompletion(features)
+			if math.Abs(probability-tt.wantProbability) > 1e-9 {
+				t.Errorf("predictCompletion() = %v, want %v", probability, tt.wantProbability)
+			}
+
+			if got := isStreakAtRisk(h, probability, 0, 7-clock.WeekdayIndex(clock.Today())); got != tt.wantAtRisk {
+				t.Errorf("isStreakAtRisk() = %v, want %v", got, tt.wantAtRisk)
+			}
+		})
+	}
+}
+
+func TestIsStreakAtRisk(t *testing.T) {
+	tests := []struct {
+		name        string
+		frequency   habit.Frequency
+		streak      int
+		probability float64
+		remaining   int
+		daysLeft    int
+		want        bool
+	}{
+		{"no streak to lose", habit.Daily, 0, 0.1, 0, 5, false},
+		{"likely to be completed", habit.Daily, 4, 0.8, 0, 5, false},
+		{"just above the threshold", habit.Daily, 4, 0.5, 0, 5, false},
+		{"unlikely to be completed", habit.Daily, 4, 0.3, 0, 5, true},
+		{"weekly with days to spare", habit.Weekly, 3, 0.8, 2, 3, false},
+		{"weekly needing every day left", habit.Weekly, 3, 0.8, 3, 3, true},
+		{"weekly needing more than the days left", habit.Weekly, 3, 0.8, 2, 1, true},
+		{"weekly without a streak", habit.Weekly, 0, 0.8, 3, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h habit.Habit
+			h.Frequency = tt.frequency
+			h.CurrentStreak = tt.streak
+
+			if got := isStreakAtRisk(h, tt.probability, tt.remaining, tt.daysLeft); got != tt.want {
+				t.Errorf("isStreakAtRisk() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
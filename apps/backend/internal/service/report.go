@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/report"
+	"github.com/reche13/habitum/internal/model/rollup"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+const (
+	// reportHabitLimit caps the best and the worst habits of a report
+	reportHabitLimit = 3
+	// reportNoteLimit caps the notes of a report
+	reportNoteLimit = 3
+	// minBrokenStreak is the shortest streak whose end a report mentions
+	minBrokenStreak = 2
+)
+
+type ReportService struct {
+	*BaseService
+	repos *repository.Repositories
+}
+
+func NewReportService(repos *repository.Repositories) *ReportService {
+	return &ReportService{
+		BaseService: &BaseService{
+			resourceName: "report",
+		},
+		repos: repos,
+	}
+}
+
+// GetWeeklyReport returns the review of the week containing week, or of last
+// week when week is nil
+func (s *ReportService) GetWeeklyReport(ctx context.Context, userID uuid.UUID, week *time.Time) (*report.ReportResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	start := clock.WeekStart(clock.Today()).AddDate(0, 0, -7)
+	if week != nil {
+		start = clock.WeekStart(lib.NormalizeDate(*week))
+	}
+	if start.After(clock.Today()) {
+		return nil, errs.NewBadRequestError("That week has not started yet")
+	}
+
+	return s.getReport(ctx, userID, report.Weekly, start, start.AddDate(0, 0, 6), start.AddDate(0, 0, -7))
+}
+
+// GetMonthlyReport returns the review of the month containing month, or of
+// last month when month is nil
+func (s *ReportService) GetMonthlyReport(ctx context.Context, userID uuid.UUID, month *time.Time) (*report.ReportResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	start := bucketStart(clock.Today(), GranularityMonth, clock).AddDate(0, -1, 0)
+	if month != nil {
+		start = bucketStart(lib.NormalizeDate(*month), GranularityMonth, clock)
+	}
+	if start.After(clock.Today()) {
+		return nil, errs.NewBadRequestError("That month has not started yet")
+	}
+
+	return s.getReport(ctx, userID, report.Monthly, start, start.AddDate(0, 1, -1), start.AddDate(0, -1, 0))
+}
+
+// getReport returns the stored report of a finished period, generating and
+// storing it the first time. Reports of the period in progress are never
+// stored, nor are those of a month whose last week is still running, since
+// the rest of that week moves the weekly targets counted in the month.
+func (s *ReportService) getReport(
+	ctx context.Context,
+	userID uuid.UUID,
+	period report.Period,
+	start, end, prevStart time.Time,
+) (*report.ReportResponse, error) {
+	clock := lib.ClockFromContext(ctx)
+	final := clock.WeekStart(end).AddDate(0, 0, 6).Before(clock.Today())
+	if final {
+		stored, err := s.repos.Report.Get(ctx, userID, period, start)
+		if err == nil {
+			return &stored.Data, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, s.wrapError(err)
+		}
+	}
+
+	response, err := s.generateReport(ctx, userID, period, start, end, prevStart)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	if !final {
+		return response, nil
+	}
+
+	response.Final = true
+	stored, err := s.repos.Report.Create(ctx, &report.Report{
+		UserID:      userID,
+		Period:      period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Data:        *response,
+		GeneratedAt: response.GeneratedAt,
+	})
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return &stored.Data, nil
+}
+
+// generateReport reviews the period from start to end against the period
+// starting at prevStart, which ends the day before start
+func (s *ReportService) generateReport(
+	ctx context.Context,
+	userID uuid.UUID,
+	period report.Period,
+	start, end, prevStart time.Time,
+) (*report.ReportResponse, error) {
+	clock := lib.ClockFromContext(ctx)
+	today := clock.Today()
+	prevEnd := start.AddDate(0, 0, -1)
+
+	// Days after today are not over, so they neither count nor break anything
+	through := end
+	if through.After(today) {
+		through = today
+	}
+
+	response := &report.ReportResponse{
+		Period:         period,
+		Range:          newDateRange(start, end),
+		ComparedTo:     newDateRange(prevStart, prevEnd),
+		GeneratedAt:    time.Now(),
+		BestHabits:     []report.ReportHabit{},
+		WorstHabits:    []report.ReportHabit{},
+		StreaksStarted: []report.ReportStreak{},
+		StreaksBroken:  []report.ReportStreak{},
+		Achievements:   []report.ReportAchievement{},
+		Notes:          []report.ReportNote{},
+	}
+
+	// Weekly habits count their weekly target, split by day across the
+	// period's edges, and completions up to it
+	rollups, err := loadDailyRollups(ctx, s.repos.DailyRollup, s.repos.Habit, s.repos.HabitLog, s.repos.Pause, userID, prevStart, through)
+	if err != nil {
+		return nil, err
+	}
+	var previousDays, currentDays []rollup.DailyRollup
+	for _, ru := range rollups {
+		if ru.Day.Before(start) {
+			previousDays = append(previousDays, ru)
+		} else {
+			currentDays = append(currentDays, ru)
+		}
+	}
+	previous := completionTrendSummary(previousDays)
+	current := completionTrendSummary(currentDays)
+	response.Completions = current.Completions
+	response.Scheduled = current.TotalHabits
+	response.CompletionRate = current.CompletionRate
+	response.Comparison = newDelta(current.CompletionRate, previous.CompletionRate)
+
+	allHabits, _, err := s.repos.Habit.List(ctx, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+	archiveEndedChallenges(allHabits, lib.ClockFromContext(ctx))
+
+	// Habits archived before the period do not belong in it
+	habits := make([]habit.Habit, 0, len(allHabits))
+	names := make(map[uuid.UUID]string, len(allHabits))
+	for _, h := range allHabits {
+		names[h.ID] = h.Name
+		if h.ArchivedAt == nil || clock.Date(*h.ArchivedAt).After(start) {
+			habits = append(habits, h)
+		}
+	}
+
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+	logs, err := s.repos.HabitLog.GetByHabits(ctx, userID, habitIDs, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), end)
+	if err != nil {
+		return nil, err
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog, len(habits))
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.repos.Pause, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]report.ReportHabit, 0, len(habits))
+	for _, h := range habits {
+		stats, ok := habitStatsBetween(h, logsByHabit[h.ID], pauses, clock, start, through)
+		if !ok {
+			continue
+		}
+		ranked = append(ranked, report.ReportHabit{
+			HabitID:        h.ID.String(),
+			Name:           h.Name,
+			CompletionRate: stats.completionRate,
+			Completions:    stats.completions,
+		})
+
+		started, broken := reportStreaks(h, logsByHabit[h.ID], pauses, clock, start, end)
+		response.StreaksStarted = append(response.StreaksStarted, started...)
+		response.StreaksBroken = append(response.StreaksBroken, broken...)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].CompletionRate != ranked[j].CompletionRate {
+			return ranked[i].CompletionRate > ranked[j].CompletionRate
+		}
+		return ranked[i].Completions > ranked[j].Completions
+	})
+	response.BestHabits = append(response.BestHabits, ranked[:min(reportHabitLimit, len(ranked))]...)
+	for i := len(ranked) - 1; i >= max(len(response.BestHabits), len(ranked)-reportHabitLimit); i-- {
+		response.WorstHabits = append(response.WorstHabits, ranked[i])
+	}
+
+	sort.SliceStable(response.StreaksStarted, func(i, j int) bool {
+		return response.StreaksStarted[i].Length > response.StreaksStarted[j].Length
+	})
+	sort.SliceStable(response.StreaksBroken, func(i, j int) bool {
+		return response.StreaksBroken[i].Length > response.StreaksBroken[j].Length
+	})
+
+	achievements, err := s.repos.Achievement.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	definitions := achievementDefinitions()
+	for _, a := range achievements {
+		if a.UnlockedAt == nil {
+			continue
+		}
+		unlocked := clock.Date(*a.UnlockedAt)
+		if unlocked.Before(start) || unlocked.After(end) {
+			continue
+		}
+		response.Achievements = append(response.Achievements, report.ReportAchievement{
+			Key:        a.Key,
+			Name:       definitions[a.Key].Name,
+			Icon:       definitions[a.Key].Icon,
+			UnlockedAt: *a.UnlockedAt,
+		})
+	}
+	sort.Slice(response.Achievements, func(i, j int) bool {
+		return response.Achievements[i].UnlockedAt.Before(response.Achievements[j].UnlockedAt)
+	})
+
+	response.Notes = reportNotes(logs, names, start, end)
+	response.Highlight = reportHighlight(response)
+
+	return response, nil
+}
+
+// reportStreaks returns the streaks of a habit that started between start and
+// end, with their length by end, and those that ended then after lasting at
+// least minBrokenStreak. The logs must reach back to the habit's first one.
+// Weekly habits count weeks, which belong to the period their last day is in.
+func reportStreaks(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+	start, end time.Time,
+) ([]report.ReportStreak, []report.ReportStreak) {
+	completedDates := make([]time.Time, 0)
+	for _, log := range logs {
+		if log.Completed && !log.LogDate.After(end) {
+			completedDates = append(completedDates, log.LogDate)
+		}
+	}
+
+	unitDays := 1
+	if h.Frequency == habit.Weekly {
+		unitDays = 7
+	}
+	inPeriod := func(unit time.Time) bool {
+		return !unit.AddDate(0, 0, unitDays-1).Before(start)
+	}
+
+	started, broken := make([]report.ReportStreak, 0), make([]report.ReportStreak, 0)
+	for _, run := range streakRuns(h, completedDates, habitActiveDayFilter(h, pauses, logs, clock), clock, end) {
+		streak := report.ReportStreak{
+			HabitID:   h.ID.String(),
+			Name:      h.Name,
+			StartDate: dateKey(run.start),
+			Length:    run.length,
+		}
+		if inPeriod(run.start) {
+			started = append(started, streak)
+		}
+		if run.brokenOn != nil && inPeriod(*run.brokenOn) && run.length >= minBrokenStreak {
+			brokenOn := dateKey(*run.brokenOn)
+			streak.BrokenOn = &brokenOn
+			broken = append(broken, streak)
+		}
+	}
+
+	return started, broken
+}
+
+// reportNotes returns the longest notes logged between start and end
+func reportNotes(logs []habitlog.HabitLog, names map[uuid.UUID]string, start, end time.Time) []report.ReportNote {
+	notes := make([]report.ReportNote, 0)
+	for _, log := range logs {
+		if log.Note == nil || log.LogDate.Before(start) || log.LogDate.After(end) {
+			continue
+		}
+		note := strings.TrimSpace(*log.Note)
+		if note == "" {
+			continue
+		}
+		notes = append(notes, report.ReportNote{
+			HabitID: log.HabitID.String(),
+			Name:    names[log.HabitID],
+			Date:    dateKey(log.LogDate),
+			Note:    note,
+		})
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		if len(notes[i].Note) != len(notes[j].Note) {
+			return len(notes[i].Note) > len(notes[j].Note)
+		}
+		return notes[i].Date > notes[j].Date
+	})
+	if len(notes) > reportNoteLimit {
+		notes = notes[:reportNoteLimit]
+	}
+
+	return notes
+}
+
+// reportHighlight sums the report up in one sentence, picking the most
+// notable thing that happened
+func reportHighlight(r *report.ReportResponse) string {
+	noun := "week"
+	if r.Period == report.Monthly {
+		noun = "month"
+	}
+
+	switch {
+	case r.Scheduled == 0 && r.Completions == 0:
+		return fmt.Sprintf("Nothing was scheduled this %s.", noun)
+	case len(r.Achievements) > 1:
+		return fmt.Sprintf("You unlocked %s and %d more achievements this %s.", r.Achievements[0].Name, len(r.Achievements)-1, noun)
+	case len(r.Achievements) == 1:
+		return fmt.Sprintf("You unlocked %s this %s.", r.Achievements[0].Name, noun)
+	case r.Scheduled > 0 && r.Completions >= r.Scheduled:
+		return fmt.Sprintf("A perfect %s: every scheduled habit was completed.", noun)
+	case r.Comparison.Previous > 0 && r.Comparison.Change >= 10:
+		return fmt.Sprintf("Your completion rate rose from %.0f%% to %.0f%%.", r.Comparison.Previous, r.CompletionRate)
+	case len(r.BestHabits) > 0 && r.BestHabits[0].CompletionRate >= 80:
+		return fmt.Sprintf("%s led the way at %.0f%% completion.", r.BestHabits[0].Name, r.BestHabits[0].CompletionRate)
+	case r.Comparison.Change <= -10:
+		return fmt.Sprintf("Your completion rate dipped from %.0f%% to %.0f%%. A fresh %s is a chance to bounce back.", r.Comparison.Previous, r.CompletionRate, noun)
+	default:
+		return fmt.Sprintf("You logged %d completions this %s.", r.Completions, noun)
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/rollup"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// Daily rollups hold each user's per-day totals. A day's rollup is rewritten
+// in the same transaction as any log written for that day, and rollups are
+// dropped from the first affected day whenever habits, pauses or the user's
+// day boundaries change. Reads compute missing days without storing them, so
+// a dropped rollup never serves stale totals; they are stored again by the
+// next log write in their week or by BackfillRollups.
+//
+// Weekly habits are due a number of times per week rather than every day, so
+// each week gets its weekTarget in scheduled units, not one per day: a unit
+// on each credited completion and the shortfall spread over the week's other
+// days. A whole week then adds up to the target, and a week that crosses a
+// bucket edge is split between the buckets by the days it spans.
+
+// computeDailyRollups derives the user's totals for every day from from to to.
+// A daily habit is scheduled on a day it is active and neither paused nor
+// skipped or frozen; a habit completed anyway always counts as scheduled.
+// Weekly habits need logs for every week the range touches; see
+// rollupLogRange.
+func computeDailyRollups(
+	userID uuid.UUID,
+	habits []habit.Habit,
+	logs []habitlog.HabitLog,
+	pauses *PauseSchedule,
+	clock lib.Clock,
+	from, to time.Time,
+) []rollup.DailyRollup {
+	logsByDay := make(map[string]map[uuid.UUID]habitlog.HabitLog)
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		key := dateKey(log.LogDate)
+		if logsByDay[key] == nil {
+			logsByDay[key] = make(map[uuid.UUID]habitlog.HabitLog)
+		}
+		logsByDay[key][log.HabitID] = log
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	rollups := make([]rollup.DailyRollup, 0)
+	indexByDay := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		ru := rollup.DailyRollup{UserID: userID, Day: day}
+		dayLogs := logsByDay[dateKey(day)]
+
+		for _, h := range habits {
+			log, logged := dayLogs[h.ID]
+			switch {
+			case logged && log.Status.IsExcused():
+				ru.SkippedCount++
+			case h.Frequency == habit.Weekly:
+				// Added week by week below
+			case logged && log.Completed:
+				ru.ScheduledCount++
+				ru.CompletedCount++
+			case isHabitActiveOn(clock, h, day) && !pauses.IsPaused(h.ID, day):
+				ru.ScheduledCount++
+			}
+		}
+
+		indexByDay[dateKey(day)] = len(rollups)
+		rollups = append(rollups, ru)
+	}
+
+	for _, h := range habits {
+		if h.Frequency != habit.Weekly {
+			continue
+		}
+
+		excluded := habitActiveDayFilter(h, pauses, logsByHabit[h.ID], clock)
+		for week := clock.WeekStart(from); !week.After(to); week = week.AddDate(0, 0, 7) {
+			scheduled, completed := weeklyUnits(week, h.WeeklyTarget(), excluded, logsByDay, h.ID)
+			for i := 0; i < 7; i++ {
+				index, ok := indexByDay[dateKey(week.AddDate(0, 0, i))]
+				if !ok {
+					continue
+				}
+				rollups[index].ScheduledCount += scheduled[i]
+				rollups[index].CompletedCount += completed[i]
+			}
+		}
+	}
+
+	return rollups
+}
+
+// weeklyUnits spreads the weekTarget of a weekly habit over the week starting
+// at weekStart. Completions count towards the target in order, each as one
+// scheduled and one completed unit on its day, and the rest of the target is
+// spread evenly over the week's other days that are not excluded. There are
+// always enough of them, since the target is at most the days not excluded.
+func weeklyUnits(
+	weekStart time.Time,
+	timesPerWeek int,
+	excluded DayFilter,
+	logsByDay map[string]map[uuid.UUID]habitlog.HabitLog,
+	habitID uuid.UUID,
+) (scheduled, completed [7]int) {
+	target := weekTarget(weekStart, timesPerWeek, excluded)
+
+	credited := 0
+	for i := 0; i < 7 && credited < target; i++ {
+		if log, ok := logsByDay[dateKey(weekStart.AddDate(0, 0, i))][habitID]; ok && log.Completed {
+			scheduled[i]++
+			completed[i]++
+			credited++
+		}
+	}
+
+	open := make([]int, 0, 7)
+	for i := 0; i < 7; i++ {
+		if completed[i] == 0 && !excluded(weekStart.AddDate(0, 0, i)) {
+			open = append(open, i)
+		}
+	}
+	shortfall := target - credited
+	for j := 0; j < shortfall; j++ {
+		scheduled[open[j*len(open)/shortfall]]++
+	}
+
+	return scheduled, completed
+}
+
+// rollupLogRange widens from and to to whole weeks, the logs
+// computeDailyRollups needs to spread weekly habits over the range
+func rollupLogRange(clock lib.Clock, from, to time.Time) (time.Time, time.Time) {
+	return clock.WeekStart(from), clock.WeekStart(to).AddDate(0, 0, 6)
+}
+
+// dropDailyRollups drops the user's rollups from the start of the week
+// containing from, since a weekly habit's units depend on its whole week. A
+// zero from drops all of them.
+func dropDailyRollups(ctx context.Context, rollupRepo *repository.DailyRollupRepository, userID uuid.UUID, from time.Time) error {
+	if !from.IsZero() {
+		from = lib.ClockFromContext(ctx).WeekStart(from)
+	}
+	return rollupRepo.DeleteFrom(ctx, userID, from)
+}
+
+// refreshDailyRollup recomputes and stores the user's rollups for the week
+// containing day, up to today, since a log moves the units of weekly habits
+// across their week. Log writes call it on their transaction so the rollups
+// commit with the log.
+func refreshDailyRollup(
+	ctx context.Context,
+	repos *repository.Repositories,
+	userID uuid.UUID,
+	day time.Time,
+) error {
+	habits, _, err := repos.Habit.List(ctx, userID, nil)
+	if err != nil {
+		return err
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	from, to := rollupLogRange(clock, day, day)
+	logs, err := repos.HabitLog.GetByDateRange(ctx, userID, from, to)
+	if err != nil {
+		return err
+	}
+
+	pauses, err := loadPauseSchedule(ctx, repos.Pause, userID)
+	if err != nil {
+		return err
+	}
+
+	last := clock.Today()
+	if day.After(last) {
+		last = day
+	}
+	if last.Before(to) {
+		to = last
+	}
+	return repos.DailyRollup.Upsert(ctx, userID, computeDailyRollups(userID, habits, logs, pauses, clock, from, to))
+}
+
+// loadDailyRollups returns the user's rollup for every day from from to to,
+// oldest first. Days without a stored rollup are computed in one pass but not
+// stored, so reads never write.
+func loadDailyRollups(
+	ctx context.Context,
+	rollupRepo *repository.DailyRollupRepository,
+	habitRepo *repository.HabitRepository,
+	habitLogRepo *repository.HabitLogRepository,
+	pauseRepo *repository.PauseRepository,
+	userID uuid.UUID,
+	from, to time.Time,
+) ([]rollup.DailyRollup, error) {
+	stored, err := rollupRepo.ListRange(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]rollup.DailyRollup, len(stored))
+	for _, ru := range stored {
+		byDay[dateKey(ru.Day)] = ru
+	}
+
+	// Compute everything between the first and last missing day at once
+	var firstMissing, lastMissing time.Time
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if _, ok := byDay[dateKey(day)]; ok {
+			continue
+		}
+		if firstMissing.IsZero() {
+			firstMissing = day
+		}
+		lastMissing = day
+	}
+
+	if !firstMissing.IsZero() {
+		habits, _, err := habitRepo.List(ctx, userID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		clock := lib.ClockFromContext(ctx)
+		logFrom, logTo := rollupLogRange(clock, firstMissing, lastMissing)
+		logs, err := habitLogRepo.GetByDateRange(ctx, userID, logFrom, logTo)
+		if err != nil {
+			return nil, err
+		}
+
+		pauses, err := loadPauseSchedule(ctx, pauseRepo, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ru := range computeDailyRollups(userID, habits, logs, pauses, clock, firstMissing, lastMissing) {
+			key := dateKey(ru.Day)
+			if _, ok := byDay[key]; !ok {
+				byDay[key] = ru
+			}
+		}
+	}
+
+	rollups := make([]rollup.DailyRollup, 0, len(byDay))
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		rollups = append(rollups, byDay[dateKey(day)])
+	}
+
+	return rollups, nil
+}
+
+// BackfillRollups rebuilds every stored rollup of the user from their habits
+// and logs, from the day their first habit was created up to today. It
+// returns the number of days written.
+func (s *AnalyticsService) BackfillRollups(ctx context.Context, userID uuid.UUID) (int, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	if err := s.rollupRepo.DeleteFrom(ctx, userID, time.Time{}); err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+	if len(habits) == 0 {
+		return 0, nil
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	from, today := earliestHabitDate(habits, clock), clock.Today()
+	if from.After(today) {
+		return 0, nil
+	}
+
+	rollups, err := loadDailyRollups(ctx, s.rollupRepo, s.habitRepo, s.habitLogRepo, s.pauseRepo, userID, from, today)
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	if err := s.rollupRepo.Upsert(ctx, userID, rollups); err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	return len(rollups), nil
+}
+
+// earliestHabitDate returns the day the oldest of habits was created
+func earliestHabitDate(habits []habit.Habit, clock lib.Clock) time.Time {
+	earliest := habits[0].CreatedAt
+	for _, h := range habits {
+		if h.CreatedAt.Before(earliest) {
+			earliest = h.CreatedAt
+		}
+	}
+	return clock.Date(earliest)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+// skippedLogs returns a skipped log for each of the given days
+func skippedLogs(values ...string) []habitlog.HabitLog {
+	logs := make([]habitlog.HabitLog, len(values))
+	for i, d := range days(values...) {
+		logs[i] = habitlog.HabitLog{LogDate: d, Status: habitlog.Skipped}
+	}
+	return logs
+}
+
+// weeklyHabit returns a weekly habit with the zero ID of the test logs,
+// created well before May 2026
+func weeklyHabit(timesPerWeek int) habit.Habit {
+	h := habit.Habit{Frequency: habit.Weekly, TimesPerWeek: &timesPerWeek}
+	h.CreatedAt = day("2026-04-01")
+	return h
+}
+
+func TestComputeDailyRollupsWeeklyHabit(t *testing.T) {
+	tests := []struct {
+		name          string
+		logs          []habitlog.HabitLog
+		wantScheduled int
+		wantCompleted int
+		wantSkipped   int
+	}{
+		{"target met", completedLogs("2026-05-04", "2026-05-06", "2026-05-08"), 3, 3, 0},
+		{"completions past the target", completedLogs(dayRange("2026-05-04", "2026-05-08")...), 3, 3, 0},
+		{"target missed", completedLogs("2026-05-05"), 3, 1, 0},
+		{"nothing completed", nil, 3, 0, 0},
+		{"skipped days lower the target", skippedLogs(dayRange("2026-05-04", "2026-05-08")...), 2, 0, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := weekClock("2026-05-20", time.Monday)
+			rollups := computeDailyRollups(uuid.Nil, []habit.Habit{weeklyHabit(3)}, tt.logs, NewPauseSchedule(nil), clock, day("2026-05-04"), day("2026-05-10"))
+
+			scheduled, completed, skipped := 0, 0, 0
+			for _, ru := range rollups {
+				if ru.CompletedCount > ru.ScheduledCount || ru.ScheduledCount > 1 {
+					t.Errorf("%s: %d completed of %d scheduled", dateKey(ru.Day), ru.CompletedCount, ru.ScheduledCount)
+				}
+				scheduled += ru.ScheduledCount
+				completed += ru.CompletedCount
+				skipped += ru.SkippedCount
+			}
+
+			if scheduled != tt.wantScheduled || completed != tt.wantCompleted || skipped != tt.wantSkipped {
+				t.Errorf("week totals = %d scheduled, %d completed, %d skipped, want %d, %d, %d",
+					scheduled, completed, skipped, tt.wantScheduled, tt.wantCompleted, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/routine"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/sqlerr"
+)
+
+type RoutineService struct {
+	*BaseService
+	repos        *repository.Repositories
+	routineRepo  *repository.RoutineRepository
+	habitRepo    *repository.HabitRepository
+	habitLogRepo *repository.HabitLogRepository
+	xp           XPRules
+}
+
+func NewRoutineService(repos *repository.Repositories, xp XPRules) *RoutineService {
+	return &RoutineService{
+		BaseService: &BaseService{
+			resourceName: "routine",
+		},
+		repos:        repos,
+		routineRepo:  repos.Routine,
+		habitRepo:    repos.Habit,
+		habitLogRepo: repos.HabitLog,
+		xp:           xp,
+	}
+}
+
+func (s *RoutineService) CreateRoutine(
+	ctx context.Context,
+	userID uuid.UUID,
+	payload *routine.CreateRoutinePayload,
+) (*routine.RoutineResponse, error) {
+	timeOfDay := payload.TimeOfDay
+	if timeOfDay == "" {
+		timeOfDay = routine.Anytime
+	}
+
+	var routineID uuid.UUID
+	err := s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		created, err := tx.Routine.Create(ctx, userID, payload.Name, timeOfDay)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		routineID = created.ID
+
+		return s.setHabits(ctx, tx, routineID, userID, payload.HabitIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRoutine(ctx, userID, routineID)
+}
+
+func (s *RoutineService) GetRoutines(ctx context.Context, userID uuid.UUID) ([]routine.RoutineResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	routines, err := s.routineRepo.List(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	members, err := s.routineRepo.ListMembers(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	today := lib.ClockFromContext(ctx).Today()
+	habitsByID, completedAt, err := s.loadMemberState(ctx, userID, members, today)
+	if err != nil {
+		return nil, err
+	}
+
+	membersByRoutine := make(map[uuid.UUID][]routine.Member)
+	for _, m := range members {
+		membersByRoutine[m.RoutineID] = append(membersByRoutine[m.RoutineID], m)
+	}
+
+	responses := make([]routine.RoutineResponse, len(routines))
+	for i, rt := range routines {
+		responses[i] = buildRoutineResponse(rt, membersByRoutine[rt.ID], habitsByID, completedAt, today)
+	}
+
+	return responses, nil
+}
+
+func (s *RoutineService) GetRoutine(ctx context.Context, userID uuid.UUID, routineID uuid.UUID) (*routine.RoutineResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	return s.getRoutineOnDate(ctx, userID, routineID, lib.ClockFromContext(ctx).Today())
+}
+
+func (s *RoutineService) UpdateRoutine(
+	ctx context.Context,
+	userID uuid.UUID,
+	routineID uuid.UUID,
+	payload *routine.UpdateRoutinePayload,
+) (*routine.RoutineResponse, error) {
+	// Verify routine exists and belongs to user
+	_, err := s.routineRepo.GetByID(ctx, routineID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if _, err := tx.Routine.Update(ctx, routineID, userID, payload); err != nil {
+			return s.wrapError(err)
+		}
+
+		if payload.HabitIDs != nil {
+			return s.setHabits(ctx, tx, routineID, userID, *payload.HabitIDs)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetRoutine(ctx, userID, routineID)
+}
+
+func (s *RoutineService) DeleteRoutine(ctx context.Context, userID uuid.UUID, routineID uuid.UUID) error {
+	// Verify routine exists and belongs to user
+	_, err := s.routineRepo.GetByID(ctx, routineID, userID)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if err := s.routineRepo.Delete(ctx, routineID, userID); err != nil {
+		return s.wrapError(err)
+	}
+
+	return nil
+}
+
+// CompleteRoutine marks every member habit that is due on the given date as
+// completed, skipping archived and paused ones. All logs are written in a
+// single transaction.
+func (s *RoutineService) CompleteRoutine(
+	ctx context.Context,
+	userID uuid.UUID,
+	routineID uuid.UUID,
+	date time.Time,
+) (*routine.RoutineResponse, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	logDate := dateOrToday(ctx, date)
+
+	_, err = s.routineRepo.GetByID(ctx, routineID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	members, err := s.routineRepo.ListMembersByRoutine(ctx, routineID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	habitIDs := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		habitIDs[i] = m.HabitID
+	}
+
+	habits, err := s.habitRepo.GetByIDs(ctx, userID, habitIDs)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.repos.Pause, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		for _, h := range habits {
+			// Paused habits and challenges outside their window are not due
+			if h.ArchivedAt != nil || habitDayFilter(h, pauses)(logDate) {
+				continue
+			}
+
+			payload := &habitlog.HabitLogPayload{
+				HabitID:   h.ID,
+				LogDate:   logDate,
+				Completed: true,
+			}
+			stampCompletion(ctx, payload)
+
+			log, err := tx.HabitLog.Create(ctx, userID, payload)
+			if err != nil {
+				return sqlerr.WrapError(err, "habitlog")
+			}
+
+			if err := afterLogWrite(ctx, tx, s.xp, userID, &h, log); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getRoutineOnDate(ctx, userID, routineID, logDate)
+}
+
+func (s *RoutineService) getRoutineOnDate(
+	ctx context.Context,
+	userID uuid.UUID,
+	routineID uuid.UUID,
+	date time.Time,
+) (*routine.RoutineResponse, error) {
+	rt, err := s.routineRepo.GetByID(ctx, routineID, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	members, err := s.routineRepo.ListMembersByRoutine(ctx, routineID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	habitsByID, completedAt, err := s.loadMemberState(ctx, userID, members, date)
+	if err != nil {
+		return nil, err
+	}
+
+	response := buildRoutineResponse(*rt, members, habitsByID, completedAt, date)
+	return &response, nil
+}
+
+func (s *RoutineService) setHabits(
+	ctx context.Context,
+	tx *repository.Repositories,
+	routineID uuid.UUID,
+	userID uuid.UUID,
+	habitIDs []uuid.UUID,
+) error {
+	unique := make(map[uuid.UUID]bool)
+	for _, id := range habitIDs {
+		unique[id] = true
+	}
+
+	inserted, err := tx.Routine.SetHabits(ctx, routineID, userID, habitIDs)
+	if err != nil {
+		return s.wrapError(err)
+	}
+
+	if inserted != len(unique) {
+		return errs.NewBadRequestError("One or more habits do not exist")
+	}
+
+	return nil
+}
+
+// loadMemberState loads the member habits and their completion times on date
+func (s *RoutineService) loadMemberState(
+	ctx context.Context,
+	userID uuid.UUID,
+	members []routine.Member,
+	date time.Time,
+) (map[uuid.UUID]habit.Habit, map[uuid.UUID]time.Time, error) {
+	habitIDs := make([]uuid.UUID, len(members))
+	for i, m := range members {
+		habitIDs[i] = m.HabitID
+	}
+
+	habits, err := s.habitRepo.GetByIDs(ctx, userID, habitIDs)
+	if err != nil {
+		return nil, nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	habitsByID := make(map[uuid.UUID]habit.Habit, len(habits))
+	for _, h := range habits {
+		habitsByID[h.ID] = h
+	}
+
+	logs, err := s.habitLogRepo.GetByDate(ctx, userID, date)
+	if err != nil {
+		return nil, nil, s.wrapError(err)
+	}
+
+	completedAt := make(map[uuid.UUID]time.Time)
+	for _, log := range logs {
+		if log.Completed {
+			completedAt[log.HabitID] = log.CreatedAt
+		}
+	}
+
+	return habitsByID, completedAt, nil
+}
+
+// buildRoutineResponse assembles a routine with its ordered, active member
+// habits and their progress on date
+func buildRoutineResponse(
+	rt routine.Routine,
+	members []routine.Member,
+	habitsByID map[uuid.UUID]habit.Habit,
+	completedAt map[uuid.UUID]time.Time,
+	date time.Time,
+) routine.RoutineResponse {
+	habits := make([]routine.RoutineHabit, 0, len(members))
+	completedCount := 0
+
+	for _, m := range members {
+		h, ok := habitsByID[m.HabitID]
+		if !ok || h.ArchivedAt != nil {
+			continue
+		}
+
+		routineHabit := routine.RoutineHabit{
+			ID:       h.ID.String(),
+			Name:     h.Name,
+			Icon:     h.Icon,
+			Color:    h.Color,
+			Position: m.Position,
+		}
+
+		if at, ok := completedAt[h.ID]; ok {
+			routineHabit.CompletedToday = true
+			routineHabit.CompletedAt = &at
+			completedCount++
+		}
+
+		habits = append(habits, routineHabit)
+	}
+
+	return routine.RoutineResponse{
+		Routine:  rt,
+		Habits:   habits,
+		Progress: routineProgress(completedCount, len(habits), date),
+	}
+}
+
+func routineProgress(completedCount int, totalCount int, date time.Time) routine.Progress {
+	completionRate := 0.0
+	if totalCount > 0 {
+		completionRate = (float64(completedCount) / float64(totalCount)) * 100
+	}
+
+	return routine.Progress{
+		Date:           date.Format("2006-01-02"),
+		CompletedCount: completedCount,
+		TotalCount:     totalCount,
+		CompletionRate: completionRate,
+		Completed:      totalCount > 0 && completedCount == totalCount,
+	}
+}
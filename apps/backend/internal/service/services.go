@@ -24,11 +24,21 @@ type Services struct{
 	Analytics *AnalyticsService
 	Calendar *CalendarService
 	Dashboard *DashboardService
+	HabitTemplate *HabitTemplateService
+	Routine *RoutineService
+	Pause *PauseService
+	StreakFreeze *StreakFreezeService
+	Goal *GoalService
+	Achievement *AchievementService
+	Points *PointsService
+	Reward *RewardService
+	Report *ReportService
 	Auth *AuthService
 }
 
 func NewServices(repos *repository.Repositories, cfg *config.Config, logger zerolog.Logger) *Services {
-	habitLogService := NewHabitLogService(repos.HabitLog)
+	xpRules := NewXPRules(cfg.XP)
+	habitLogService := NewHabitLogService(repos, xpRules)
 	
 	// Parse JWT expiry durations
 	accessExpiry := 15 * time.Minute
@@ -71,13 +81,24 @@ func NewServices(repos *repository.Repositories, cfg *config.Config, logger zero
 		cfg.Auth.TestAccountPassword,
 	)
 	
+	habitService := NewHabitService(repos.Habit, habitLogService, repos.Pause, repos.User)
+
 	return &Services{
-		User: NewUserService(repos.User),
-		Habit: NewHabitService(repos.Habit, habitLogService),
+		User: NewUserService(repos),
+		Habit: habitService,
 		HabitLog: habitLogService,
-		Analytics: NewAnalyticsService(repos.Habit, repos.HabitLog),
-		Calendar: NewCalendarService(repos.Habit, repos.HabitLog),
-		Dashboard: NewDashboardService(repos.Habit, repos.HabitLog),
+		Analytics: NewAnalyticsService(repos.Habit, repos.HabitLog, repos.Pause, repos.User, repos.DailyRollup, repos.HabitStrength, repos.InsightDismissal),
+		Calendar: NewCalendarService(repos.Habit, repos.HabitLog, repos.Pause, repos.User, repos.DailyRollup),
+		Dashboard: NewDashboardService(repos.Habit, repos.HabitLog, repos.Routine, repos.Pause, repos.User, repos.HabitStrength, repos.Achievement),
+		HabitTemplate: NewHabitTemplateService(repos.HabitTemplate, repos.Habit, habitService),
+		Routine: NewRoutineService(repos, xpRules),
+		Pause: NewPauseService(repos),
+		StreakFreeze: NewStreakFreezeService(repos, xpRules),
+		Goal: NewGoalService(repos),
+		Achievement: NewAchievementService(repos.Achievement, repos.User),
+		Points: NewPointsService(repos, xpRules),
+		Reward: NewRewardService(repos),
+		Report: NewReportService(repos),
 		Auth: authService,
 	}
 }
\ No newline at end of file
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// Streaks are kept on the habit row and maintained when logs are written, so
+// reads never rescan a habit's history. The stored current streak is the
+// streak ending on last_completed_on; whether it is still alive today is
+// decided at read time by liveCurrentStreak.
+
+// updateStreaks brings the habit's stored streaks in line with a log that was
+// just written. For daily habits a completion after the last completed day
+// extends or restarts the streak incrementally; anything else (a backdated
+// completion, a skip or a freeze that may bridge a gap) recomputes the habit's
+// streaks from its logs. Weekly habits always recompute, since whether a week
+// counts depends on every completion in it.
+func updateStreaks(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+	log *habitlog.HabitLog,
+) error {
+	if h.Frequency == habit.Weekly || !log.Completed || h.LastCompletedOn == nil || !log.LogDate.After(*h.LastCompletedOn) {
+		return recomputeStreaks(ctx, tx, userID, h)
+	}
+
+	pauses, err := loadPauseSchedule(ctx, tx.Pause, userID)
+	if err != nil {
+		return err
+	}
+
+	// Only the days between the last completion and this one matter
+	last := lib.NormalizeDate(*h.LastCompletedOn)
+	gapLogs, err := tx.HabitLog.GetByHabit(ctx, userID, h.ID, last.AddDate(0, 0, 1), log.LogDate)
+	if err != nil {
+		return err
+	}
+	excluded := withExcusedDays(habitDayFilter(*h, pauses), gapLogs)
+
+	current := 1
+	if isGapExcused(last.AddDate(0, 0, 1), log.LogDate, excluded) {
+		current = h.CurrentStreak + 1
+	}
+
+	longest := h.LongestStreak
+	if current > longest {
+		longest = current
+	}
+
+	lastCompletedOn := log.LogDate
+	if err := tx.Habit.UpdateStreaks(ctx, h.ID, userID, current, longest, &lastCompletedOn); err != nil {
+		return err
+	}
+
+	h.CurrentStreak, h.LongestStreak, h.LastCompletedOn = current, longest, &lastCompletedOn
+	return nil
+}
+
+// recomputeStreaks derives the habit's streaks from its full log history and
+// stores them
+func recomputeStreaks(
+	ctx context.Context,
+	repos *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+) error {
+	clock := lib.ClockFromContext(ctx)
+	logs, err := repos.HabitLog.GetByHabit(ctx, userID, h.ID, time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC), clock.Today())
+	if err != nil {
+		return err
+	}
+
+	pauses, err := loadPauseSchedule(ctx, repos.Pause, userID)
+	if err != nil {
+		return err
+	}
+	excluded := withExcusedDays(habitDayFilter(*h, pauses), logs)
+
+	completedDates := make([]time.Time, 0)
+	for _, log := range logs {
+		if log.Completed {
+			completedDates = append(completedDates, log.LogDate)
+		}
+	}
+
+	current, longest := 0, 0
+	var lastCompletedOn *time.Time
+	if len(completedDates) > 0 {
+		if h.Frequency == habit.Daily {
+			last := latestDate(completedDates)
+			lastCompletedOn = &last
+			current = calculateDailyStreak(completedDates, excluded, last)
+			longest = findLongestDailyStreak(completedDates, excluded)
+		} else {
+			// The stored streak ends on the latest week that met the target
+			target := h.WeeklyTarget()
+			counts := weeklyCounts(completedDates, clock)
+			for _, date := range completedDates {
+				if !isWeekMet(counts, clock.WeekStart(date), target, excluded) {
+					continue
+				}
+				if lastCompletedOn == nil || date.After(*lastCompletedOn) {
+					last := lib.NormalizeDate(date)
+					lastCompletedOn = &last
+				}
+			}
+			if lastCompletedOn != nil {
+				current = calculateWeeklyStreak(completedDates, excluded, clock, target, *lastCompletedOn)
+			}
+			longest = findLongestWeeklyStreak(completedDates, excluded, clock, target)
+		}
+	}
+
+	if err := repos.Habit.UpdateStreaks(ctx, h.ID, userID, current, longest, lastCompletedOn); err != nil {
+		return err
+	}
+
+	h.CurrentStreak, h.LongestStreak, h.LastCompletedOn = current, longest, lastCompletedOn
+	return nil
+}
+
+// recomputeUserStreaks recomputes the stored streaks of every habit of the
+// user, after a change that moves the days they are counted on
+func recomputeUserStreaks(ctx context.Context, repos *repository.Repositories, userID uuid.UUID) error {
+	habits, _, err := repos.Habit.List(ctx, userID, nil)
+	if err != nil {
+		return err
+	}
+
+	for i := range habits {
+		if err := recomputeStreaks(ctx, repos, userID, &habits[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refreshCurrentStreaks replaces the stored current streak of each habit with
+// the streak that is still alive today. Habits whose gap since the last
+// completion is not covered by pauses alone need their logs checked for
+// skipped and frozen days; those logs are loaded in one query.
+func refreshCurrentStreaks(
+	ctx context.Context,
+	habitLogRepo *repository.HabitLogRepository,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	pauses *PauseSchedule,
+) error {
+	clock := lib.ClockFromContext(ctx)
+
+	var since *time.Time
+	pending := make([]int, 0)
+	for i, h := range habits {
+		if h.LastCompletedOn == nil {
+			habits[i].CurrentStreak = 0
+			continue
+		}
+		if h.CurrentStreak == 0 || liveCurrentStreak(h, habitDayFilter(h, pauses), clock) > 0 {
+			continue
+		}
+		pending = append(pending, i)
+		if since == nil || h.LastCompletedOn.Before(*since) {
+			since = h.LastCompletedOn
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	logs, err := habitLogRepo.GetByDateRange(ctx, userID, lib.NormalizeDate(*since), clock.Today())
+	if err != nil {
+		return err
+	}
+
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	for _, i := range pending {
+		h := habits[i]
+		excluded := withExcusedDays(habitDayFilter(h, pauses), logsByHabit[h.ID])
+		habits[i].CurrentStreak = liveCurrentStreak(h, excluded, clock)
+	}
+
+	return nil
+}
+
+// liveCurrentStreak returns the habit's stored current streak if nothing has
+// broken it since the last completion, and 0 otherwise. Today (or this week)
+// is still in progress, so only the days (or weeks) before it can break it.
+func liveCurrentStreak(h habit.Habit, excluded DayFilter, clock lib.Clock) int {
+	if h.LastCompletedOn == nil {
+		return 0
+	}
+
+	last := lib.NormalizeDate(*h.LastCompletedOn)
+	today := clock.Today()
+
+	if h.Frequency == habit.Daily {
+		if !isGapExcused(last.AddDate(0, 0, 1), today, excluded) {
+			return 0
+		}
+		return h.CurrentStreak
+	}
+
+	if !isWeekGapExcused(clock.WeekStart(last).AddDate(0, 0, 7), clock.WeekStart(today), excluded) {
+		return 0
+	}
+	return h.CurrentStreak
+}
+
+// isGapExcused reports whether every day from from up to, but not including,
+// to is excluded
+func isGapExcused(from, to time.Time, excluded DayFilter) bool {
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !excluded(day) {
+			return false
+		}
+	}
+	return true
+}
+
+// isWeekGapExcused reports whether every week starting from from up to, but
+// not including, the week starting at to is fully excluded
+func isWeekGapExcused(from, to time.Time, excluded DayFilter) bool {
+	for week := from; week.Before(to); week = week.AddDate(0, 0, 7) {
+		if !isWeekExcluded(week, excluded) {
+			return false
+		}
+	}
+	return true
+}
+
+// RecomputeStreaks rebuilds the stored streaks of every habit of the user
+// from their logs, for repairing rows that drifted from the log history. It
+// returns the number of habits updated.
+func (s *HabitService) RecomputeStreaks(ctx context.Context, userID uuid.UUID) (int, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return 0, s.wrapError(err)
+	}
+
+	for i := range habits {
+		err := s.habitLogService.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+			return recomputeStreaks(ctx, tx, userID, &habits[i])
+		})
+		if err != nil {
+			return i, s.wrapError(err)
+		}
+	}
+
+	return len(habits), nil
+}
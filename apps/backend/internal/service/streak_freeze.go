@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/model/streakfreeze"
+	"github.com/reche13/habitum/internal/repository"
+	"github.com/reche13/habitum/internal/sqlerr"
+)
+
+const (
+	// streakFreezeMilestone is the streak length (and every multiple of it)
+	// at which a daily habit earns a freeze
+	streakFreezeMilestone = 7
+	// maxStreakFreezes caps the inventory; milestones reached while it is
+	// full do not earn anything
+	maxStreakFreezes = 3
+)
+
+type StreakFreezeService struct {
+	*BaseService
+	repos *repository.Repositories
+	xp    XPRules
+}
+
+func NewStreakFreezeService(repos *repository.Repositories, xp XPRules) *StreakFreezeService {
+	return &StreakFreezeService{
+		BaseService: &BaseService{
+			resourceName: "streak freeze",
+		},
+		repos: repos,
+		xp:    xp,
+	}
+}
+
+// GetInventory returns the freezes left together with the earning and usage history
+func (s *StreakFreezeService) GetInventory(ctx context.Context, userID uuid.UUID) (*streakfreeze.InventoryResponse, error) {
+	earned, err := s.repos.StreakFreeze.ListEarned(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	usage, err := s.repos.StreakFreeze.ListUsage(ctx, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	available := 0
+	for _, f := range earned {
+		if f.UsedAt == nil {
+			available++
+		}
+	}
+
+	return &streakfreeze.InventoryResponse{
+		Available:     available,
+		MaxAvailable:  maxStreakFreezes,
+		MilestoneDays: streakFreezeMilestone,
+		EarnedTotal:   len(earned),
+		UsedTotal:     len(usage),
+		Earned:        earned,
+		Usage:         usage,
+	}, nil
+}
+
+// UseFreeze spends a freeze to cover a missed day of a daily habit. A zero
+// date means yesterday.
+func (s *StreakFreezeService) UseFreeze(
+	ctx context.Context,
+	userID uuid.UUID,
+	habitID uuid.UUID,
+	date time.Time,
+) (*habitlog.HabitLog, error) {
+	ctx, err := withUserClock(ctx, s.repos.User, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	clock := lib.ClockFromContext(ctx)
+	today := clock.Today()
+	day := today.AddDate(0, 0, -1)
+	if !date.IsZero() {
+		day = lib.NormalizeDate(date)
+	}
+
+	if !day.Before(today) {
+		return nil, errs.NewBadRequestError("Only past days can be covered by a streak freeze")
+	}
+
+	var log *habitlog.HabitLog
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		if err := tx.StreakFreeze.LockUser(ctx, userID); err != nil {
+			return s.wrapError(err)
+		}
+
+		h, err := tx.Habit.GetByID(ctx, habitID, userID)
+		if err != nil {
+			return sqlerr.WrapError(err, "habit")
+		}
+
+		if h.Frequency != habit.Daily {
+			return errs.NewBadRequestError("Streak freezes can only be used on daily habits")
+		}
+		if day.Before(clock.Date(h.CreatedAt)) {
+			return errs.NewBadRequestError("The habit did not exist on that day")
+		}
+
+		logs, err := tx.HabitLog.GetByHabit(ctx, userID, habitID, day, day)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		for _, l := range logs {
+			if l.Completed || l.Status.IsExcused() {
+				return errs.NewBadRequestError("That day does not need a streak freeze")
+			}
+		}
+
+		spent, err := tx.StreakFreeze.Spend(ctx, userID, habitID, day)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		if !spent {
+			return errs.NewBadRequestError("No streak freezes left")
+		}
+
+		log, err = tx.HabitLog.Create(ctx, userID, &habitlog.HabitLogPayload{
+			HabitID: habitID,
+			LogDate: day,
+			Status:  habitlog.Frozen,
+		})
+		if err != nil {
+			return sqlerr.WrapError(err, "habitlog")
+		}
+		return afterLogWrite(ctx, tx, s.xp, userID, h, log)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return log, nil
+}
+
+// applyStreakFreezes runs after a daily habit was completed on date. It spends
+// a freeze on the previous day when that single day was missed in the middle
+// of a streak, then awards a freeze if the streak reached a milestone.
+// It must run on transaction-bound repositories.
+func applyStreakFreezes(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+	date time.Time,
+) error {
+	if h.Frequency != habit.Daily {
+		return nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, tx.Pause, userID)
+	if err != nil {
+		return err
+	}
+	excluded := habitDayFilter(*h, pauses)
+
+	if err := autoUseStreakFreeze(ctx, tx, userID, h, date, excluded); err != nil {
+		return err
+	}
+
+	// Milestones are only awarded for completions that extend the live streak
+	if !date.Equal(lib.ClockFromContext(ctx).Today()) {
+		return nil
+	}
+
+	streak, err := CalculateCurrentStreak(ctx, tx.HabitLog, userID, h.ID, h.Frequency, h.WeeklyTarget(), excluded)
+	if err != nil {
+		return err
+	}
+	if streak == 0 || streak%streakFreezeMilestone != 0 {
+		return nil
+	}
+
+	if err := tx.StreakFreeze.LockUser(ctx, userID); err != nil {
+		return err
+	}
+	available, err := tx.StreakFreeze.CountAvailable(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if available >= maxStreakFreezes {
+		return nil
+	}
+
+	_, err = tx.StreakFreeze.Earn(ctx, userID, h.ID, date, streak)
+	return err
+}
+
+// autoUseStreakFreeze covers the day before date when it has no log, is not
+// excluded, and the day before it was completed
+func autoUseStreakFreeze(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+	date time.Time,
+	excluded DayFilter,
+) error {
+	missed := date.AddDate(0, 0, -1)
+	previous := date.AddDate(0, 0, -2)
+
+	if previous.Before(lib.ClockFromContext(ctx).Date(h.CreatedAt)) || excluded(missed) {
+		return nil
+	}
+
+	logs, err := tx.HabitLog.GetByHabit(ctx, userID, h.ID, previous, missed)
+	if err != nil {
+		return err
+	}
+
+	previousCompleted := false
+	for _, l := range logs {
+		if l.LogDate.Equal(missed) {
+			// Something was already logged for that day
+			return nil
+		}
+		if l.LogDate.Equal(previous) && l.Completed {
+			previousCompleted = true
+		}
+	}
+	if !previousCompleted {
+		return nil
+	}
+
+	if err := tx.StreakFreeze.LockUser(ctx, userID); err != nil {
+		return err
+	}
+	spent, err := tx.StreakFreeze.Spend(ctx, userID, h.ID, missed)
+	if err != nil {
+		return err
+	}
+	if !spent {
+		return nil
+	}
+
+	_, err = tx.HabitLog.Create(ctx, userID, &habitlog.HabitLogPayload{
+		HabitID: h.ID,
+		LogDate: missed,
+		Status:  habitlog.Frozen,
+	})
+	return err
+}
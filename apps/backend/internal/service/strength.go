@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+	"github.com/reche13/habitum/internal/repository"
+)
+
+// A habit's strength is an exponential moving average of its scheduled
+// outcomes: each completed day (or week meeting the target) pulls the score
+// towards 100 and each missed one towards 0, so one miss after a long run
+// only dents it. Excluded days leave it unchanged.
+const (
+	// An outcome weighs half as much after this many days or weeks
+	strengthHalfLifeDays  = 14
+	strengthHalfLifeWeeks = 4
+)
+
+// strengthAlpha returns the weight of the newest outcome in the habit's score
+func strengthAlpha(h habit.Habit) float64 {
+	halfLife := strengthHalfLifeDays
+	if h.Frequency == habit.Weekly {
+		halfLife = strengthHalfLifeWeeks
+	}
+	return 1 - math.Pow(0.5, 1/float64(halfLife))
+}
+
+// nextStrength moves score towards 100 on success and towards 0 otherwise
+func nextStrength(score, alpha float64, success bool) float64 {
+	outcome := 0.0
+	if success {
+		outcome = 100
+	}
+	return score + alpha*(outcome-score)
+}
+
+// strengthSeries returns the habit's score at the end of each day from from to
+// to, continuing from score, the score at the end of the day before from. The
+// logs must cover from (for weekly habits, the start of its week) to to, and
+// excluded should include excused days. Today counts once it is completed; a
+// week counts on the day it meets its target, or as missed on its last day.
+func strengthSeries(
+	h habit.Habit,
+	logs []habitlog.HabitLog,
+	excluded DayFilter,
+	clock lib.Clock,
+	score float64,
+	from, to time.Time,
+) []float64 {
+	alpha := strengthAlpha(h)
+	today := clock.Today()
+
+	completed := make(map[string]bool)
+	for _, log := range logs {
+		if log.Completed {
+			completed[dateKey(log.LogDate)] = true
+		}
+	}
+
+	// Days the habit did not exist yet lower a week's target like excluded ones
+	unscheduled := func(day time.Time) bool {
+		return !isHabitActiveOn(clock, h, day) || excluded(day)
+	}
+
+	scores := make([]float64, 0)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if h.Frequency == habit.Daily {
+			switch {
+			case !isHabitActiveOn(clock, h, day):
+			case completed[dateKey(day)]:
+				score = nextStrength(score, alpha, true)
+			case excluded(day) || !day.Before(today):
+			default:
+				score = nextStrength(score, alpha, false)
+			}
+			scores = append(scores, score)
+			continue
+		}
+
+		week := clock.WeekStart(day)
+		target := weekTarget(week, h.WeeklyTarget(), unscheduled)
+		need := max(target, 1)
+		done := 0
+		for d := week; !d.After(day); d = d.AddDate(0, 0, 1) {
+			if completed[dateKey(d)] {
+				done++
+			}
+		}
+
+		switch {
+		case completed[dateKey(day)] && done == need:
+			score = nextStrength(score, alpha, true)
+		case clock.WeekdayIndex(day) == 6 && day.Before(today) && target > 0 && done < need && isHabitActiveOn(clock, h, day):
+			score = nextStrength(score, alpha, false)
+		}
+		scores = append(scores, score)
+	}
+
+	return scores
+}
+
+// loadHabitStrengths returns each habit's score for every day from from to
+// to, keyed by habit. Days after each habit's latest stored score are
+// computed but not stored, so reads never write; log writes store them with
+// storeHabitStrengths. Days before a habit existed score 0.
+func loadHabitStrengths(
+	ctx context.Context,
+	strengthRepo *repository.HabitStrengthRepository,
+	habitLogRepo *repository.HabitLogRepository,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	pauses *PauseSchedule,
+	from, to time.Time,
+) (map[uuid.UUID][]float64, error) {
+	strengths := make(map[uuid.UUID][]float64, len(habits))
+	if len(habits) == 0 {
+		return strengths, nil
+	}
+
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+
+	stored, err := strengthRepo.ListRange(ctx, userID, habitIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	missing, err := missingHabitStrengths(ctx, strengthRepo, habitLogRepo, userID, habits, pauses, to)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[uuid.UUID]map[string]float64, len(habits))
+	for _, st := range append(stored, missing...) {
+		if scores[st.HabitID] == nil {
+			scores[st.HabitID] = make(map[string]float64)
+		}
+		scores[st.HabitID][dateKey(st.Day)] = st.Score
+	}
+
+	for _, h := range habits {
+		series := make([]float64, 0)
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			series = append(series, scores[h.ID][dateKey(day)])
+		}
+		strengths[h.ID] = series
+	}
+
+	return strengths, nil
+}
+
+// missingHabitStrengths computes each habit's scores for the days after its
+// latest stored one, up to to. Stored scores run without gaps from each
+// habit's creation, so these are the only days missing.
+func missingHabitStrengths(
+	ctx context.Context,
+	strengthRepo *repository.HabitStrengthRepository,
+	habitLogRepo *repository.HabitLogRepository,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	pauses *PauseSchedule,
+	to time.Time,
+) ([]habit.Strength, error) {
+	clock := lib.ClockFromContext(ctx)
+
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+
+	latest, err := strengthRepo.ListLatest(ctx, userID, habitIDs)
+	if err != nil {
+		return nil, err
+	}
+	latestByHabit := make(map[uuid.UUID]habit.Strength, len(latest))
+	for _, st := range latest {
+		latestByHabit[st.HabitID] = st
+	}
+
+	// Each habit continues from the day after its latest stored score
+	starts := make(map[uuid.UUID]time.Time)
+	var logsFrom time.Time
+	for _, h := range habits {
+		start := clock.Date(h.CreatedAt)
+		if st, ok := latestByHabit[h.ID]; ok {
+			start = lib.NormalizeDate(st.Day).AddDate(0, 0, 1)
+		}
+		if start.After(to) {
+			continue
+		}
+		starts[h.ID] = start
+		if weekStart := clock.WeekStart(start); logsFrom.IsZero() || weekStart.Before(logsFrom) {
+			logsFrom = weekStart
+		}
+	}
+
+	missing := make([]habit.Strength, 0)
+	if len(starts) == 0 {
+		return missing, nil
+	}
+
+	logs, err := habitLogRepo.GetByHabits(ctx, userID, habitIDs, logsFrom, to)
+	if err != nil {
+		return nil, err
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	for _, h := range habits {
+		start, ok := starts[h.ID]
+		if !ok {
+			continue
+		}
+
+		excluded := withExcusedDays(habitDayFilter(h, pauses), logsByHabit[h.ID])
+		series := strengthSeries(h, logsByHabit[h.ID], excluded, clock, latestByHabit[h.ID].Score, start, to)
+		for i, score := range series {
+			missing = append(missing, habit.Strength{HabitID: h.ID, Day: start.AddDate(0, 0, i), Score: score})
+		}
+	}
+
+	return missing, nil
+}
+
+// storeHabitStrengths stores the habit's missing scores up to yesterday,
+// leaving today's, which changes until the day ends. Log writes call it on
+// their transaction after invalidateStrength, so reads find them stored.
+func storeHabitStrengths(
+	ctx context.Context,
+	tx *repository.Repositories,
+	userID uuid.UUID,
+	h *habit.Habit,
+) error {
+	pauses, err := loadPauseSchedule(ctx, tx.Pause, userID)
+	if err != nil {
+		return err
+	}
+
+	yesterday := lib.ClockFromContext(ctx).Today().AddDate(0, 0, -1)
+	missing, err := missingHabitStrengths(ctx, tx.HabitStrength, tx.HabitLog, userID, []habit.Habit{*h}, pauses, yesterday)
+	if err != nil {
+		return err
+	}
+
+	return tx.HabitStrength.Upsert(ctx, userID, missing)
+}
+
+// invalidateStrength drops the habit's stored scores that a log on date may
+// change. Whether a week met its target depends on the whole week.
+func invalidateStrength(
+	ctx context.Context,
+	strengthRepo *repository.HabitStrengthRepository,
+	h *habit.Habit,
+	date time.Time,
+) error {
+	if h.Frequency == habit.Weekly {
+		date = lib.ClockFromContext(ctx).WeekStart(date)
+	}
+	return strengthRepo.DeleteFrom(ctx, h.ID, date)
+}
+
+// GetStrengthTrend returns the daily strength score over the range, for one
+// habit when habitID is set and otherwise averaged over the habits active on
+// each day
+func (s *AnalyticsService) GetStrengthTrend(
+	ctx context.Context,
+	userID uuid.UUID,
+	q analytics.RangeQuery,
+	habitID *uuid.UUID,
+) (*analytics.StrengthTrendResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	archiveEndedChallenges(habits, lib.ClockFromContext(ctx))
+
+	if habitID != nil {
+		selected := make([]habit.Habit, 0, 1)
+		for _, h := range habits {
+			if h.ID == *habitID {
+				selected = append(selected, h)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errs.NewNotFoundError("habit not found")
+		}
+		habits = selected
+	}
+
+	startDate, endDate, err := resolveRange(q, habits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.StrengthTrendResponse{
+		Range: newDateRange(startDate, endDate),
+		Data:  []analytics.StrengthTrendDataPoint{},
+	}
+	if habitID != nil {
+		response.HabitID = habitID.String()
+	}
+	if len(habits) == 0 {
+		return response, nil
+	}
+
+	pauses, err := loadPauseSchedule(ctx, s.pauseRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	response.Data, err = s.strengthTrend(ctx, userID, habits, pauses, startDate, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	response.Average = averageStrength(response.Data)
+
+	if q.Compare != "" {
+		prevStart, prevEnd := comparisonRange(q.Compare, startDate, endDate)
+		previous, err := s.strengthTrend(ctx, userID, habits, pauses, prevStart, prevEnd)
+		if err != nil {
+			return nil, s.wrapError(err)
+		}
+
+		comparedTo := newDateRange(prevStart, prevEnd)
+		response.ComparedTo = &comparedTo
+		response.Deltas = map[string]analytics.Delta{
+			"average": newDelta(response.Average, averageStrength(previous)),
+		}
+	}
+
+	return response, nil
+}
+
+// strengthTrend averages the habits' scores on each day from from to to over
+// the habits active that day
+func (s *AnalyticsService) strengthTrend(
+	ctx context.Context,
+	userID uuid.UUID,
+	habits []habit.Habit,
+	pauses *PauseSchedule,
+	from, to time.Time,
+) ([]analytics.StrengthTrendDataPoint, error) {
+	clock := lib.ClockFromContext(ctx)
+
+	strengths, err := loadHabitStrengths(ctx, s.strengthRepo, s.habitLogRepo, userID, habits, pauses, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]analytics.StrengthTrendDataPoint, 0)
+	for i, day := 0, from; !day.After(to); i, day = i+1, day.AddDate(0, 0, 1) {
+		total, active := 0.0, 0
+		for _, h := range habits {
+			if isHabitActiveOn(clock, h, day) {
+				total += strengths[h.ID][i]
+				active++
+			}
+		}
+
+		strength := 0.0
+		if active > 0 {
+			strength = total / float64(active)
+		}
+
+		data = append(data, analytics.StrengthTrendDataPoint{
+			Date:        dateKey(day),
+			Strength:    strength,
+			TotalHabits: active,
+		})
+	}
+
+	return data, nil
+}
+
+// averageStrength averages the scores of the days any habit was active
+func averageStrength(data []analytics.StrengthTrendDataPoint) float64 {
+	total, days := 0.0, 0
+	for _, point := range data {
+		if point.TotalHabits > 0 {
+			total += point.Strength
+			days++
+		}
+	}
+	if days == 0 {
+		return 0
+	}
+	return total / float64(days)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/google/uuid"
+	"github.com/reche13/habitum/internal/errs"
+	"github.com/reche13/habitum/internal/lib"
+	"github.com/reche13/habitum/internal/model/analytics"
+	"github.com/reche13/habitum/internal/model/habit"
+	"github.com/reche13/habitum/internal/model/habitlog"
+)
+
+const (
+	// A habit needs this many timed completions before it has a typical time
+	minTimedCompletions = 5
+	// How tightly completion times must cluster, from 0 (spread around the
+	// clock) to 1 (always the same minute), to have a typical time
+	minTimeConcentration = 0.6
+)
+
+// hourDistribution counts completions by the hour they were logged at
+func hourDistribution(times []int) []analytics.HourDataPoint {
+	hours := make([]analytics.HourDataPoint, 24)
+	for hour := range hours {
+		hours[hour].Hour = hour
+	}
+	for _, minute := range times {
+		hours[minute/60].Completions++
+	}
+	return hours
+}
+
+// typicalTime returns the mean of times, given in minutes after midnight, on
+// a 24 hour clock so that 23:30 and 00:30 average to midnight. It reports
+// false when there are too few times or they are too spread out.
+func typicalTime(times []int) (int, bool) {
+	if len(times) < minTimedCompletions {
+		return 0, false
+	}
+
+	var x, y float64
+	for _, minute := range times {
+		angle := float64(minute) / (24 * 60) * 2 * math.Pi
+		x += math.Cos(angle)
+		y += math.Sin(angle)
+	}
+	x /= float64(len(times))
+	y /= float64(len(times))
+	if math.Hypot(x, y) < minTimeConcentration {
+		return 0, false
+	}
+
+	angle := math.Atan2(y, x)
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	minute := int(math.Round(angle/(2*math.Pi)*24*60)) % (24 * 60)
+	return minute, true
+}
+
+// formatClockTime formats minutes after midnight as "HH:mm", rounded to the
+// nearest quarter hour
+func formatClockTime(minute int) string {
+	minute = (minute + 7) / 15 * 15 % (24 * 60)
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// completionMinutes returns the local minute after midnight each of the
+// logs' completions was logged at, leaving out those without a known time
+func completionMinutes(logs []habitlog.HabitLog) []int {
+	minutes := make([]int, 0)
+	for _, log := range logs {
+		if !log.Completed {
+			continue
+		}
+		if at, ok := log.LocalCompletedAt(); ok {
+			minutes = append(minutes, at.Hour()*60+at.Minute())
+		}
+	}
+	return minutes
+}
+
+// GetTimeOfDayAnalysis returns the hour-of-day distribution of completions
+// over the range, overall and for each habit, or only for habitID when set
+func (s *AnalyticsService) GetTimeOfDayAnalysis(
+	ctx context.Context,
+	userID uuid.UUID,
+	q analytics.RangeQuery,
+	habitID *uuid.UUID,
+) (*analytics.TimeOfDayResponse, error) {
+	ctx, err := withUserClock(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	clock := lib.ClockFromContext(ctx)
+
+	habits, _, err := s.habitRepo.List(ctx, userID, nil)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+
+	if habitID != nil {
+		selected := make([]habit.Habit, 0, 1)
+		for _, h := range habits {
+			if h.ID == *habitID {
+				selected = append(selected, h)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, errs.NewNotFoundError("habit not found")
+		}
+		habits = selected
+	}
+
+	startDate, endDate, err := resolveRange(q, habits, clock)
+	if err != nil {
+		return nil, err
+	}
+	response := &analytics.TimeOfDayResponse{
+		Range:   newDateRange(startDate, endDate),
+		Overall: hourDistribution(nil),
+		Habits:  []analytics.HabitTimeOfDay{},
+	}
+	if len(habits) == 0 {
+		return response, nil
+	}
+
+	habitIDs := make([]uuid.UUID, len(habits))
+	for i, h := range habits {
+		habitIDs[i] = h.ID
+	}
+
+	logs, err := s.habitLogRepo.GetByHabits(ctx, userID, habitIDs, startDate, endDate)
+	if err != nil {
+		return nil, s.wrapError(err)
+	}
+	logsByHabit := make(map[uuid.UUID][]habitlog.HabitLog)
+	for _, log := range logs {
+		logsByHabit[log.HabitID] = append(logsByHabit[log.HabitID], log)
+	}
+
+	response.Overall = hourDistribution(completionMinutes(logs))
+	for _, h := range habits {
+		minutes := completionMinutes(logsByHabit[h.ID])
+		if len(minutes) == 0 && habitID == nil {
+			continue
+		}
+
+		point := analytics.HabitTimeOfDay{
+			HabitID:     h.ID.String(),
+			Name:        h.Name,
+			Completions: len(minutes),
+			Hours:       hourDistribution(minutes),
+		}
+		if minute, ok := typicalTime(minutes); ok {
+			formatted := formatClockTime(minute)
+			point.TypicalTime = &formatted
+		}
+		response.Habits = append(response.Habits, point)
+	}
+
+	return response, nil
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/reche13/habitum/internal/model/user"
@@ -11,16 +12,16 @@ import (
 type UserService struct {
 	*BaseService
 	userRepo *repository.UserRepository
+	repos *repository.Repositories
 }
 
-func NewUserService(
-	userRepo *repository.UserRepository,
-) *UserService {
+func NewUserService(repos *repository.Repositories) *UserService {
 	return &UserService{
 		BaseService: &BaseService{
 			resourceName: "user",
 		},
-		userRepo: userRepo,
+		userRepo: repos.User,
+		repos: repos,
 	}
 }
 
@@ -65,9 +66,40 @@ func (s *UserService) UpdateUser(
 		return nil, s.wrapError(err)
 	}
 
-	updatedUser, err := s.userRepo.Update(ctx, id, payload)
+	var updatedUser *user.User
+	err = s.repos.WithTx(ctx, func(tx *repository.Repositories) error {
+		updatedUser, err = tx.User.Update(ctx, id, payload)
+		if err != nil {
+			return s.wrapError(err)
+		}
+
+		// Habits are created on a different day in another time zone or with
+		// another day cutoff, and weekly scores and the units of weekly habits
+		// follow the week start
+		if payload.Timezone != nil || payload.DayEndsAt != nil || payload.WeekStart != nil {
+			if err := tx.DailyRollup.DeleteFrom(ctx, id, time.Time{}); err != nil {
+				return s.wrapError(err)
+			}
+		}
+		if payload.Timezone == nil && payload.DayEndsAt == nil && payload.WeekStart == nil {
+			return nil
+		}
+		if err := tx.HabitStrength.DeleteFromForUser(ctx, id, time.Time{}); err != nil {
+			return s.wrapError(err)
+		}
+
+		// Stored streaks were counted on the old days and weeks
+		userCtx, err := withUserClock(ctx, tx.User, id)
+		if err != nil {
+			return s.wrapError(err)
+		}
+		if err := recomputeUserStreaks(userCtx, tx, id); err != nil {
+			return s.wrapError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, s.wrapError(err)
+		return nil, err
 	}
 
 	return updatedUser, nil